@@ -184,6 +184,10 @@ type BlockChain struct {
 	notifications     []NotificationCallback
 
 	claimTrie *claimtrie.ClaimTrie
+
+	// claimEventWatchers counts the callers of WatchClaimEvents that
+	// want NTClaimEvents notifications.  It must be accessed atomically.
+	claimEventWatchers int32
 }
 
 // HaveBlock returns whether or not the chain instance has the block represented
@@ -585,10 +589,12 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 	}
 
 	// Handle LBRY Claim Scripts
+	var claimEvents *ClaimEvents
 	if b.claimTrie != nil {
 		if err := b.ParseClaimScripts(block, node, view, current); err != nil {
 			return ruleError(ErrBadClaimTrie, err.Error())
 		}
+		claimEvents = b.claimEvents(block, false)
 	}
 
 	// Write any block status changes to DB before updating best state.
@@ -675,6 +681,9 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 	// updating wallets.
 	b.chainLock.Unlock()
 	b.sendNotification(NTBlockConnected, block)
+	if claimEvents != nil {
+		b.sendNotification(NTClaimEvents, claimEvents)
+	}
 	b.chainLock.Lock()
 
 	return nil
@@ -773,7 +782,9 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 		return err
 	}
 
+	var claimEvents *ClaimEvents
 	if b.claimTrie != nil {
+		claimEvents = b.claimEvents(block, true)
 		if err = b.claimTrie.ResetHeight(node.parent.height); err != nil {
 			return err
 		}
@@ -800,6 +811,9 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *btcutil.Block, view
 	// updating wallets.
 	b.chainLock.Unlock()
 	b.sendNotification(NTBlockDisconnected, block)
+	if claimEvents != nil {
+		b.sendNotification(NTClaimEvents, claimEvents)
+	}
 	b.chainLock.Lock()
 
 	return nil
@@ -1840,20 +1854,48 @@ func New(config *Config) (*BlockChain, error) {
 
 func rebuildMissingClaimTrieData(b *BlockChain, done <-chan struct{}) error {
 	target := b.bestChain.Height()
-	if b.claimTrie.Height() == target {
+	if h := b.claimTrie.Height(); h > target {
+		// an imported ClaimTrie can only be reset within the blocks exported with it
+		if err := b.claimTrie.ResetHeight(target); err != nil {
+			return fmt.Errorf("the ClaimTrie at height %d is ahead of the chain at %d "+
+				"and can't be reset to it: %v", h, target, err)
+		}
+	}
+
+	// the ClaimTrie may have been imported rather than built from these blocks, or reset to them
+	if h := b.claimTrie.Height(); h > 0 {
+		n := b.bestChain.NodeByHeight(h)
+		if hash := b.claimTrie.MerkleHash(); n.claimTrie != *hash {
+			return fmt.Errorf("the ClaimTrie hash %s at height %d doesn't match the header's %s; "+
+				"remove the claim_dbs to rebuild it", hash, h, n.claimTrie)
+		}
+	}
+
+	// the channel index may have been enabled after the ClaimTrie was built
+	channelHeight := target
+	if b.claimTrie.ChannelIndexEnabled() {
+		var err error
+		channelHeight, err = b.claimTrie.ChannelIndexHeight()
+		if err != nil {
+			return err
+		}
+	}
+	if b.claimTrie.Height() == target && channelHeight >= target {
 		return nil
 	}
-	if b.claimTrie.Height() > target {
-		return b.claimTrie.ResetHeight(target)
+
+	// the spend journal has the inputs of each block, so only the blocks past what's built are read
+	from := b.claimTrie.Height()
+	if channelHeight < from {
+		from = channelHeight
+	}
+	if from < 0 {
+		from = 0
 	}
 
 	start := time.Now()
 	lastReport := time.Now()
-	// TODO: move this view inside the loop (or recreate it every 5 sec.)
-	// as accumulating all inputs has potential to use a huge amount of RAM
-	// but we need to get the spent inputs working for that to be possible
-	view := NewUtxoViewpoint()
-	for h := int32(0); h < target; h++ {
+	for h := from; h < target; h++ {
 		select {
 		case <-done:
 			return fmt.Errorf("rebuild unfinished at height %d", b.claimTrie.Height())
@@ -1863,21 +1905,22 @@ func rebuildMissingClaimTrieData(b *BlockChain, done <-chan struct{}) error {
 		n := b.bestChain.NodeByHeight(h + 1)
 
 		var block *btcutil.Block
+		var stxos []SpentTxOut
 		err := b.db.View(func(dbTx database.Tx) error {
 			var err error
 			block, err = dbFetchBlockByNode(dbTx, n)
+			if err != nil {
+				return err
+			}
+			stxos, err = dbFetchSpendJournalEntry(dbTx, block)
 			return err
 		})
 		if err != nil {
 			return err
 		}
 
-		err = view.fetchInputUtxos(b.db, block)
-		if err != nil {
-			return err
-		}
-
-		err = view.connectTransactions(block, nil)
+		view := NewUtxoViewpoint()
+		err = view.addSpentTxOuts(block, stxos)
 		if err != nil {
 			return err
 		}
@@ -1887,6 +1930,11 @@ func rebuildMissingClaimTrieData(b *BlockChain, done <-chan struct{}) error {
 			if err != nil {
 				return err
 			}
+		} else if h >= channelHeight {
+			err = b.indexChannels(block, view)
+			if err != nil {
+				return err
+			}
 		}
 		if time.Since(lastReport) > time.Second*5 {
 			lastReport = time.Now()
@@ -3,17 +3,28 @@ package blockchain
 import (
 	"bytes"
 	"fmt"
+	"strings"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 
+	"github.com/lbryio/lbcd/chaincfg"
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
 
 	"github.com/lbryio/lbcd/claimtrie"
 	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/channel"
+	"github.com/lbryio/lbcd/claimtrie/claimid"
+	"github.com/lbryio/lbcd/claimtrie/metadata"
 	"github.com/lbryio/lbcd/claimtrie/node"
 	"github.com/lbryio/lbcd/claimtrie/normalization"
+	"github.com/lbryio/lbcd/claimtrie/param"
+	"github.com/lbryio/lbcd/claimtrie/proof"
+	"github.com/lbryio/lbcd/claimtrie/ranking"
+	"github.com/lbryio/lbcd/claimtrie/stats"
 )
 
 func (b *BlockChain) SetClaimtrieHeader(block *btcutil.Block, view *UtxoViewpoint) error {
@@ -34,14 +45,27 @@ func (b *BlockChain) SetClaimtrieHeader(block *btcutil.Block, view *UtxoViewpoin
 func (b *BlockChain) ParseClaimScripts(block *btcutil.Block, bn *blockNode, view *UtxoViewpoint, shouldFlush bool) error {
 	ht := block.Height()
 
+	// the channel index is only given the blocks once it has caught up with the ClaimTrie, and not the templates
+	var channels *channelIndexer
+	if b.claimTrie.ChannelIndexEnabled() && bn != nil {
+		indexHeight, err := b.claimTrie.ChannelIndexHeight()
+		if err != nil {
+			return errors.Wrapf(err, "in channel index height")
+		}
+		if indexHeight == b.claimTrie.Height() {
+			channels = &channelIndexer{ct: b.claimTrie, height: ht, params: b.chainParams}
+		}
+	}
+
 	for _, tx := range block.Transactions() {
-		h := handler{ht, tx, view, map[string][]byte{}}
+		h := handler{ht, tx, view, map[string][]byte{}, channels}
 		if err := h.handleTxIns(b.claimTrie); err != nil {
 			return err
 		}
 		if err := h.handleTxOuts(b.claimTrie); err != nil {
 			return err
 		}
+		h.handleSpentChannels()
 	}
 
 	err := b.claimTrie.AppendBlock()
@@ -60,17 +84,35 @@ func (b *BlockChain) ParseClaimScripts(block *btcutil.Block, bn *blockNode, view
 		_ = b.claimTrie.ResetHeight(b.claimTrie.Height() - 1)
 		return errors.Errorf("height: %d, computed hash: %s != header's ClaimTrie: %s", ht, *hash, bn.claimTrie)
 	}
+
+	// the block stands without the index
+	if channels != nil {
+		if err = channels.write(); err != nil {
+			b.claimTrie.DisableChannelIndex(errors.Wrapf(err, "at height %d", ht))
+		}
+	}
 	return nil
 }
 
+// claimWriter receives the claim changes parsed out of transactions; the ClaimTrie is one.
+type claimWriter interface {
+	Height() int32
+	AddClaim(name []byte, op wire.OutPoint, id change.ClaimID, amt int64) error
+	UpdateClaim(name []byte, op wire.OutPoint, amt int64, id change.ClaimID) error
+	SpendClaim(name []byte, op wire.OutPoint, id change.ClaimID) error
+	AddSupport(name []byte, op wire.OutPoint, amt int64, id change.ClaimID) error
+	SpendSupport(name []byte, op wire.OutPoint, id change.ClaimID) error
+}
+
 type handler struct {
-	ht    int32
-	tx    *btcutil.Tx
-	view  *UtxoViewpoint
-	spent map[string][]byte
+	ht       int32
+	tx       *btcutil.Tx
+	view     *UtxoViewpoint
+	spent    map[string][]byte
+	channels *channelIndexer // nil unless the channel index is to be kept up to date
 }
 
-func (h *handler) handleTxIns(ct *claimtrie.ClaimTrie) error {
+func (h *handler) handleTxIns(ct claimWriter) error {
 	if IsCoinBase(h.tx) {
 		return nil
 	}
@@ -111,7 +153,7 @@ func (h *handler) handleTxIns(ct *claimtrie.ClaimTrie) error {
 	return nil
 }
 
-func (h *handler) handleTxOuts(ct *claimtrie.ClaimTrie) error {
+func (h *handler) handleTxOuts(ct claimWriter) error {
 	for i, txOut := range h.tx.MsgTx().TxOut {
 		op := *wire.NewOutPoint(h.tx.Hash(), uint32(i))
 		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
@@ -130,13 +172,16 @@ func (h *handler) handleTxOuts(ct *claimtrie.ClaimTrie) error {
 		case txscript.OP_CLAIMNAME:
 			id = change.NewClaimID(op)
 			err = ct.AddClaim(name, op, id, amt)
+			if err == nil && h.channels != nil {
+				h.channels.add(h.tx.MsgTx(), op, id, cs, txOut.PkScript)
+			}
 		case txscript.OP_SUPPORTCLAIM:
 			copy(id[:], cs.ClaimID())
 			err = ct.AddSupport(name, op, amt, id)
 		case txscript.OP_UPDATECLAIM:
 			// old code wouldn't run the update if name or claimID didn't match existing data
 			// that was a safety feature, but it should have rejected the transaction instead
-			// TODO: reject transactions with invalid update commands
+			// the mempool now rejects them, but they can still be mined by others
 			copy(id[:], cs.ClaimID())
 			normName := normalization.NormalizeIfNecessary(name, ct.Height())
 			if !bytes.Equal(h.spent[id.Key()], normName) {
@@ -147,6 +192,9 @@ func (h *handler) handleTxOuts(ct *claimtrie.ClaimTrie) error {
 
 			delete(h.spent, id.Key())
 			err = ct.UpdateClaim(name, op, amt, id)
+			if err == nil && h.channels != nil {
+				h.channels.add(h.tx.MsgTx(), op, id, cs, txOut.PkScript)
+			}
 		}
 		if err != nil {
 			return errors.Wrapf(err, "handleTxOuts")
@@ -155,6 +203,153 @@ func (h *handler) handleTxOuts(ct *claimtrie.ClaimTrie) error {
 	return nil
 }
 
+// handleSpentChannels takes the claims that were spent and not updated by the transaction out of the channel index,
+// along with the channels among them.
+func (h *handler) handleSpentChannels() {
+	if h.channels == nil {
+		return
+	}
+	for key := range h.spent {
+		var id change.ClaimID
+		copy(id[:], key)
+		h.channels.pending = append(h.channels.pending, channelClaim{id: id})
+	}
+}
+
+// channelIndexer keeps the channel index of the ClaimTrie up to date with the claims of a block. They're collected
+// while the block is parsed, and only decoded and written once the ClaimTrie has taken it.
+type channelIndexer struct {
+	ct      *claimtrie.ClaimTrie
+	height  int32
+	params  *chaincfg.Params
+	pending []channelClaim
+}
+
+// channelClaim is a claim of a block for the channel index, or one taken out of it when it has no script.
+type channelClaim struct {
+	tx       *wire.MsgTx
+	op       wire.OutPoint
+	id       change.ClaimID
+	cs       *txscript.ClaimScript
+	pkScript []byte
+}
+
+func (c *channelIndexer) add(tx *wire.MsgTx, op wire.OutPoint, id change.ClaimID, cs *txscript.ClaimScript,
+	pkScript []byte) {
+	c.pending = append(c.pending, channelClaim{tx: tx, op: op, id: id, cs: cs, pkScript: pkScript})
+}
+
+// write records the claims of the block in the order they came in, and moves the index to the block's height.
+func (c *channelIndexer) write() error {
+	for _, p := range c.pending {
+		var err error
+		if p.cs == nil {
+			err = c.remove(p.id)
+		} else {
+			err = c.index(p.tx, p.op, p.id, p.cs, p.pkScript)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	c.pending = nil
+	return c.ct.SetChannelIndexHeight(c.height)
+}
+
+// index records the public key of a channel, or the channel that validly signed a stream. A claim that's neither,
+// or that can't be decoded, is taken out of the index in case an earlier version of it was there.
+func (c *channelIndexer) index(tx *wire.MsgTx, op wire.OutPoint, id change.ClaimID, cs *txscript.ClaimScript,
+	pkScript []byte) error {
+
+	var key []byte
+	var signer change.ClaimID
+	if claim, err := metadata.Decode(cs.Value()); err == nil {
+		switch {
+		case claim.Type == metadata.TypeChannel:
+			key = claim.Channel.PublicKey
+		case claim.Type == metadata.TypeStream && claim.Signed():
+			valid, err := c.verify(claim, tx, pkScript[cs.Size():])
+			if err != nil {
+				return err
+			}
+			if valid {
+				signer = claim.SigningChannel
+			}
+		}
+	}
+
+	if err := c.ct.SetChannelKey(c.height, id, key); err != nil {
+		return err
+	}
+	return c.ct.SetChannelMember(c.height, signer, channel.Member{ClaimID: id, Name: cs.Name(), OutPoint: op})
+}
+
+// verify returns whether a claim is signed by its channel, which must be in the index already.
+func (c *channelIndexer) verify(claim *metadata.Claim, tx *wire.MsgTx, script []byte) (bool, error) {
+
+	key, err := c.ct.ChannelKey(claim.SigningChannel)
+	if err != nil || key == nil {
+		return false, err
+	}
+
+	var address btcutil.Address
+	_, addresses, _, _ := txscript.ExtractPkScriptAddrs(script, c.params)
+	if len(addresses) > 0 {
+		address = addresses[0]
+	}
+	valid, err := claim.VerifySignature(key, tx, address)
+	return err == nil && valid, nil
+}
+
+func (c *channelIndexer) remove(id change.ClaimID) error {
+	if err := c.ct.SetChannelKey(c.height, id, nil); err != nil {
+		return err
+	}
+	return c.ct.SetChannelMember(c.height, change.ClaimID{}, channel.Member{ClaimID: id})
+}
+
+// indexChannels gives the channel index a block that the ClaimTrie has already, to catch up with it.
+func (b *BlockChain) indexChannels(block *btcutil.Block, view *UtxoViewpoint) error {
+
+	// the changes were made to the ClaimTrie before; they're only parsed again to follow the claims
+	collector := &changeCollector{height: block.Height() - 1}
+	channels := &channelIndexer{ct: b.claimTrie, height: block.Height(), params: b.chainParams}
+	for _, tx := range block.Transactions() {
+		h := handler{block.Height(), tx, view, map[string][]byte{}, channels}
+		if err := h.handleTxIns(collector); err != nil {
+			return err
+		}
+		if err := h.handleTxOuts(collector); err != nil {
+			return err
+		}
+		h.handleSpentChannels()
+	}
+	return channels.write()
+}
+
+// GetClaimsInChannel returns up to limit stream claims validly signed by the channel, after skipping the first
+// start of them, in the order they joined it. It requires the channel index.
+func (b *BlockChain) GetClaimsInChannel(id change.ClaimID, start, limit int) ([]channel.Member, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.claimTrie.ChannelMembers(id, start, limit)
+}
+
+// GetTopClaims returns up to limit of the claims, or of the names when byName is set, ranked by their effective
+// amounts at the height, leaving out the names without the prefix and the entries below minAmount.
+// The prefix is normalized at the height.
+func (b *BlockChain) GetTopClaims(height int32, byName bool, prefix string, minAmount int64, limit int) ([]ranking.Entry, error) {
+
+	normalizedPrefix := normalization.NormalizeIfNecessary([]byte(prefix), height)
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.claimTrie.TopRanked(height, byName, normalizedPrefix, minAmount, limit)
+}
+
 func (b *BlockChain) GetNamesChangedInBlock(height int32) ([]string, error) {
 	b.chainLock.RLock()
 	defer b.chainLock.RUnlock()
@@ -162,6 +357,14 @@ func (b *BlockChain) GetNamesChangedInBlock(height int32) ([]string, error) {
 	return b.claimTrie.NamesChangedInBlock(height)
 }
 
+// GetClaimTrieChanges returns the changes the block at height made to the claims and supports, from the change index.
+func (b *BlockChain) GetClaimTrieChanges(height int32) ([]change.Change, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.claimTrie.Changes(height)
+}
+
 func (b *BlockChain) GetClaimsForName(height int32, name string) (string, *node.Node, error) {
 
 	normalizedName := normalization.NormalizeIfNecessary([]byte(name), height)
@@ -181,3 +384,418 @@ func (b *BlockChain) GetClaimsForName(height int32, name string) (string, *node.
 	n.SortClaimsByBid()
 	return string(normalizedName), n, nil
 }
+
+// ClaimExists returns whether a claim with the given normalized name and claim ID is in the ClaimTrie
+// at the current tip, whether or not it is active yet.
+func (b *BlockChain) ClaimExists(name []byte, id change.ClaimID) (bool, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	n, err := b.claimTrie.NodeAt(b.claimTrie.Height(), name)
+	if err != nil || n == nil {
+		return false, err
+	}
+	for _, c := range n.Claims {
+		if c.ClaimID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListNames calls f, in byte order, with each name that starts with prefix, is not before start, and has a
+// controlling claim at the height, along with its node. The prefix and start are normalized at the height.
+// Return false on f to stop the iteration.
+func (b *BlockChain) ListNames(height int32, prefix, start string, f func(name string, n *node.Node) bool) error {
+
+	normalizedPrefix := normalization.NormalizeIfNecessary([]byte(prefix), height)
+	normalizedStart := normalization.NormalizeIfNecessary([]byte(start), height)
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.claimTrie.ListNames(height, normalizedPrefix, normalizedStart, func(name []byte, n *node.Node) bool {
+		return f(string(name), n)
+	})
+}
+
+// GetNameProof returns a proof for the claim matching the partial claim ID, or for the winning claim when
+// the partial ID is empty. Proofs can only be produced against the current tip of the ClaimTrie.
+func (b *BlockChain) GetNameProof(height int32, name string, partialID string) (string, *proof.Proof, error) {
+
+	normalizedName := normalization.NormalizeIfNecessary([]byte(name), height)
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if height != b.claimTrie.Height() {
+		return string(normalizedName), nil, fmt.Errorf("proofs are only available at the tip height %d", b.claimTrie.Height())
+	}
+
+	var id *change.ClaimID
+	if len(partialID) > 0 {
+		n, err := b.claimTrie.NodeAt(height, normalizedName)
+		if err != nil {
+			return string(normalizedName), nil, err
+		}
+		if n == nil {
+			return string(normalizedName), nil, fmt.Errorf("name does not exist at height %d: %s", height, name)
+		}
+		for _, c := range n.Claims {
+			if !strings.HasPrefix(c.ClaimID.String(), partialID) {
+				continue
+			}
+			if id != nil {
+				return string(normalizedName), nil, fmt.Errorf("claim ID %s is ambiguous on %s", partialID, name)
+			}
+			cid := c.ClaimID
+			id = &cid
+		}
+		if id == nil {
+			return string(normalizedName), nil, fmt.Errorf("no claim matches %s on %s", partialID, name)
+		}
+	}
+
+	p, err := b.claimTrie.NameProof(normalizedName, id)
+	return string(normalizedName), p, err
+}
+
+// GetClaimByID returns the normalized name of the claim along with its node and the index of the claim in
+// the node's bid-sorted claims. It requires the claim ID index, which tells the claims that left the trie
+// at the tip without looking at their nodes.
+func (b *BlockChain) GetClaimByID(height int32, id change.ClaimID) (string, *node.Node, int, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	c, err := b.claimTrie.Claim(id)
+	if err != nil {
+		return "", nil, -1, err
+	}
+	if c == nil || c.Created > height {
+		return "", nil, -1, fmt.Errorf("claim does not exist at height %d: %s", height, id)
+	}
+
+	normalizedName := normalization.NormalizeIfNecessary(c.Name, height)
+	if c.Status == claimid.Removed && height == b.claimTrie.Height() {
+		return string(normalizedName), nil, -1, fmt.Errorf("claim was spent or expired by height %d: %s, "+
+			"latest version %s", height, id, c.OutPoint)
+	}
+	n, err := b.claimTrie.NodeAt(height, normalizedName)
+	if err != nil {
+		return string(normalizedName), nil, -1, err
+	}
+	if n != nil {
+		n.SortClaimsByBid()
+		for i := range n.Claims {
+			if n.Claims[i].ClaimID == id {
+				return string(normalizedName), n, i, nil
+			}
+		}
+	}
+	return string(normalizedName), nil, -1, fmt.Errorf("claim is not in the trie at height %d: %s", height, id)
+}
+
+// GetClaimHistory returns the ID of the claim matching the partial claim ID along with the changes
+// made to it and its supports, up to the given height.
+func (b *BlockChain) GetClaimHistory(height int32, name string, partialID string) (string, change.ClaimID, []node.HistoryEntry, error) {
+
+	normalizedName := normalization.NormalizeIfNecessary([]byte(name), height)
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	var id change.ClaimID
+	entries, err := b.claimTrie.History(height, normalizedName)
+	if err != nil {
+		return string(normalizedName), id, nil, err
+	}
+
+	var history []node.HistoryEntry
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Change.ClaimID.String(), partialID) {
+			continue
+		}
+		if len(history) > 0 && e.Change.ClaimID != id {
+			return string(normalizedName), id, nil, fmt.Errorf("claim ID %s is ambiguous on %s", partialID, name)
+		}
+		id = e.Change.ClaimID
+		history = append(history, e)
+	}
+	if len(history) == 0 {
+		return string(normalizedName), id, nil, fmt.Errorf("no claim matches %s on %s at height %d", partialID, name, height)
+	}
+	return string(normalizedName), id, history, nil
+}
+
+// GetTakeoverHistory returns the takeovers of a name between the heights, inclusive.
+// A negative toHeight, or one past the tip, stands for the tip. It requires the takeover index.
+// The takeovers before the normalization fork are those of the name as given, the later ones
+// those of the normalized name.
+func (b *BlockChain) GetTakeoverHistory(name string, fromHeight, toHeight int32) (string, []node.Takeover, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if toHeight < 0 || toHeight > b.claimTrie.Height() {
+		toHeight = b.claimTrie.Height()
+	}
+	normalizedName := normalization.NormalizeIfNecessary([]byte(name), toHeight)
+
+	fork := param.ActiveParams.NormalizedNameForkHeight
+	if fromHeight >= fork || toHeight < fork {
+		takeovers, err := b.claimTrie.Takeovers(normalizedName, fromHeight, toHeight)
+		return string(normalizedName), takeovers, err
+	}
+
+	// the takeovers before the normalization fork are recorded under the name as it was given
+	takeovers, err := b.claimTrie.Takeovers([]byte(name), fromHeight, fork-1)
+	if err != nil {
+		return string(normalizedName), nil, err
+	}
+	normalized, err := b.claimTrie.Takeovers(normalizedName, fork, toHeight)
+	return string(normalizedName), append(takeovers, normalized...), err
+}
+
+// ClaimEvents is the data of an NTClaimEvents notification.
+type ClaimEvents struct {
+	Block        *btcutil.Block
+	Disconnected bool
+	Events       []claimtrie.Event
+}
+
+// WatchClaimEvents starts (or stops) the NTClaimEvents notifications. Every call that starts them
+// must be paired with one that stops them; they are sent as long as anybody is watching.
+func (b *BlockChain) WatchClaimEvents(watch bool) {
+	if watch {
+		atomic.AddInt32(&b.claimEventWatchers, 1)
+	} else {
+		atomic.AddInt32(&b.claimEventWatchers, -1)
+	}
+}
+
+// claimEvents returns the events of the block at the tip of the ClaimTrie, or nil when nobody is watching.
+// Failing to compute them is not a reason to reject the block; it gets logged instead.
+func (b *BlockChain) claimEvents(block *btcutil.Block, disconnected bool) *ClaimEvents {
+	if atomic.LoadInt32(&b.claimEventWatchers) <= 0 {
+		return nil
+	}
+	events, err := b.claimTrie.Events(block.Height())
+	if err != nil {
+		log.Warnf("Unable to compute the claim events of block %s: %v", block.Hash(), err)
+		return nil
+	}
+	return &ClaimEvents{Block: block, Disconnected: disconnected, Events: events}
+}
+
+// changeCollector is a claimWriter that keeps the changes rather than applying them.
+type changeCollector struct {
+	height  int32
+	changes []change.Change
+}
+
+func (c *changeCollector) Height() int32 {
+	return c.height
+}
+
+func (c *changeCollector) AddClaim(name []byte, op wire.OutPoint, id change.ClaimID, amt int64) error {
+	c.changes = append(c.changes, change.Change{Type: change.AddClaim, Name: name, OutPoint: op, Amount: amt, ClaimID: id})
+	return nil
+}
+
+func (c *changeCollector) UpdateClaim(name []byte, op wire.OutPoint, amt int64, id change.ClaimID) error {
+	c.changes = append(c.changes, change.Change{Type: change.UpdateClaim, Name: name, OutPoint: op, Amount: amt, ClaimID: id})
+	return nil
+}
+
+func (c *changeCollector) SpendClaim(name []byte, op wire.OutPoint, id change.ClaimID) error {
+	c.changes = append(c.changes, change.Change{Type: change.SpendClaim, Name: name, OutPoint: op, ClaimID: id})
+	return nil
+}
+
+func (c *changeCollector) AddSupport(name []byte, op wire.OutPoint, amt int64, id change.ClaimID) error {
+	c.changes = append(c.changes, change.Change{Type: change.AddSupport, Name: name, OutPoint: op, Amount: amt, ClaimID: id})
+	return nil
+}
+
+func (c *changeCollector) SpendSupport(name []byte, op wire.OutPoint, id change.ClaimID) error {
+	c.changes = append(c.changes, change.Change{Type: change.SpendSupport, Name: name, OutPoint: op, ClaimID: id})
+	return nil
+}
+
+// GetPendingClaimsForName returns the node of the name at the tip with the claim changes made by the
+// unconfirmed transactions applied as if they were mined in the next block. The transactions may spend
+// outputs of the main chain or of each other, in any order; other transactions are skipped.
+func (b *BlockChain) GetPendingClaimsForName(name string, txs []*btcutil.Tx) (string, *node.Node, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	height := b.claimTrie.Height()
+	normalizedName := normalization.NormalizeIfNecessary([]byte(name), height+1)
+
+	txs = sortByDependency(txs)
+	needed := make(map[wire.OutPoint]struct{})
+	view := NewUtxoViewpoint()
+	for _, tx := range txs {
+		view.AddTxOuts(tx, height+1)
+	}
+	for _, tx := range txs {
+		for _, txIn := range tx.MsgTx().TxIn {
+			if view.LookupEntry(txIn.PreviousOutPoint) == nil {
+				needed[txIn.PreviousOutPoint] = struct{}{}
+			}
+		}
+	}
+	if err := view.fetchUtxosMain(b.db, needed); err != nil {
+		return string(normalizedName), nil, errors.Wrapf(err, "in fetch utxos")
+	}
+
+	collector := &changeCollector{height: height}
+	for _, tx := range txs {
+		// the handler expects all inputs to be available (as they are in a block)
+		available := true
+		for _, txIn := range tx.MsgTx().TxIn {
+			if view.LookupEntry(txIn.PreviousOutPoint) == nil {
+				available = false
+				break
+			}
+		}
+		if !available {
+			continue
+		}
+		h := handler{height + 1, tx, view, map[string][]byte{}, nil}
+		if err := h.handleTxIns(collector); err != nil {
+			return string(normalizedName), nil, err
+		}
+		if err := h.handleTxOuts(collector); err != nil {
+			return string(normalizedName), nil, err
+		}
+	}
+
+	n, err := b.claimTrie.NodeWithPending(normalizedName, collector.changes)
+	if err != nil {
+		return string(normalizedName), nil, err
+	}
+	if n == nil {
+		return string(normalizedName), nil, fmt.Errorf("name does not exist at height %d or in the mempool: %s", height, name)
+	}
+
+	n.SortClaimsByBid()
+	return string(normalizedName), n, nil
+}
+
+// sortByDependency orders the transactions so that those spending the outputs of others come after them.
+func sortByDependency(txs []*btcutil.Tx) []*btcutil.Tx {
+
+	byHash := make(map[chainhash.Hash]*btcutil.Tx, len(txs))
+	for _, tx := range txs {
+		byHash[*tx.Hash()] = tx
+	}
+
+	sorted := make([]*btcutil.Tx, 0, len(txs))
+	visited := make(map[chainhash.Hash]bool, len(txs))
+	var visit func(tx *btcutil.Tx)
+	visit = func(tx *btcutil.Tx) {
+		if visited[*tx.Hash()] {
+			return
+		}
+		visited[*tx.Hash()] = true
+		for _, txIn := range tx.MsgTx().TxIn {
+			if parent, ok := byHash[txIn.PreviousOutPoint.Hash]; ok {
+				visit(parent)
+			}
+		}
+		sorted = append(sorted, tx)
+	}
+	for _, tx := range txs {
+		visit(tx)
+	}
+	return sorted
+}
+
+// ClaimTrieInfo summarizes the ClaimTrie at its current height.
+type ClaimTrieInfo struct {
+	Height        int32
+	MerkleHash    chainhash.Hash
+	Stats         *stats.Stats // nil when the statistics are disabled
+	ExpiringNames int64        // names whose controlling claim expires within the requested number of blocks; needs the statistics
+
+	NodeCacheHits   uint64
+	NodeCacheMisses uint64
+}
+
+// GetClaimTrieInfo returns the height and hash of the ClaimTrie along with its statistics and the number of
+// names whose controlling claim expires within the given number of blocks, when enabled, and the node cache
+// hit counts.
+func (b *BlockChain) GetClaimTrieInfo(expiringWithin int32) (*ClaimTrieInfo, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	info := &ClaimTrieInfo{
+		Height:     b.claimTrie.Height(),
+		MerkleHash: *b.claimTrie.MerkleHash(),
+	}
+	info.NodeCacheHits, info.NodeCacheMisses = b.claimTrie.NodeCacheStats()
+
+	s, err := b.claimTrie.Stats()
+	if err == claimtrie.ErrNoStats {
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info.Stats = &s
+
+	info.ExpiringNames, err = b.claimTrie.ExpiringNames(expiringWithin)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// VerifyClaimTrie recomputes the ClaimTrie from its nodes at each height from fromHeight to toHeight, inclusive,
+// and compares it with the hash stored at that height and with the ClaimTrie of the block header. It returns
+// the last height along with the first height at which they differ, or nil when they all match. A negative toHeight means the tip.
+// The chain is only locked while each height is verified, so blocks keep being connected in the meantime.
+func (b *BlockChain) VerifyClaimTrie(fromHeight, toHeight int32, interrupt <-chan struct{}) (int32, *claimtrie.Divergence, error) {
+
+	if toHeight < 0 {
+		b.chainLock.RLock()
+		toHeight = b.claimTrie.Height()
+		b.chainLock.RUnlock()
+	}
+
+	header := func(height int32) (*chainhash.Hash, error) {
+		n := b.bestChain.NodeByHeight(height)
+		if n == nil {
+			return nil, nil
+		}
+		return &n.claimTrie, nil
+	}
+	d, err := b.claimTrie.Verify(fromHeight, toHeight, header, b.chainLock.RLocker(), interrupt)
+	return toHeight, d, err
+}
+
+// GetExpiringClaims returns the height of the ClaimTrie along with the activations and expirations of claims
+// and supports due from fromHeight to toHeight, inclusive, as things stand at that height.
+func (b *BlockChain) GetExpiringClaims(fromHeight, toHeight int32) (int32, []claimtrie.ScheduledEvent, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	events, err := b.claimTrie.ScheduledEvents(fromHeight, toHeight)
+	return b.claimTrie.Height(), events, err
+}
+
+// SimulateBid returns what a bid of amount on name would do if it were placed in the next block.
+// See ClaimTrie.SimulateBid for the types of bids.
+func (b *BlockChain) SimulateBid(name string, amount int64, typ change.ChangeType, id change.ClaimID) (*claimtrie.BidSimulation, error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.claimTrie.SimulateBid([]byte(name), amount, typ, id)
+}
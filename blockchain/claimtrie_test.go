@@ -0,0 +1,317 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/lbryio/lbcd/btcec"
+	"github.com/lbryio/lbcd/chaincfg"
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/channel"
+	claimtrieconfig "github.com/lbryio/lbcd/claimtrie/config"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/param"
+	"github.com/lbryio/lbcd/txscript"
+	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
+)
+
+// TestPendingClaimChanges ensures the claim changes of unconfirmed transactions
+// are collected in the order the transactions depend on each other.
+func TestPendingClaimChanges(t *testing.T) {
+	funding := wire.NewMsgTx(1)
+	funding.AddTxOut(wire.NewTxOut(10, []byte{txscript.OP_TRUE}))
+	fundingTx := btcutil.NewTx(funding)
+
+	claimScript, err := txscript.ClaimNameScript("test", "value")
+	if err != nil {
+		t.Fatalf("ClaimNameScript: %v", err)
+	}
+	claim := wire.NewMsgTx(1)
+	claim.AddTxIn(wire.NewTxIn(wire.NewOutPoint(fundingTx.Hash(), 0), nil, nil))
+	claim.AddTxOut(wire.NewTxOut(9, claimScript))
+	claimTx := btcutil.NewTx(claim)
+	claimOut := wire.NewOutPoint(claimTx.Hash(), 0)
+	id := change.NewClaimID(*claimOut)
+
+	updateScript, err := txscript.UpdateClaimScript("test", id[:], "value2")
+	if err != nil {
+		t.Fatalf("UpdateClaimScript: %v", err)
+	}
+	update := wire.NewMsgTx(1)
+	update.AddTxIn(wire.NewTxIn(claimOut, nil, nil))
+	update.AddTxOut(wire.NewTxOut(8, updateScript))
+	updateTx := btcutil.NewTx(update)
+
+	txs := sortByDependency([]*btcutil.Tx{updateTx, claimTx})
+	if len(txs) != 2 || txs[0] != claimTx || txs[1] != updateTx {
+		t.Fatalf("sortByDependency: unexpected order %v", txs)
+	}
+
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(fundingTx, 1)
+	for _, tx := range txs {
+		view.AddTxOuts(tx, 2)
+	}
+	collector := &changeCollector{height: 1}
+	for _, tx := range txs {
+		h := handler{2, tx, view, map[string][]byte{}, nil}
+		if err := h.handleTxIns(collector); err != nil {
+			t.Fatalf("handleTxIns: %v", err)
+		}
+		if err := h.handleTxOuts(collector); err != nil {
+			t.Fatalf("handleTxOuts: %v", err)
+		}
+	}
+
+	want := []change.ChangeType{change.AddClaim, change.SpendClaim, change.UpdateClaim}
+	if len(collector.changes) != len(want) {
+		t.Fatalf("unexpected number of changes: got %d, want %d",
+			len(collector.changes), len(want))
+	}
+	for i, chg := range collector.changes {
+		if chg.Type != want[i] || chg.ClaimID != id || string(chg.Name) != "test" {
+			t.Fatalf("change %d: got type %v, ID %s, name %s", i,
+				chg.Type, chg.ClaimID, chg.Name)
+		}
+	}
+}
+
+// TestChannelIndexer ensures the streams validly signed by a channel are indexed
+// with it, and taken out of the index when they're spent.
+func TestChannelIndexer(t *testing.T) {
+	cfg := claimtrieconfig.DefaultConfig
+	cfg.DataDir = t.TempDir()
+	cfg.Memory = true
+	cfg.ChannelIndex = true
+	ct, err := claimtrie.New(cfg)
+	if err != nil {
+		t.Fatalf("claimtrie.New: %v", err)
+	}
+	defer ct.Close()
+
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+
+	funding := wire.NewMsgTx(1)
+	for i := 0; i < 3; i++ {
+		funding.AddTxOut(wire.NewTxOut(10, []byte{txscript.OP_TRUE}))
+	}
+	fundingTx := btcutil.NewTx(funding)
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(fundingTx, 1)
+
+	// the channel, whose value has the public key as its only field
+	key := priv.PubKey().SerializeCompressed()
+	channelValue := append([]byte{0, 0x12, byte(len(key) + 2), 0x0a, byte(len(key))}, key...)
+	channelScript, err := txscript.ClaimNameScript("@channel", string(channelValue))
+	if err != nil {
+		t.Fatalf("ClaimNameScript: %v", err)
+	}
+	channelMsgTx := wire.NewMsgTx(1)
+	channelMsgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(fundingTx.Hash(), 0), nil, nil))
+	channelMsgTx.AddTxOut(wire.NewTxOut(9, channelScript))
+	channelTx := btcutil.NewTx(channelMsgTx)
+	channelID := change.NewClaimID(*wire.NewOutPoint(channelTx.Hash(), 0))
+
+	// a stream signed for the outpoint spent by the first input of its transaction
+	stream := func(prevOut *wire.OutPoint, signedOut *wire.OutPoint) *btcutil.Tx {
+		payload := []byte{0x0a, 0}
+		var index [4]byte
+		binary.LittleEndian.PutUint32(index[:], signedOut.Index)
+		message := append(append(append(signedOut.Hash[:], index[:]...), channelID[:]...), payload...)
+		digest := sha256.Sum256(message)
+		sig, err := priv.Sign(digest[:])
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		signature := make([]byte, 64)
+		sig.R.FillBytes(signature[:32])
+		sig.S.FillBytes(signature[32:])
+		value := append(append(append([]byte{1}, channelID[:]...), signature...), payload...)
+
+		script, err := txscript.ClaimNameScript("stream", string(value))
+		if err != nil {
+			t.Fatalf("ClaimNameScript: %v", err)
+		}
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(8, script))
+		return btcutil.NewTx(tx)
+	}
+	signedTx := stream(wire.NewOutPoint(fundingTx.Hash(), 1), wire.NewOutPoint(fundingTx.Hash(), 1))
+	forgedTx := stream(wire.NewOutPoint(fundingTx.Hash(), 2), wire.NewOutPoint(fundingTx.Hash(), 1))
+	signedOut := wire.NewOutPoint(signedTx.Hash(), 0)
+
+	abandon := wire.NewMsgTx(1)
+	abandon.AddTxIn(wire.NewTxIn(signedOut, nil, nil))
+	abandon.AddTxOut(wire.NewTxOut(7, []byte{txscript.OP_TRUE}))
+	abandonTx := btcutil.NewTx(abandon)
+
+	handle := func(height int32, tx *btcutil.Tx) {
+		view.AddTxOuts(tx, height)
+		collector := &changeCollector{height: height - 1}
+		channels := &channelIndexer{ct: ct, height: height, params: &chaincfg.RegressionNetParams}
+		h := handler{height, tx, view, map[string][]byte{}, channels}
+		if err := h.handleTxIns(collector); err != nil {
+			t.Fatalf("handleTxIns: %v", err)
+		}
+		if err := h.handleTxOuts(collector); err != nil {
+			t.Fatalf("handleTxOuts: %v", err)
+		}
+		h.handleSpentChannels()
+		if err := channels.write(); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	members := func() []channel.Member {
+		members, err := ct.ChannelMembers(channelID, 0, 10)
+		if err != nil {
+			t.Fatalf("ChannelMembers: %v", err)
+		}
+		return members
+	}
+
+	handle(2, channelTx)
+	handle(3, signedTx)
+	handle(3, forgedTx)
+	got := members()
+	if len(got) != 1 || got[0].ClaimID != change.NewClaimID(*signedOut) || got[0].Height != 3 ||
+		string(got[0].Name) != "stream" {
+		t.Fatalf("unexpected members %v", got)
+	}
+
+	handle(4, abandonTx)
+	if got := members(); len(got) != 0 {
+		t.Fatalf("unexpected members after the stream was spent %v", got)
+	}
+}
+
+// TestTakeoverHistoryAcrossFork ensures the takeover history of a name covers
+// the takeovers of the name as given before the normalization fork and those of
+// the normalized name after it.
+func TestTakeoverHistoryAcrossFork(t *testing.T) {
+	params := param.ActiveParams
+	defer func() { param.ActiveParams = params }()
+	param.ActiveParams.NormalizedNameForkHeight = 3
+
+	cfg := claimtrieconfig.DefaultConfig
+	cfg.DataDir = t.TempDir()
+	cfg.Memory = true
+	cfg.TakeoverIndex = true
+	ct, err := claimtrie.New(cfg)
+	if err != nil {
+		t.Fatalf("claimtrie.New: %v", err)
+	}
+	defer ct.Close()
+
+	appendBlocks := func(n int) {
+		for ; n > 0; n-- {
+			if err := ct.AppendBlock(); err != nil {
+				t.Fatalf("AppendBlock: %v", err)
+			}
+		}
+	}
+
+	hash := chainhash.HashH([]byte{1, 2, 3})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	if err := ct.AddClaim([]byte("Test"), o1, id1, 1); err != nil {
+		t.Fatalf("AddClaim: %v", err)
+	}
+	appendBlocks(4)
+
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	if err := ct.AddClaim([]byte("test"), o2, id2, 2); err != nil {
+		t.Fatalf("AddClaim: %v", err)
+	}
+	appendBlocks(1)
+
+	b := &BlockChain{claimTrie: ct}
+	name, takeovers, err := b.GetTakeoverHistory("Test", 0, -1)
+	if err != nil {
+		t.Fatalf("GetTakeoverHistory: %v", err)
+	}
+	if name != "test" {
+		t.Fatalf("unexpected normalized name %s", name)
+	}
+	if len(takeovers) == 0 || takeovers[0] != (node.Takeover{Height: 1, ClaimID: id1, Amount: 1}) {
+		t.Fatalf("the takeover before the fork is missing: %v", takeovers)
+	}
+	last := takeovers[len(takeovers)-1]
+	if last.Height != 5 || last.PreviousClaimID != id1 || last.ClaimID != id2 {
+		t.Fatalf("the takeover after the fork is missing: %v", takeovers)
+	}
+	for i := 1; i < len(takeovers); i++ {
+		if takeovers[i].Height <= takeovers[i-1].Height {
+			t.Fatalf("the takeovers are out of order: %v", takeovers)
+		}
+	}
+
+	// a range on one side of the fork only looks up one of the names
+	_, takeovers, err = b.GetTakeoverHistory("Test", 0, 2)
+	if err != nil || len(takeovers) != 1 {
+		t.Fatalf("got %v (err %v) before the fork, want 1 takeover", takeovers, err)
+	}
+	_, takeovers, err = b.GetTakeoverHistory("Test", 5, 5)
+	if err != nil || len(takeovers) != 1 || takeovers[0] != last {
+		t.Fatalf("got %v (err %v) after the fork, want %v", takeovers, err, last)
+	}
+}
+
+// TestAddSpentTxOuts ensures the view built from the spend journal of a block
+// has the outputs spent by its transactions, including the ones made earlier in
+// the block, so its claims can be parsed without connecting the blocks before.
+func TestAddSpentTxOuts(t *testing.T) {
+	claimScript, err := txscript.ClaimNameScript("test", "value")
+	if err != nil {
+		t.Fatalf("ClaimNameScript: %v", err)
+	}
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex}})
+	coinbase.AddTxOut(wire.NewTxOut(1, []byte{txscript.OP_TRUE}))
+
+	claimOut := wire.OutPoint{Hash: chainhash.Hash{1}}
+	first := wire.NewMsgTx(1)
+	first.AddTxIn(wire.NewTxIn(&claimOut, nil, nil))
+	first.AddTxOut(wire.NewTxOut(9, []byte{txscript.OP_TRUE}))
+	firstHash := first.TxHash()
+	second := wire.NewMsgTx(1)
+	second.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&firstHash, 0), nil, nil))
+	second.AddTxOut(wire.NewTxOut(8, []byte{txscript.OP_TRUE}))
+
+	block := btcutil.NewBlock(&wire.MsgBlock{
+		Transactions: []*wire.MsgTx{coinbase, first, second},
+	})
+	stxos := []SpentTxOut{
+		{Amount: 10, PkScript: claimScript, Height: 5},
+		{Amount: 9, PkScript: []byte{txscript.OP_TRUE}, Height: 7},
+	}
+
+	view := NewUtxoViewpoint()
+	if err := view.addSpentTxOuts(block, stxos); err != nil {
+		t.Fatalf("addSpentTxOuts: %v", err)
+	}
+	entry := view.LookupEntry(claimOut)
+	if entry == nil || entry.Amount() != 10 || entry.BlockHeight() != 5 || !entry.IsSpent() {
+		t.Fatalf("unexpected entry for the claim: %+v", entry)
+	}
+	entry = view.LookupEntry(second.TxIn[0].PreviousOutPoint)
+	if entry == nil || entry.Amount() != 9 || entry.BlockHeight() != 7 {
+		t.Fatalf("unexpected entry for the output spent in the block: %+v", entry)
+	}
+
+	if err := NewUtxoViewpoint().addSpentTxOuts(block, stxos[:1]); err == nil {
+		t.Fatal("addSpentTxOuts: no error for a short spend journal")
+	}
+	if err := NewUtxoViewpoint().addSpentTxOuts(block, append(stxos, stxos[0])); err == nil {
+		t.Fatal("addSpentTxOuts: no error for a long spend journal")
+	}
+}
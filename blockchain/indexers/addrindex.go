@@ -971,8 +971,13 @@ func NewAddrIndex(db database.DB, chainParams *chaincfg.Params) *AddrIndex {
 	}
 }
 
-// DropAddrIndex drops the address index from the provided database if it
-// exists.
+// DropAddrIndex drops the address index, along with the claim address index
+// that is enabled with it, from the provided database if they exist.
 func DropAddrIndex(db database.DB, interrupt <-chan struct{}) error {
+	err := dropIndex(db, claimAddrIndexKey, claimAddrIndexName, interrupt)
+	if err != nil {
+		return err
+	}
+
 	return dropIndex(db, addrIndexKey, addrIndexName, interrupt)
 }
@@ -10,7 +10,10 @@ import (
 	"github.com/lbryio/lbcd/blockchain"
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/normalization"
 	"github.com/lbryio/lbcd/database"
+	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
 	"github.com/lbryio/lbcutil/gcs"
@@ -20,10 +23,14 @@ import (
 const (
 	// cfIndexName is the human-readable name for the index.
 	cfIndexName = "committed filter index"
+
+	// cfIndexVersion is the current version of the committed filter index.
+	// The second version added the claim filters.
+	cfIndexVersion = 2
 )
 
-// Committed filters come in one flavor currently: basic. They are generated
-// and dropped in pairs, and both are indexed by a block's hash.  Besides
+// Committed filters come in two flavors: basic and claim.  They are generated
+// and dropped together, and all of them are indexed by a block's hash.  Besides
 // holding different content, they also live in different buckets.
 var (
 	// cfIndexParentBucketKey is the name of the parent bucket used to
@@ -34,18 +41,21 @@ var (
 	// block hashes to cfilters.
 	cfIndexKeys = [][]byte{
 		[]byte("cf0byhashidx"),
+		[]byte("cf1byhashidx"),
 	}
 
 	// cfHeaderKeys is an array of db bucket names used to house indexes of
 	// block hashes to cf headers.
 	cfHeaderKeys = [][]byte{
 		[]byte("cf0headerbyhashidx"),
+		[]byte("cf1headerbyhashidx"),
 	}
 
 	// cfHashKeys is an array of db bucket names used to house indexes of
 	// block hashes to cf hashes.
 	cfHashKeys = [][]byte{
 		[]byte("cf0hashbyhashidx"),
+		[]byte("cf1hashbyhashidx"),
 	}
 
 	maxFilterType = uint8(len(cfHeaderKeys) - 1)
@@ -94,6 +104,17 @@ func (idx *CfIndex) NeedsInputs() bool {
 	return true
 }
 
+// Ensure the CfIndex type implements the Versioner interface.
+var _ Versioner = (*CfIndex)(nil)
+
+// Version returns the current version of the cf index.  A cf index built with
+// an older version is dropped and rebuilt by the index manager.
+//
+// This implements the Versioner interface.
+func (idx *CfIndex) Version() uint32 {
+	return cfIndexVersion
+}
+
 // Init initializes the hash-based cf index. This is part of the Indexer
 // interface.
 func (idx *CfIndex) Init() error {
@@ -113,8 +134,8 @@ func (idx *CfIndex) Name() string {
 }
 
 // Create is invoked when the indexer manager determines the index needs to
-// be created for the first time. It creates buckets for the two hash-based cf
-// indexes (regular only currently).
+// be created for the first time. It creates buckets for the hash-based cf
+// indexes of each filter type.
 func (idx *CfIndex) Create(dbTx database.Tx) error {
 	meta := dbTx.Metadata()
 
@@ -222,7 +243,70 @@ func (idx *CfIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
 		return err
 	}
 
-	return storeFilter(dbTx, block, f, wire.GCSFilterRegular)
+	err = storeFilter(dbTx, block, f, wire.GCSFilterRegular)
+	if err != nil {
+		return err
+	}
+
+	f, err = buildClaimFilter(block, prevScripts)
+	if err != nil {
+		return err
+	}
+
+	return storeFilter(dbTx, block, f, wire.GCSFilterClaim)
+}
+
+// buildClaimFilter builds the claim filter of a block.  Its elements are the
+// names, normalized as of the block's height, and the claim IDs, as serialized
+// in the claim scripts, of the claims and supports the block adds, updates or
+// spends.  The claim ID of a new claim is the one derived from its outpoint.
+func buildClaimFilter(block *btcutil.Block, prevScripts [][]byte) (*gcs.Filter, error) {
+	blockHash := block.Hash()
+	b := builder.WithKeyHash(blockHash)
+
+	// If the filter had an issue with the specified key, then we force it
+	// to bubble up here by calling the Key() function.
+	_, err := b.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	addScript := func(pkScript []byte, op wire.OutPoint) {
+		cs, err := txscript.DecodeClaimScript(pkScript)
+		if err != nil {
+			return
+		}
+
+		var id change.ClaimID
+		if cs.Opcode() == txscript.OP_CLAIMNAME {
+			id = change.NewClaimID(op)
+		} else {
+			copy(id[:], cs.ClaimID())
+		}
+		name := normalization.NormalizeIfNecessary(cs.Name(), block.Height())
+		b.AddEntry(name)
+		b.AddEntry(id[:])
+	}
+
+	stxoIndex := 0
+	for txIdx, tx := range block.Transactions() {
+		// Coinbases do not reference any inputs, and the spent outputs
+		// are ordered as the inputs of the other transactions.
+		if txIdx != 0 {
+			for _, txIn := range tx.MsgTx().TxIn {
+				addScript(prevScripts[stxoIndex],
+					txIn.PreviousOutPoint)
+				stxoIndex++
+			}
+		}
+
+		for i, txOut := range tx.MsgTx().TxOut {
+			addScript(txOut.PkScript,
+				*wire.NewOutPoint(tx.Hash(), uint32(i)))
+		}
+	}
+
+	return b.Build()
 }
 
 // DisconnectBlock is invoked by the index manager when a block has been
@@ -0,0 +1,111 @@
+package indexers
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/param"
+	"github.com/lbryio/lbcd/txscript"
+	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
+	"github.com/lbryio/lbcutil/gcs/builder"
+)
+
+// TestClaimFilter ensures the claim filter of a block matches the names and
+// claim IDs of the claims and supports it adds, updates or spends, with the
+// names normalized as of the block's height.
+func TestClaimFilter(t *testing.T) {
+	t.Parallel()
+
+	mustScript := func(script []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("unable to build claim script: %v", err)
+		}
+		return script
+	}
+
+	// The update of a claim spends it in the same transaction, and a
+	// support and a new claim are made along with it.
+	updatedID := change.ClaimID{1, 2, 3}
+	supportedID := change.ClaimID{4, 5, 6}
+	spentScript := mustScript(txscript.UpdateClaimScript("Spent", updatedID[:], "old"))
+	prevScripts := [][]byte{spentScript}
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex}})
+	coinbase.AddTxOut(wire.NewTxOut(1, []byte{txscript.OP_TRUE}))
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{7}}})
+	tx.AddTxOut(wire.NewTxOut(1, mustScript(txscript.UpdateClaimScript("Spent", updatedID[:], "new"))))
+	tx.AddTxOut(wire.NewTxOut(1, mustScript(txscript.SupportClaimScript("Supported", supportedID[:], nil))))
+	tx.AddTxOut(wire.NewTxOut(1, mustScript(txscript.ClaimNameScript("Claimed", "value"))))
+	tx.AddTxOut(wire.NewTxOut(1, []byte{txscript.OP_TRUE}))
+
+	tests := []struct {
+		name      string
+		height    int32
+		matches   [][]byte
+		unmatched [][]byte
+	}{
+		{
+			name:   "before the normalization fork",
+			height: param.ActiveParams.NormalizedNameForkHeight - 1,
+			matches: [][]byte{[]byte("Spent"), []byte("Supported"),
+				[]byte("Claimed"), updatedID[:], supportedID[:]},
+			unmatched: [][]byte{[]byte("spent"), []byte("claimed"),
+				[]byte("value")},
+		},
+		{
+			name:   "after the normalization fork",
+			height: param.ActiveParams.NormalizedNameForkHeight,
+			matches: [][]byte{[]byte("spent"), []byte("supported"),
+				[]byte("claimed"), updatedID[:], supportedID[:]},
+			unmatched: [][]byte{[]byte("Spent"), []byte("Claimed"),
+				[]byte("value")},
+		},
+	}
+
+	for _, test := range tests {
+		msgBlock := &wire.MsgBlock{
+			Transactions: []*wire.MsgTx{coinbase, tx},
+		}
+		block := btcutil.NewBlock(msgBlock)
+		block.SetHeight(test.height)
+
+		// The claim ID of the new claim derives from its outpoint.
+		newID := change.NewClaimID(*wire.NewOutPoint(
+			block.Transactions()[1].Hash(), 2))
+		matches := append([][]byte{newID[:]}, test.matches...)
+
+		f, err := buildClaimFilter(block, prevScripts)
+		if err != nil {
+			t.Fatalf("%s: unable to build the claim filter: %v",
+				test.name, err)
+		}
+
+		// Three names and three claim IDs since the update and the
+		// spent claim share theirs.
+		if f.N() != 6 {
+			t.Errorf("%s: filter has %d elements, want 6", test.name,
+				f.N())
+		}
+
+		key := builder.DeriveKey(block.Hash())
+		for _, data := range matches {
+			match, err := f.Match(key, data)
+			if err != nil || !match {
+				t.Errorf("%s: filter doesn't match %q (err %v)",
+					test.name, data, err)
+			}
+		}
+		for _, data := range test.unmatched {
+			match, err := f.Match(key, data)
+			if err != nil || match {
+				t.Errorf("%s: filter matches %q (err %v)",
+					test.name, data, err)
+			}
+		}
+	}
+}
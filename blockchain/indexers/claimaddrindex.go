@@ -0,0 +1,356 @@
+package indexers
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/lbryio/lbcd/blockchain"
+	"github.com/lbryio/lbcd/chaincfg"
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/database"
+	"github.com/lbryio/lbcd/txscript"
+	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
+)
+
+const (
+	// claimAddrIndexName is the human-readable name for the index.
+	claimAddrIndexName = "claim address index"
+
+	// claimAddrKeySize is the size of a key in the claim address index.  It
+	// consists of an address key + 4 bytes block height + 4 bytes position
+	// of the transaction in the block.
+	claimAddrKeySize = addrKeySize + 4 + 4
+
+	// claimAddrValueSize is the size of a value in the claim address index.
+	// It consists of a 32 bytes block hash + 4 bytes offset + 4 bytes length
+	// + 1 byte claim tag.
+	claimAddrValueSize = chainhash.HashSize + 4 + 4 + 1
+)
+
+// ClaimTag identifies the kinds of claim scripts a transaction pays an address
+// with.  A transaction can pay an address with several of them, so the tags are
+// combined as bit flags.
+type ClaimTag uint8
+
+const (
+	// ClaimTagName marks a transaction that pays an address with an
+	// OP_CLAIMNAME output.
+	ClaimTagName ClaimTag = 1 << iota
+
+	// ClaimTagUpdate marks a transaction that pays an address with an
+	// OP_UPDATECLAIM output.
+	ClaimTagUpdate
+
+	// ClaimTagSupport marks a transaction that pays an address with an
+	// OP_SUPPORTCLAIM output.
+	ClaimTagSupport
+
+	// ClaimTagAny matches a transaction that pays an address with any
+	// claim script.
+	ClaimTagAny = ClaimTagName | ClaimTagUpdate | ClaimTagSupport
+)
+
+// claimTagForScript returns the claim tag of the passed public key script, or
+// zero when it isn't a claim script.
+func claimTagForScript(pkScript []byte) ClaimTag {
+	cs, err := txscript.DecodeClaimScript(pkScript)
+	if err != nil {
+		return 0
+	}
+
+	switch cs.Opcode() {
+	case txscript.OP_CLAIMNAME:
+		return ClaimTagName
+	case txscript.OP_UPDATECLAIM:
+		return ClaimTagUpdate
+	case txscript.OP_SUPPORTCLAIM:
+		return ClaimTagSupport
+	}
+	return 0
+}
+
+var (
+	// claimAddrIndexKey is the key of the claim address index and the db
+	// bucket used to house it.
+	claimAddrIndexKey = []byte("claimbyaddridx")
+)
+
+// -----------------------------------------------------------------------------
+// The claim address index maps the addresses paid with claim scripts to the
+// transactions that pay them, tagged with the kinds of claim scripts they pay
+// the address with.  It lists the claims, updates and supports controlled by
+// an address without loading every transaction that involves it.
+//
+// It is kept apart from the address index, which indexes the same outputs
+// under the same addresses, so the entries of an address index built before it
+// stay valid; a missing claim address index is built on its own.  Unlike the
+// address index, it doesn't rely on the block IDs of the transaction index.
+//
+// There is one entry per transaction and address, keyed by the height of the
+// block and the position of the transaction in it so that the entries of an
+// address come in the order they were made.
+//
+// The serialized key format is:
+//
+//   <addr type><addr hash><block height><tx index>
+//
+//   Field           Type      Size
+//   addr type       uint8     1 byte
+//   addr hash       hash160   20 bytes
+//   block height    uint32    4 bytes (big endian)
+//   tx index        uint32    4 bytes (big endian)
+//   -----
+//   Total: 29 bytes
+//
+// The serialized value format is:
+//
+//   <block hash><start offset><tx length><claim tag>
+//
+//   Field           Type              Size
+//   block hash      chainhash.Hash    32 bytes
+//   start offset    uint32            4 bytes
+//   tx length       uint32            4 bytes
+//   claim tag       uint8             1 byte
+//   -----
+//   Total: 41 bytes
+// -----------------------------------------------------------------------------
+
+// claimAddrEntryKey returns the key of the entry of an address for the
+// transaction at the passed position in the block at the passed height.
+func claimAddrEntryKey(addrKey [addrKeySize]byte, height int32, txIdx int) []byte {
+	key := make([]byte, claimAddrKeySize)
+	copy(key, addrKey[:])
+	binary.BigEndian.PutUint32(key[addrKeySize:], uint32(height))
+	binary.BigEndian.PutUint32(key[addrKeySize+4:], uint32(txIdx))
+	return key
+}
+
+// claimAddrEntries returns the entries of a block, mapped from their keys to
+// their values.
+func claimAddrEntries(block *btcutil.Block, params *chaincfg.Params) (map[string][]byte, error) {
+	entries := make(map[string][]byte)
+	var txLocs []wire.TxLoc
+	for txIdx, tx := range block.Transactions() {
+		for _, txOut := range tx.MsgTx().TxOut {
+			tag := claimTagForScript(txOut.PkScript)
+			if tag == 0 {
+				continue
+			}
+
+			// The claim prefix is stripped when the addresses are
+			// extracted.
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				txOut.PkScript, params)
+			if err != nil {
+				continue
+			}
+
+			for _, addr := range addrs {
+				addrKey, err := addrToKey(addr)
+				if err != nil {
+					continue
+				}
+
+				key := string(claimAddrEntryKey(addrKey, block.Height(), txIdx))
+				if value, ok := entries[key]; ok {
+					value[claimAddrValueSize-1] |= byte(tag)
+					continue
+				}
+
+				if txLocs == nil {
+					locs, err := block.TxLoc()
+					if err != nil {
+						return nil, err
+					}
+					txLocs = locs
+				}
+				value := make([]byte, claimAddrValueSize)
+				copy(value, block.Hash()[:])
+				offset := chainhash.HashSize
+				byteOrder.PutUint32(value[offset:], uint32(txLocs[txIdx].TxStart))
+				byteOrder.PutUint32(value[offset+4:], uint32(txLocs[txIdx].TxLen))
+				value[claimAddrValueSize-1] = byte(tag)
+				entries[key] = value
+			}
+		}
+	}
+	return entries, nil
+}
+
+// ClaimAddrIndex implements an index of the transactions that pay addresses with
+// claim scripts.  It is enabled along with the address index.
+type ClaimAddrIndex struct {
+	db          database.DB
+	chainParams *chaincfg.Params
+}
+
+// Ensure the ClaimAddrIndex type implements the Indexer interface.
+var _ Indexer = (*ClaimAddrIndex)(nil)
+
+// Init is only provided to satisfy the Indexer interface as there is nothing to
+// initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimAddrIndex) Init() error {
+	// Nothing to do.
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimAddrIndex) Key() []byte {
+	return claimAddrIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimAddrIndex) Name() string {
+	return claimAddrIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs
+// to be created for the first time.  It creates the bucket for the claim
+// address index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimAddrIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(claimAddrIndexKey)
+	return err
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds an entry for each address
+// paid with a claim script by each transaction in the block.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimAddrIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	entries, err := claimAddrEntries(block, idx.chainParams)
+	if err != nil {
+		return err
+	}
+
+	bucket := dbTx.Metadata().Bucket(claimAddrIndexKey)
+	for key, value := range entries {
+		if err := bucket.Put([]byte(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the entries of the
+// block.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimAddrIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	entries, err := claimAddrEntries(block, idx.chainParams)
+	if err != nil {
+		return err
+	}
+
+	bucket := dbTx.Metadata().Bucket(claimAddrIndexKey)
+	for key := range entries {
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TxRegionsForAddress returns a slice of block regions which identify each
+// transaction that pays the passed address with one of the claim scripts in
+// the tag mask, along with the claim tags of each transaction.  The number to
+// skip, number requested and reverse flag are applied to the matching
+// transactions only, as is the returned number actually skipped.
+//
+// NOTE: These results only include transactions confirmed in blocks.
+//
+// This function is safe for concurrent access.
+func (idx *ClaimAddrIndex) TxRegionsForAddress(dbTx database.Tx, addr btcutil.Address,
+	tagMask ClaimTag, numToSkip, numRequested uint32,
+	reverse bool) ([]database.BlockRegion, []ClaimTag, uint32, error) {
+
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var regions []database.BlockRegion
+	var tags []ClaimTag
+	var skipped uint32
+	err = idx.db.View(func(dbTx database.Tx) error {
+		cursor := dbTx.Metadata().Bucket(claimAddrIndexKey).Cursor()
+
+		// Position the cursor on the first entry of the address in the
+		// requested order.
+		var ok bool
+		if !reverse {
+			ok = cursor.Seek(addrKey[:])
+		} else {
+			end := claimAddrEntryKey(addrKey, -1, -1)
+			if cursor.Seek(end) {
+				ok = cursor.Prev()
+			} else {
+				ok = cursor.Last()
+			}
+		}
+
+		for ; ok && bytes.HasPrefix(cursor.Key(), addrKey[:]); ok = stepCursor(cursor, reverse) {
+			if uint32(len(regions)) >= numRequested {
+				break
+			}
+			value := cursor.Value()
+			tag := ClaimTag(value[claimAddrValueSize-1])
+			if tag&tagMask == 0 {
+				continue
+			}
+			if skipped < numToSkip {
+				skipped++
+				continue
+			}
+
+			var hash chainhash.Hash
+			copy(hash[:], value)
+			offset := chainhash.HashSize
+			regions = append(regions, database.BlockRegion{
+				Hash:   &hash,
+				Offset: byteOrder.Uint32(value[offset:]),
+				Len:    byteOrder.Uint32(value[offset+4:]),
+			})
+			tags = append(tags, tag)
+		}
+		return nil
+	})
+
+	return regions, tags, skipped, err
+}
+
+// stepCursor moves the cursor to the next entry in the requested order.
+func stepCursor(cursor database.Cursor, reverse bool) bool {
+	if reverse {
+		return cursor.Prev()
+	}
+	return cursor.Next()
+}
+
+// NewClaimAddrIndex returns a new instance of an indexer that is used to create
+// a mapping of the addresses paid with claim scripts to the transactions that
+// pay them.
+//
+// It implements the Indexer interface which plugs into the IndexManager that in
+// turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewClaimAddrIndex(db database.DB, chainParams *chaincfg.Params) *ClaimAddrIndex {
+	return &ClaimAddrIndex{
+		db:          db,
+		chainParams: chainParams,
+	}
+}
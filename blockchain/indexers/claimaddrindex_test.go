@@ -0,0 +1,203 @@
+package indexers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lbryio/lbcd/chaincfg"
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/database"
+	_ "github.com/lbryio/lbcd/database/ffldb"
+	"github.com/lbryio/lbcd/txscript"
+	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
+)
+
+// TestClaimAddrIndex ensures the claim address index lists the transactions
+// that pay an address with claim scripts, tagged with the kinds of scripts they
+// pay it with, in block order, and that disconnecting a block removes them.
+func TestClaimAddrIndex(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", t.TempDir(), params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	other, err := btcutil.NewAddressPubKeyHash(append(make([]byte, 19), 1), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+
+	// claimScript pays the address with the claim prefix built by the
+	// passed builder.
+	claimScript := func(prefix *txscript.ScriptBuilder, addr btcutil.Address) []byte {
+		claim, err := prefix.AddOp(txscript.OP_2DROP).AddOp(txscript.OP_DROP).Script()
+		if err != nil {
+			t.Fatalf("unable to build claim script: %v", err)
+		}
+		payment, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("unable to build payment script: %v", err)
+		}
+		return append(claim, payment...)
+	}
+	claimName := func(addr btcutil.Address) []byte {
+		return claimScript(txscript.NewScriptBuilder().AddOp(txscript.OP_CLAIMNAME).
+			AddData([]byte("name")).AddData([]byte("value")), addr)
+	}
+	support := func(addr btcutil.Address) []byte {
+		return claimScript(txscript.NewScriptBuilder().AddOp(txscript.OP_SUPPORTCLAIM).
+			AddData([]byte("name")).AddData(make([]byte, 20)), addr)
+	}
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex}})
+	coinbase.AddTxOut(wire.NewTxOut(1, []byte{txscript.OP_TRUE}))
+
+	// The first transaction claims a name and supports it, the second one
+	// only pays the address, and the third one supports a claim for another
+	// address.
+	claimTx := wire.NewMsgTx(1)
+	claimTx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{1}}})
+	claimTx.AddTxOut(wire.NewTxOut(1, claimName(addr)))
+	claimTx.AddTxOut(wire.NewTxOut(1, support(addr)))
+
+	payTx := wire.NewMsgTx(1)
+	payTx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{2}}})
+	payment, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to build payment script: %v", err)
+	}
+	payTx.AddTxOut(wire.NewTxOut(1, payment))
+
+	otherTx := wire.NewMsgTx(1)
+	otherTx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{3}}})
+	otherTx.AddTxOut(wire.NewTxOut(1, support(other)))
+
+	first := btcutil.NewBlock(&wire.MsgBlock{
+		Transactions: []*wire.MsgTx{coinbase, claimTx, payTx, otherTx},
+	})
+	first.SetHeight(1)
+
+	// The second block supports the claim again.
+	supportTx := wire.NewMsgTx(1)
+	supportTx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{4}}})
+	supportTx.AddTxOut(wire.NewTxOut(1, support(addr)))
+	second := btcutil.NewBlock(&wire.MsgBlock{
+		Header:       wire.BlockHeader{Nonce: 1},
+		Transactions: []*wire.MsgTx{coinbase, supportTx},
+	})
+	second.SetHeight(2)
+
+	idx := NewClaimAddrIndex(db, params)
+	err = db.Update(func(dbTx database.Tx) error {
+		if err := idx.Create(dbTx); err != nil {
+			return err
+		}
+		if err := idx.ConnectBlock(dbTx, first, nil); err != nil {
+			return err
+		}
+		return idx.ConnectBlock(dbTx, second, nil)
+	})
+	if err != nil {
+		t.Fatalf("unable to connect the blocks: %v", err)
+	}
+
+	type entry struct {
+		hash *chainhash.Hash
+		tx   *wire.MsgTx
+		tag  ClaimTag
+	}
+	claimEntry := entry{first.Hash(), claimTx, ClaimTagName | ClaimTagSupport}
+	supportEntry := entry{second.Hash(), supportTx, ClaimTagSupport}
+
+	tests := []struct {
+		name      string
+		tagMask   ClaimTag
+		numToSkip uint32
+		reverse   bool
+		expected  []entry
+	}{
+		{name: "any claim", tagMask: ClaimTagAny,
+			expected: []entry{claimEntry, supportEntry}},
+		{name: "any claim reversed", tagMask: ClaimTagAny, reverse: true,
+			expected: []entry{supportEntry, claimEntry}},
+		{name: "any claim skipped", tagMask: ClaimTagAny, numToSkip: 1,
+			expected: []entry{supportEntry}},
+		{name: "claims", tagMask: ClaimTagName,
+			expected: []entry{claimEntry}},
+		{name: "claims reversed", tagMask: ClaimTagName, reverse: true,
+			expected: []entry{claimEntry}},
+		{name: "updates", tagMask: ClaimTagUpdate},
+	}
+
+	for _, test := range tests {
+		regions, tags, _, err := idx.TxRegionsForAddress(nil, addr,
+			test.tagMask, test.numToSkip, 10, test.reverse)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if len(regions) != len(test.expected) {
+			t.Errorf("%s: got %d regions, want %d", test.name,
+				len(regions), len(test.expected))
+			continue
+		}
+		for i, want := range test.expected {
+			if *regions[i].Hash != *want.hash || tags[i] != want.tag {
+				t.Errorf("%s: entry %d is in block %v tagged %d, want "+
+					"%v tagged %d", test.name, i, regions[i].Hash,
+					tags[i], want.hash, want.tag)
+				continue
+			}
+
+			// The region locates the transaction in its block.
+			block := first
+			if *want.hash == *second.Hash() {
+				block = second
+			}
+			serialized, err := block.Bytes()
+			if err != nil {
+				t.Fatalf("unable to serialize the block: %v", err)
+			}
+			region := regions[i]
+			var tx wire.MsgTx
+			err = tx.Deserialize(bytes.NewReader(
+				serialized[region.Offset : region.Offset+region.Len]))
+			if err != nil || tx.TxHash() != want.tx.TxHash() {
+				t.Errorf("%s: entry %d is transaction %v, want %v "+
+					"(err %v)", test.name, i, tx.TxHash(),
+					want.tx.TxHash(), err)
+			}
+		}
+	}
+
+	// The other address only has its own support.
+	regions, _, _, err := idx.TxRegionsForAddress(nil, other, ClaimTagAny,
+		0, 10, false)
+	if err != nil || len(regions) != 1 || *regions[0].Hash != *first.Hash() {
+		t.Fatalf("got %d regions for the other address, want 1 (err %v)",
+			len(regions), err)
+	}
+
+	// Disconnecting the second block leaves the entries of the first one.
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.DisconnectBlock(dbTx, second, nil)
+	})
+	if err != nil {
+		t.Fatalf("unable to disconnect the block: %v", err)
+	}
+	regions, _, _, err = idx.TxRegionsForAddress(nil, addr, ClaimTagAny, 0,
+		10, true)
+	if err != nil || len(regions) != 1 || *regions[0].Hash != *first.Hash() {
+		t.Fatalf("got %d regions after the disconnect, want 1 (err %v)",
+			len(regions), err)
+	}
+}
@@ -32,6 +32,13 @@ type NeedsInputser interface {
 	NeedsInputs() bool
 }
 
+// Versioner provides a generic interface for an indexer to specify the version
+// of its serialized format.  The index manager drops and rebuilds an index that
+// was built with an older version.
+type Versioner interface {
+	Version() uint32
+}
+
 // Indexer provides a generic interface for an indexer that is managed by an
 // index manager such as the Manager type provided by this package.
 type Indexer interface {
@@ -64,6 +64,37 @@ func dbFetchIndexerTip(dbTx database.Tx, idxKey []byte) (*chainhash.Hash, int32,
 	return &hash, height, nil
 }
 
+// indexVersionKey returns the key for an index which holds the version of its
+// serialized format.
+func indexVersionKey(idxKey []byte) []byte {
+	versionKey := make([]byte, len(idxKey)+1)
+	versionKey[0] = 'v'
+	copy(versionKey[1:], idxKey)
+	return versionKey
+}
+
+// dbPutIndexerVersion uses an existing database transaction to update or add
+// the version for the given index.
+func dbPutIndexerVersion(dbTx database.Tx, idxKey []byte, version uint32) error {
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], version)
+
+	indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+	return indexesBucket.Put(indexVersionKey(idxKey), serialized[:])
+}
+
+// dbFetchIndexerVersion uses an existing database transaction to retrieve the
+// version of the provided index.  Indexes created before their version was
+// tracked are at version 1.
+func dbFetchIndexerVersion(dbTx database.Tx, idxKey []byte) uint32 {
+	indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+	serialized := indexesBucket.Get(indexVersionKey(idxKey))
+	if len(serialized) < 4 {
+		return 1
+	}
+	return byteOrder.Uint32(serialized)
+}
+
 // dbIndexConnectBlock adds all of the index entries associated with the
 // given block using the provided indexer and updates the tip of the indexer
 // accordingly.  An error will be returned if the current tip for the indexer is
@@ -196,6 +227,51 @@ func (m *Manager) maybeFinishDrops(interrupt <-chan struct{}) error {
 	return nil
 }
 
+// maybeUpgradeIndexes determines if each of the enabled indexes was built with
+// an older version of its serialized format and drops them when they were, so
+// they are created and caught up again with the current one.
+func (m *Manager) maybeUpgradeIndexes(interrupt <-chan struct{}) error {
+	indexNeedsUpgrade := make([]bool, len(m.enabledIndexes))
+	err := m.db.View(func(dbTx database.Tx) error {
+		// None of the indexes needs to be upgraded if the index tips
+		// bucket hasn't been created yet.
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket == nil {
+			return nil
+		}
+
+		for i, indexer := range m.enabledIndexes {
+			versioner, ok := indexer.(Versioner)
+			if !ok || indexesBucket.Get(indexer.Key()) == nil {
+				continue
+			}
+			version := dbFetchIndexerVersion(dbTx, indexer.Key())
+			indexNeedsUpgrade[i] = version < versioner.Version()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, indexer := range m.enabledIndexes {
+		if !indexNeedsUpgrade[i] {
+			continue
+		}
+
+		log.Infof("Upgrading %s to version %d.  It has to be rebuilt "+
+			"from scratch", indexer.Name(),
+			indexer.(Versioner).Version())
+		err := dropIndex(m.db, indexer.Key(), indexer.Name(), interrupt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // maybeCreateIndexes determines if each of the enabled indexes have already
 // been created and creates them if not.
 func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
@@ -220,6 +296,15 @@ func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
 		if err != nil {
 			return err
 		}
+
+		// Record the version the index is built with.
+		if versioner, ok := indexer.(Versioner); ok {
+			err := dbPutIndexerVersion(dbTx, idxKey,
+				versioner.Version())
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -248,6 +333,11 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 		return err
 	}
 
+	// Drop the indexes built with an older version so they are rebuilt.
+	if err := m.maybeUpgradeIndexes(interrupt); err != nil {
+		return err
+	}
+
 	// Create the initial state for the indexes as needed.
 	err := m.db.Update(func(dbTx database.Tx) error {
 		// Create the bucket for the current tips as needed.
@@ -677,14 +767,18 @@ func dropIndex(db database.DB, idxKey []byte, idxName string, interrupt <-chan s
 		}
 	}
 
-	// Remove the index tip, index bucket, and in-progress drop flag now
-	// that all index entries have been removed.
+	// Remove the index tip, version, index bucket, and in-progress drop
+	// flag now that all index entries have been removed.
 	err = db.Update(func(dbTx database.Tx) error {
 		meta := dbTx.Metadata()
 		indexesBucket := meta.Bucket(indexTipsBucketName)
 		if err := indexesBucket.Delete(idxKey); err != nil {
 			return err
 		}
+		err := indexesBucket.Delete(indexVersionKey(idxKey))
+		if err != nil {
+			return err
+		}
 
 		return indexesBucket.Delete(indexDropKey(idxKey))
 	})
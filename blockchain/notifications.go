@@ -29,6 +29,11 @@ const (
 	// NTBlockDisconnected indicates the associated block was disconnected
 	// from the main chain.
 	NTBlockDisconnected
+
+	// NTClaimEvents indicates that claims, supports or names changed in a
+	// block that was connected to or disconnected from the main chain.  It
+	// is only sent while somebody watches for it; see WatchClaimEvents.
+	NTClaimEvents
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
@@ -37,6 +42,7 @@ var notificationTypeStrings = map[NotificationType]string{
 	NTBlockAccepted:     "NTBlockAccepted",
 	NTBlockConnected:    "NTBlockConnected",
 	NTBlockDisconnected: "NTBlockDisconnected",
+	NTClaimEvents:       "NTClaimEvents",
 }
 
 // String returns the NotificationType in human-readable form.
@@ -53,6 +59,7 @@ func (n NotificationType) String() string {
 // 	- NTBlockAccepted:     *btcutil.Block
 // 	- NTBlockConnected:    *btcutil.Block
 // 	- NTBlockDisconnected: *btcutil.Block
+// 	- NTClaimEvents:       *ClaimEvents
 type Notification struct {
 	Type NotificationType
 	Data interface{}
@@ -592,6 +592,44 @@ func (view *UtxoViewpoint) fetchInputUtxos(db database.DB, block *btcutil.Block)
 	return view.fetchUtxosMain(db, neededSet)
 }
 
+// addSpentTxOuts adds the outputs spent by the transactions in the given block,
+// as recorded in its spend journal entry, to the view as spent entries.  This
+// allows the inputs of a main chain block to be looked up without connecting
+// all of the blocks before it.
+func (view *UtxoViewpoint) addSpentTxOuts(block *btcutil.Block, stxos []SpentTxOut) error {
+	// The spend journal holds an entry for each input of each transaction
+	// in the block, except for the coinbase, in order.
+	stxoIdx := 0
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			if stxoIdx >= len(stxos) {
+				return AssertError(fmt.Sprintf("spend journal of "+
+					"block %v is missing inputs", block.Hash()))
+			}
+			stxo := &stxos[stxoIdx]
+			stxoIdx++
+
+			entry := &UtxoEntry{
+				amount:      stxo.Amount,
+				pkScript:    stxo.PkScript,
+				blockHeight: stxo.Height,
+				packedFlags: tfSpent,
+			}
+			if stxo.IsCoinBase {
+				entry.packedFlags |= tfCoinBase
+			}
+			view.entries[txIn.PreviousOutPoint] = entry
+		}
+	}
+	if stxoIdx != len(stxos) {
+		return AssertError(fmt.Sprintf("spend journal of block %v has "+
+			"%d entries for %d inputs", block.Hash(), len(stxos),
+			stxoIdx))
+	}
+
+	return nil
+}
+
 // NewUtxoViewpoint returns a new empty unspent transaction output view.
 func NewUtxoViewpoint() *UtxoViewpoint {
 	return &UtxoViewpoint{
@@ -177,6 +177,44 @@ func NewStopNotifySpentCmd(outPoints []OutPoint) *StopNotifySpentCmd {
 	}
 }
 
+// NotifyClaimsCmd defines the notifyclaims JSON-RPC command.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+type NotifyClaimsCmd struct {
+	Names    []string
+	ClaimIDs []string
+}
+
+// NewNotifyClaimsCmd returns a new instance which can be used to issue a
+// notifyclaims JSON-RPC command.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+func NewNotifyClaimsCmd(names []string, claimIDs []string) *NotifyClaimsCmd {
+	return &NotifyClaimsCmd{
+		Names:    names,
+		ClaimIDs: claimIDs,
+	}
+}
+
+// StopNotifyClaimsCmd defines the stopnotifyclaims JSON-RPC command.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+type StopNotifyClaimsCmd struct {
+	Names    []string
+	ClaimIDs []string
+}
+
+// NewStopNotifyClaimsCmd returns a new instance which can be used to issue a
+// stopnotifyclaims JSON-RPC command.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+func NewStopNotifyClaimsCmd(names []string, claimIDs []string) *StopNotifyClaimsCmd {
+	return &StopNotifyClaimsCmd{
+		Names:    names,
+		ClaimIDs: claimIDs,
+	}
+}
+
 // RescanCmd defines the rescan JSON-RPC command.
 //
 // Deprecated: Use RescanBlocksCmd instead.
@@ -228,11 +266,13 @@ func init() {
 	MustRegisterCmd("authenticate", (*AuthenticateCmd)(nil), flags)
 	MustRegisterCmd("loadtxfilter", (*LoadTxFilterCmd)(nil), flags)
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("notifyclaims", (*NotifyClaimsCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("stopnotifyclaims", (*StopNotifyClaimsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyspent", (*StopNotifySpentCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyreceived", (*StopNotifyReceivedCmd)(nil), flags)
@@ -63,6 +63,34 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyblocks","params":[],"id":1}`,
 			unmarshalled: &btcjson.StopNotifyBlocksCmd{},
 		},
+		{
+			name: "notifyclaims",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifyclaims", []string{"test"}, []string{"0123"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyClaimsCmd([]string{"test"}, []string{"0123"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"notifyclaims","params":[["test"],["0123"]],"id":1}`,
+			unmarshalled: &btcjson.NotifyClaimsCmd{
+				Names:    []string{"test"},
+				ClaimIDs: []string{"0123"},
+			},
+		},
+		{
+			name: "stopnotifyclaims",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("stopnotifyclaims", []string{"test"}, []string{})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStopNotifyClaimsCmd([]string{"test"}, []string{})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"stopnotifyclaims","params":[["test"],[]],"id":1}`,
+			unmarshalled: &btcjson.StopNotifyClaimsCmd{
+				Names:    []string{"test"},
+				ClaimIDs: []string{},
+			},
+		},
 		{
 			name: "notifynewtransactions",
 			newCmd: func() (interface{}, error) {
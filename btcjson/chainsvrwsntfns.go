@@ -75,6 +75,13 @@ const (
 	// from the chain server that inform a client that a transaction that
 	// matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// ClaimEventsNtfnMethod is the method used for notifications from the
+	// chain server that claims, supports or names a client subscribed to
+	// with notifyclaims changed in a connected or disconnected block.
+	//
+	// NOTE: This is an lbcd extension.
+	ClaimEventsNtfnMethod = "claimevents"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -285,6 +292,42 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// ClaimEvent describes something that happened to a claim, a support or a
+// name.  The type is one of addclaim, updateclaim, spendclaim, activateclaim,
+// expireclaim, addsupport, spendsupport, activatesupport, expiresupport or
+// takeover.  Supports carry the ID of the claim they support, and takeovers
+// carry the effective amount of the new controlling claim.
+type ClaimEvent struct {
+	Type            string `json:"type"`
+	Name            string `json:"name"`
+	ClaimID         string `json:"claimid,omitempty"`
+	TXID            string `json:"txid,omitempty"`
+	N               uint32 `json:"n"`
+	Amount          int64  `json:"amount"`
+	PreviousClaimID string `json:"previousclaimid,omitempty"`
+}
+
+// ClaimEventsNtfn defines the claimevents JSON-RPC notification.
+//
+// NOTE: This is an lbcd extension.
+type ClaimEventsNtfn struct {
+	Hash         string
+	Height       int32
+	Disconnected bool
+	Events       []ClaimEvent
+}
+
+// NewClaimEventsNtfn returns a new instance which can be used to issue a
+// claimevents JSON-RPC notification.
+func NewClaimEventsNtfn(hash string, height int32, disconnected bool, events []ClaimEvent) *ClaimEventsNtfn {
+	return &ClaimEventsNtfn{
+		Hash:         hash,
+		Height:       height,
+		Disconnected: disconnected,
+		Events:       events,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -292,6 +335,7 @@ func init() {
 
 	MustRegisterCmd(BlockConnectedNtfnMethod, (*BlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(BlockDisconnectedNtfnMethod, (*BlockDisconnectedNtfn)(nil), flags)
+	MustRegisterCmd(ClaimEventsNtfnMethod, (*ClaimEventsNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockConnectedNtfnMethod, (*FilteredBlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockDisconnectedNtfnMethod, (*FilteredBlockDisconnectedNtfn)(nil), flags)
 	MustRegisterCmd(RecvTxNtfnMethod, (*RecvTxNtfn)(nil), flags)
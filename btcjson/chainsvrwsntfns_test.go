@@ -59,6 +59,23 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Time:   123456789,
 			},
 		},
+		{
+			name: "claimevents",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("claimevents", "123", 100000, false, `[{"type":"addclaim","name":"test","claimid":"0123","txid":"456","n":1,"amount":10}]`)
+			},
+			staticNtfn: func() interface{} {
+				events := []btcjson.ClaimEvent{{Type: "addclaim", Name: "test", ClaimID: "0123", TXID: "456", N: 1, Amount: 10}}
+				return btcjson.NewClaimEventsNtfn("123", 100000, false, events)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"claimevents","params":["123",100000,false,[{"type":"addclaim","name":"test","claimid":"0123","txid":"456","n":1,"amount":10}]],"id":null}`,
+			unmarshalled: &btcjson.ClaimEventsNtfn{
+				Hash:         "123",
+				Height:       100000,
+				Disconnected: false,
+				Events:       []btcjson.ClaimEvent{{Type: "addclaim", Name: "test", ClaimID: "0123", TXID: "456", N: 1, Amount: 10}},
+			},
+		},
 		{
 			name: "filteredblockconnected",
 			newNtfn: func() (interface{}, error) {
@@ -10,6 +10,22 @@ func init() {
 	MustRegisterCmd("getclaimsfornamebybid", (*GetClaimsForNameByBidCmd)(nil), flags)
 	MustRegisterCmd("getclaimsfornamebyseq", (*GetClaimsForNameBySeqCmd)(nil), flags)
 	MustRegisterCmd("normalize", (*GetNormalizedCmd)(nil), flags)
+	MustRegisterCmd("getnameproof", (*GetNameProofCmd)(nil), flags)
+	MustRegisterCmd("getclaimproof", (*GetClaimProofCmd)(nil), flags)
+	MustRegisterCmd("getclaimbyid", (*GetClaimByIDCmd)(nil), flags)
+	MustRegisterCmd("getclaimhistory", (*GetClaimHistoryCmd)(nil), flags)
+	MustRegisterCmd("gettakeoverhistory", (*GetTakeoverHistoryCmd)(nil), flags)
+	MustRegisterCmd("listnames", (*ListNamesCmd)(nil), flags)
+	MustRegisterCmd("getclaimtrieinfo", (*GetClaimTrieInfoCmd)(nil), flags)
+	MustRegisterCmd("verifyclaimtrie", (*VerifyClaimTrieCmd)(nil), flags)
+	MustRegisterCmd("getexpiringclaims", (*GetExpiringClaimsCmd)(nil), flags)
+	MustRegisterCmd("simulatebid", (*SimulateBidCmd)(nil), flags)
+	MustRegisterCmd("getclaimtriechanges", (*GetClaimTrieChangesCmd)(nil), flags)
+	MustRegisterCmd("resolve", (*ResolveCmd)(nil), flags)
+	MustRegisterCmd("getclaimsforaddress", (*GetClaimsForAddressCmd)(nil), flags)
+	MustRegisterCmd("decodeclaimvalue", (*DecodeClaimValueCmd)(nil), flags)
+	MustRegisterCmd("getclaimsinchannel", (*GetClaimsInChannelCmd)(nil), flags)
+	MustRegisterCmd("gettopclaims", (*GetTopClaimsCmd)(nil), flags)
 }
 
 // optional inputs are required to be pointers, but they support things like `jsonrpcdefault:"false"`
@@ -28,9 +44,10 @@ type GetChangesInBlockResult struct {
 }
 
 type GetClaimsForNameCmd struct {
-	Name          string  `json:"name"`
-	HashOrHeight  *string `json:"hashorheight" jsonrpcdefault:""`
-	IncludeValues *bool   `json:"includevalues" jsonrpcdefault:"false"`
+	Name           string  `json:"name"`
+	HashOrHeight   *string `json:"hashorheight" jsonrpcdefault:""`
+	IncludeValues  *bool   `json:"includevalues" jsonrpcdefault:"false"`
+	IncludeMempool *bool   `json:"includemempool" jsonrpcdefault:"false"`
 }
 
 type GetClaimsForNameByIDCmd struct {
@@ -38,20 +55,23 @@ type GetClaimsForNameByIDCmd struct {
 	PartialClaimIDs []string `json:"partialclaimids"`
 	HashOrHeight    *string  `json:"hashorheight" jsonrpcdefault:""`
 	IncludeValues   *bool    `json:"includevalues" jsonrpcdefault:"false"`
+	IncludeMempool  *bool    `json:"includemempool" jsonrpcdefault:"false"`
 }
 
 type GetClaimsForNameByBidCmd struct {
-	Name          string  `json:"name"`
-	Bids          []int32 `json:"bids"`
-	HashOrHeight  *string `json:"hashorheight" jsonrpcdefault:""`
-	IncludeValues *bool   `json:"includevalues" jsonrpcdefault:"false"`
+	Name           string  `json:"name"`
+	Bids           []int32 `json:"bids"`
+	HashOrHeight   *string `json:"hashorheight" jsonrpcdefault:""`
+	IncludeValues  *bool   `json:"includevalues" jsonrpcdefault:"false"`
+	IncludeMempool *bool   `json:"includemempool" jsonrpcdefault:"false"`
 }
 
 type GetClaimsForNameBySeqCmd struct {
-	Name          string  `json:"name"`
-	Sequences     []int32 `json:"sequences" jsonrpcusage:"[sequence,...]"`
-	HashOrHeight  *string `json:"hashorheight" jsonrpcdefault:""`
-	IncludeValues *bool   `json:"includevalues" jsonrpcdefault:"false"`
+	Name           string  `json:"name"`
+	Sequences      []int32 `json:"sequences" jsonrpcusage:"[sequence,...]"`
+	HashOrHeight   *string `json:"hashorheight" jsonrpcdefault:""`
+	IncludeValues  *bool   `json:"includevalues" jsonrpcdefault:"false"`
+	IncludeMempool *bool   `json:"includemempool" jsonrpcdefault:"false"`
 }
 
 type GetClaimsForNameResult struct {
@@ -70,20 +90,23 @@ type SupportResult struct {
 	Amount        int64  `json:"amount"`
 	Address       string `json:"address,omitempty"`
 	Value         string `json:"value,omitempty"`
+	Pending       bool   `json:"pending,omitempty"`
 }
 
 type ClaimResult struct {
-	ClaimID         string          `json:"claimid"`
-	TXID            string          `json:"txid"`
-	N               uint32          `json:"n"`
-	Bid             int32           `json:"bid"`
-	Sequence        int32           `json:"sequence"`
-	Height          int32           `json:"height"`
-	ValidAtHeight   int32           `json:"validatheight"`
-	EffectiveAmount int64           `json:"effectiveamount"`
-	Supports        []SupportResult `json:"supports,omitempty"`
-	Address         string          `json:"address,omitempty"`
-	Value           string          `json:"value,omitempty"`
+	ClaimID         string              `json:"claimid"`
+	TXID            string              `json:"txid"`
+	N               uint32              `json:"n"`
+	Bid             int32               `json:"bid"`
+	Sequence        int32               `json:"sequence"`
+	Height          int32               `json:"height"`
+	ValidAtHeight   int32               `json:"validatheight"`
+	EffectiveAmount int64               `json:"effectiveamount"`
+	Supports        []SupportResult     `json:"supports,omitempty"`
+	Address         string              `json:"address,omitempty"`
+	Value           string              `json:"value,omitempty"`
+	Decoded         *DecodedClaimResult `json:"decoded,omitempty"`
+	Pending         bool                `json:"pending,omitempty"`
 }
 
 type GetNormalizedCmd struct {
@@ -93,3 +116,347 @@ type GetNormalizedCmd struct {
 type GetNormalizedResult struct {
 	NormalizedName string `json:"normalizedname"`
 }
+
+type GetNameProofCmd struct {
+	Name         string  `json:"name"`
+	HashOrHeight *string `json:"hashorheight" jsonrpcdefault:""`
+}
+
+type GetClaimProofCmd struct {
+	Name           string  `json:"name"`
+	PartialClaimID string  `json:"partialclaimid"`
+	HashOrHeight   *string `json:"hashorheight" jsonrpcdefault:""`
+}
+
+type ProofChildResult struct {
+	Character int    `json:"character"`
+	NodeHash  string `json:"nodehash"`
+}
+
+type ProofNodeResult struct {
+	Children  []ProofChildResult `json:"children,omitempty"`
+	ValueHash string             `json:"valuehash,omitempty"`
+}
+
+type ProofPairResult struct {
+	Right bool   `json:"right"`
+	Hash  string `json:"hash"`
+}
+
+type ProofResult struct {
+	Hash               string            `json:"hash"`
+	Height             int32             `json:"height"`
+	NormalizedName     string            `json:"normalizedname"`
+	TXID               string            `json:"txid,omitempty"`
+	N                  uint32            `json:"n"`
+	LastTakeoverHeight int32             `json:"lasttakeoverheight"`
+	Nodes              []ProofNodeResult `json:"nodes,omitempty"`
+	Pairs              []ProofPairResult `json:"pairs,omitempty"`
+}
+
+type GetClaimByIDCmd struct {
+	ClaimID       string  `json:"claimid"`
+	HashOrHeight  *string `json:"hashorheight" jsonrpcdefault:""`
+	IncludeValues *bool   `json:"includevalues" jsonrpcdefault:"false"`
+}
+
+type GetClaimByIDResult struct {
+	Hash           string      `json:"hash"`
+	Height         int32       `json:"height"`
+	NormalizedName string      `json:"normalizedname"`
+	Status         string      `json:"status"`
+	Claim          ClaimResult `json:"claim"`
+}
+
+type GetClaimHistoryCmd struct {
+	Name           string  `json:"name"`
+	PartialClaimID string  `json:"partialclaimid"`
+	HashOrHeight   *string `json:"hashorheight" jsonrpcdefault:""`
+}
+
+type ClaimHistoryResult struct {
+	Type               string `json:"type"`
+	Height             int32  `json:"height"`
+	Name               string `json:"name"`
+	TXID               string `json:"txid"`
+	N                  uint32 `json:"n"`
+	Amount             int64  `json:"amount"`
+	ValidAtHeight      int32  `json:"validatheight"`
+	ExpirationHeight   int32  `json:"expirationheight"`
+	Status             string `json:"status"`
+	Controlling        bool   `json:"controlling"`
+	LastTakeoverHeight int32  `json:"lasttakeoverheight"`
+}
+
+type GetClaimHistoryResult struct {
+	Hash           string               `json:"hash"`
+	Height         int32                `json:"height"`
+	NormalizedName string               `json:"normalizedname"`
+	ClaimID        string               `json:"claimid"`
+	History        []ClaimHistoryResult `json:"history"`
+}
+
+type GetTakeoverHistoryCmd struct {
+	Name       string `json:"name"`
+	FromHeight *int32 `json:"fromheight" jsonrpcdefault:"0"`
+	ToHeight   *int32 `json:"toheight" jsonrpcdefault:"-1"`
+}
+
+type TakeoverResult struct {
+	Height                  int32  `json:"height"`
+	PreviousClaimID         string `json:"previousclaimid,omitempty"`
+	ClaimID                 string `json:"claimid,omitempty"`
+	PreviousEffectiveAmount int64  `json:"previouseffectiveamount"`
+	EffectiveAmount         int64  `json:"effectiveamount"`
+}
+
+type GetTakeoverHistoryResult struct {
+	NormalizedName string           `json:"normalizedname"`
+	Takeovers      []TakeoverResult `json:"takeovers"`
+}
+
+type ListNamesCmd struct {
+	Prefix       *string `json:"prefix" jsonrpcdefault:""`
+	Start        *string `json:"start" jsonrpcdefault:""`
+	Limit        *int32  `json:"limit" jsonrpcdefault:"100"`
+	HashOrHeight *string `json:"hashorheight" jsonrpcdefault:""`
+}
+
+type NameResult struct {
+	Name               string `json:"name"`
+	ClaimID            string `json:"claimid"`
+	EffectiveAmount    int64  `json:"effectiveamount"`
+	LastTakeoverHeight int32  `json:"lasttakeoverheight"`
+}
+
+type ListNamesResult struct {
+	Hash   string       `json:"hash"`
+	Height int32        `json:"height"`
+	Names  []NameResult `json:"names"`
+	Next   string       `json:"next,omitempty"`
+}
+
+type GetClaimTrieInfoCmd struct {
+	ExpiringWithin *int32 `json:"expiringwithin" jsonrpcdefault:"576"`
+}
+
+type ClaimTrieStatsResult struct {
+	Names            int64 `json:"names"`
+	ActiveClaims     int64 `json:"activeclaims"`
+	AcceptedClaims   int64 `json:"acceptedclaims"`
+	ActiveSupports   int64 `json:"activesupports"`
+	AcceptedSupports int64 `json:"acceptedsupports"`
+	ClaimAmount      int64 `json:"claimamount"`
+	SupportAmount    int64 `json:"supportamount"`
+	ExpiringWithin   int32 `json:"expiringwithin"`
+	ExpiringNames    int64 `json:"expiringnames"`
+}
+
+type GetClaimTrieInfoResult struct {
+	Height          int32                 `json:"height"`
+	MerkleHash      string                `json:"merklehash"`
+	Stats           *ClaimTrieStatsResult `json:"stats,omitempty"`
+	NodeCacheHits   uint64                `json:"nodecachehits"`
+	NodeCacheMisses uint64                `json:"nodecachemisses"`
+}
+
+type VerifyClaimTrieCmd struct {
+	FromHeight *int32 `json:"fromheight" jsonrpcdefault:"0"`
+	ToHeight   *int32 `json:"toheight" jsonrpcdefault:"-1"`
+}
+
+type VerifyClaimTrieResult struct {
+	FromHeight      int32    `json:"fromheight"`
+	ToHeight        int32    `json:"toheight"`
+	Valid           bool     `json:"valid"`
+	DivergentHeight int32    `json:"divergentheight,omitempty"`
+	ComputedHash    string   `json:"computedhash,omitempty"`
+	StoredHash      string   `json:"storedhash,omitempty"`
+	HeaderHash      string   `json:"headerhash,omitempty"`
+	Names           []string `json:"names,omitempty"`
+}
+
+type GetExpiringClaimsCmd struct {
+	FromHeight int32 `json:"fromheight"`
+	ToHeight   int32 `json:"toheight"`
+}
+
+type ScheduledEventResult struct {
+	Height  int32  `json:"height"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	ClaimID string `json:"claimid"`
+	TXID    string `json:"txid"`
+	N       uint32 `json:"n"`
+	Amount  int64  `json:"amount"`
+}
+
+type GetExpiringClaimsResult struct {
+	Height int32                  `json:"height"`
+	Events []ScheduledEventResult `json:"events"`
+}
+
+type SimulateBidCmd struct {
+	Name    string  `json:"name"`
+	Amount  int64   `json:"amount"`
+	ClaimID *string `json:"claimid" jsonrpcdefault:""`
+	Support *bool   `json:"support" jsonrpcdefault:"false"`
+}
+
+type SimulateBidResult struct {
+	NormalizedName   string `json:"normalizedname"`
+	Height           int32  `json:"height"`
+	ClaimID          string `json:"claimid,omitempty"`
+	ActivationHeight int32  `json:"activationheight"`
+	Controlling      bool   `json:"controlling"`
+	TakeoverHeight   int32  `json:"takeoverheight,omitempty"`
+	EffectiveAmount  int64  `json:"effectiveamount"`
+	WinningAmount    int64  `json:"winningamount"`
+	Needed           int64  `json:"needed"`
+}
+
+type GetClaimTrieChangesCmd struct {
+	HashOrHeight *string `json:"hashorheight" jsonrpcdefault:""`
+}
+
+type ClaimTrieChangeResult struct {
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ClaimID       string `json:"claimid"`
+	TXID          string `json:"txid"`
+	N             uint32 `json:"n"`
+	Amount        int64  `json:"amount"`
+	Height        int32  `json:"height"`
+	ActiveHeight  int32  `json:"activeheight,omitempty"`
+	VisibleHeight int32  `json:"visibleheight,omitempty"`
+}
+
+type GetClaimTrieChangesResult struct {
+	Hash    string                  `json:"hash"`
+	Height  int32                   `json:"height"`
+	Changes []ClaimTrieChangeResult `json:"changes"`
+}
+
+type ResolveCmd struct {
+	URL           string  `json:"url"`
+	HashOrHeight  *string `json:"hashorheight" jsonrpcdefault:""`
+	IncludeValues *bool   `json:"includevalues" jsonrpcdefault:"false"`
+}
+
+type ResolveResult struct {
+	Hash           string      `json:"hash"`
+	Height         int32       `json:"height"`
+	NormalizedName string      `json:"normalizedname"`
+	Claim          ClaimResult `json:"claim"`
+}
+
+type GetClaimsForAddressCmd struct {
+	Address string `json:"address"`
+	Skip    *int   `json:"skip" jsonrpcdefault:"0"`
+	Count   *int   `json:"count" jsonrpcdefault:"100"`
+	Reverse *bool  `json:"reverse" jsonrpcdefault:"false"`
+}
+
+type AddressClaimResult struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	ClaimID   string `json:"claimid"`
+	TXID      string `json:"txid"`
+	N         uint32 `json:"n"`
+	Amount    int64  `json:"amount"`
+	BlockHash string `json:"blockhash"`
+	Height    int32  `json:"height"`
+	Spent     bool   `json:"spent"`
+}
+
+type GetClaimsForAddressResult struct {
+	Address string               `json:"address"`
+	Claims  []AddressClaimResult `json:"claims"`
+}
+
+type DecodeClaimValueCmd struct {
+	Value string  `json:"value"`
+	TXID  *string `json:"txid" jsonrpcdefault:""`
+}
+
+type DecodedFeeResult struct {
+	Currency string `json:"currency"`
+	Address  string `json:"address,omitempty"`
+	Amount   uint64 `json:"amount"`
+}
+
+type DecodedStreamResult struct {
+	MediaType   string            `json:"mediatype,omitempty"`
+	SourceName  string            `json:"sourcename,omitempty"`
+	SourceHash  string            `json:"sourcehash,omitempty"`
+	SDHash      string            `json:"sdhash,omitempty"`
+	Size        uint64            `json:"size,omitempty"`
+	Author      string            `json:"author,omitempty"`
+	License     string            `json:"license,omitempty"`
+	LicenseURL  string            `json:"licenseurl,omitempty"`
+	ReleaseTime int64             `json:"releasetime,omitempty"`
+	Kind        string            `json:"kind,omitempty"`
+	Fee         *DecodedFeeResult `json:"fee,omitempty"`
+}
+
+type DecodedChannelResult struct {
+	PublicKey  string   `json:"publickey"`
+	Email      string   `json:"email,omitempty"`
+	WebsiteURL string   `json:"websiteurl,omitempty"`
+	Cover      string   `json:"cover,omitempty"`
+	Featured   []string `json:"featured,omitempty"`
+}
+
+type DecodedClaimResult struct {
+	Format         string                `json:"format"`
+	Type           string                `json:"type"`
+	Title          string                `json:"title,omitempty"`
+	Description    string                `json:"description,omitempty"`
+	Thumbnail      string                `json:"thumbnail,omitempty"`
+	Tags           []string              `json:"tags,omitempty"`
+	Stream         *DecodedStreamResult  `json:"stream,omitempty"`
+	Channel        *DecodedChannelResult `json:"channel,omitempty"`
+	Claims         []string              `json:"claims,omitempty"`
+	SigningChannel string                `json:"signingchannel,omitempty"`
+	Signature      string                `json:"signature,omitempty"`
+	SignatureValid *bool                 `json:"signaturevalid,omitempty"`
+}
+
+type GetClaimsInChannelCmd struct {
+	ChannelID string `json:"channelid"`
+	Start     *int   `json:"start" jsonrpcdefault:"0"`
+	Limit     *int   `json:"limit" jsonrpcdefault:"100"`
+}
+
+type ChannelClaimResult struct {
+	ClaimID string `json:"claimid"`
+	Name    string `json:"name"`
+	TXID    string `json:"txid"`
+	N       uint32 `json:"n"`
+	Height  int32  `json:"height"`
+}
+
+type GetClaimsInChannelResult struct {
+	ChannelID string               `json:"channelid"`
+	Claims    []ChannelClaimResult `json:"claims"`
+}
+
+type GetTopClaimsCmd struct {
+	Count        *int32  `json:"count" jsonrpcdefault:"100"`
+	ByName       *bool   `json:"byname" jsonrpcdefault:"false"`
+	Prefix       *string `json:"prefix" jsonrpcdefault:""`
+	MinAmount    *int64  `json:"minamount" jsonrpcdefault:"0"`
+	HashOrHeight *string `json:"hashorheight" jsonrpcdefault:""`
+}
+
+type TopClaimResult struct {
+	Name            string `json:"name"`
+	ClaimID         string `json:"claimid"`
+	EffectiveAmount int64  `json:"effectiveamount"`
+}
+
+type GetTopClaimsResult struct {
+	Hash   string           `json:"hash"`
+	Height int32            `json:"height"`
+	Claims []TopClaimResult `json:"claims"`
+}
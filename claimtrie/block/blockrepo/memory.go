@@ -0,0 +1,60 @@
+package blockrepo
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+)
+
+type Memory struct {
+	hashes map[int32]chainhash.Hash
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		hashes: map[int32]chainhash.Hash{},
+	}
+}
+
+func (repo *Memory) Load() (int32, error) {
+
+	var height int32
+	for h := range repo.hashes {
+		if h > height {
+			height = h
+		}
+	}
+	return height, nil
+}
+
+func (repo *Memory) Get(height int32) (*chainhash.Hash, error) {
+
+	hash, ok := repo.hashes[height]
+	if !ok {
+		return nil, errors.Errorf("no hash at height %d", height)
+	}
+	return &hash, nil
+}
+
+func (repo *Memory) Set(height int32, hash *chainhash.Hash) error {
+	repo.hashes[height] = *hash
+	return nil
+}
+
+func (repo *Memory) DropBefore(height int32) error {
+
+	for h := range repo.hashes {
+		if h < height {
+			delete(repo.hashes, h)
+		}
+	}
+	return nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
@@ -59,6 +59,14 @@ func (repo *Pebble) Set(height int32, hash *chainhash.Hash) error {
 	return errors.WithStack(repo.db.Set(key, hash[:], pebble.NoSync))
 }
 
+func (repo *Pebble) DropBefore(height int32) error {
+
+	end := make([]byte, 4)
+	binary.BigEndian.PutUint32(end, uint32(height))
+
+	return errors.Wrap(repo.db.DeleteRange([]byte{}, end, pebble.NoSync), "in delete range")
+}
+
 func (repo *Pebble) Close() error {
 
 	err := repo.db.Flush()
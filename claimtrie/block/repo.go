@@ -9,6 +9,9 @@ type Repo interface {
 	Load() (int32, error)
 	Set(height int32, hash *chainhash.Hash) error
 	Get(height int32) (*chainhash.Hash, error)
+
+	// DropBefore removes the hashes of the heights below (excludes) the specified height.
+	DropBefore(height int32) error
 	Close() error
 	Flush() error
 }
@@ -0,0 +1,64 @@
+package chainrepo
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/change"
+)
+
+type Memory struct {
+	changes map[int32][]change.Change
+	height  int32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		changes: map[int32][]change.Change{},
+		height:  -1,
+	}
+}
+
+func (repo *Memory) Save(height int32, changes []change.Change) error {
+
+	if len(changes) == 0 {
+		delete(repo.changes, height)
+		return nil
+	}
+
+	repo.changes[height] = append([]change.Change(nil), changes...)
+	return nil
+}
+
+func (repo *Memory) Load(height int32) ([]change.Change, error) {
+
+	changes, ok := repo.changes[height]
+	if !ok {
+		return nil, nil
+	}
+	return append([]change.Change(nil), changes...), nil
+}
+
+func (repo *Memory) DropAfter(height int32) error {
+
+	for h := range repo.changes {
+		if h > height {
+			delete(repo.changes, h)
+		}
+	}
+	return nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+	repo.height = height
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
@@ -2,6 +2,7 @@ package chainrepo
 
 import (
 	"encoding/binary"
+	"math"
 
 	"github.com/pkg/errors"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/cockroachdb/pebble"
 )
 
+// The height keys are four bytes long and never past math.MaxInt32, so the tip key comes after all of them.
+var tipKey = []byte{0xff}
+
 type Pebble struct {
 	db *pebble.DB
 }
@@ -25,13 +29,13 @@ func NewPebble(path string) (*Pebble, error) {
 
 func (repo *Pebble) Save(height int32, changes []change.Change) error {
 
-	if len(changes) == 0 {
-		return nil
-	}
-
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], uint32(height))
 
+	if len(changes) == 0 {
+		return errors.Wrap(repo.db.Delete(key[:], pebble.NoSync), "in delete")
+	}
+
 	value, err := msgpack.Marshal(changes)
 	if err != nil {
 		return errors.Wrap(err, "in marshaller")
@@ -50,6 +54,9 @@ func (repo *Pebble) Load(height int32) ([]change.Change, error) {
 	if closer != nil {
 		defer closer.Close()
 	}
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "in get")
 	}
@@ -59,6 +66,37 @@ func (repo *Pebble) Load(height int32) ([]change.Change, error) {
 	return changes, errors.Wrap(err, "in unmarshaller")
 }
 
+func (repo *Pebble) DropAfter(height int32) error {
+
+	var from, to [4]byte
+	binary.BigEndian.PutUint32(from[:], uint32(height+1))
+	binary.BigEndian.PutUint32(to[:], uint32(math.MaxInt32)+1)
+
+	err := repo.db.DeleteRange(from[:], to[:], pebble.NoSync)
+	return errors.Wrap(err, "in delete range")
+}
+
+func (repo *Pebble) SetHeight(height int32) error {
+
+	var tip [4]byte
+	binary.BigEndian.PutUint32(tip[:], uint32(height))
+	return errors.Wrap(repo.db.Set(tipKey, tip[:], pebble.NoSync), "in set")
+}
+
+func (repo *Pebble) Height() (int32, error) {
+
+	data, closer, err := repo.db.Get(tipKey)
+	if err == pebble.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
 func (repo *Pebble) Close() error {
 
 	err := repo.db.Flush()
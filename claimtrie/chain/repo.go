@@ -2,9 +2,23 @@ package chain
 
 import "github.com/lbryio/lbcd/claimtrie/change"
 
+// Repo defines APIs for the log of the changes each block made to access persistence layer.
 type Repo interface {
+	// Save records the changes of the block at height, replacing any; no changes remove them.
 	Save(height int32, changes []change.Change) error
+
+	// Load returns the changes of the block at height, which are none when it has no record.
 	Load(height int32) ([]change.Change, error)
+
+	// DropAfter removes the changes of the blocks after height.
+	DropAfter(height int32) error
+
+	// SetHeight records the height the log has been built to.
+	SetHeight(height int32) error
+
+	// Height returns the height the log has been built to, or -1 for a new log.
+	Height() (int32, error)
+
 	Close() error
 	Flush() error
 }
@@ -16,6 +16,13 @@ const (
 	UpdateClaim
 	AddSupport
 	SpendSupport
+
+	// The ones below only report what a block did to the claims and supports in the log of changes;
+	// they are never applied to the nodes.
+	ActivateClaim
+	ExpireClaim
+	ActivateSupport
+	ExpireSupport
 )
 
 type Change struct {
@@ -0,0 +1,179 @@
+package channelrepo
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/channel"
+)
+
+type membership struct {
+	channel change.ClaimID
+	member  channel.Member
+}
+
+// undo is what a channel key or claim was before the first change made to it at a height.
+type undo struct {
+	key        []byte
+	membership *membership
+}
+
+type Memory struct {
+	keys        map[change.ClaimID][]byte
+	memberships map[change.ClaimID]membership
+	undos       map[int32]map[string]undo // by height, then by the kind of change and the ID
+	height      int32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		keys:        map[change.ClaimID][]byte{},
+		memberships: map[change.ClaimID]membership{},
+		undos:       map[int32]map[string]undo{},
+		height:      -1,
+	}
+}
+
+func (repo *Memory) saveUndo(height int32, kind byte, id change.ClaimID, u undo) {
+
+	undos, ok := repo.undos[height]
+	if !ok {
+		undos = map[string]undo{}
+		repo.undos[height] = undos
+	}
+	if _, ok = undos[string(kind)+id.Key()]; !ok {
+		undos[string(kind)+id.Key()] = u
+	}
+}
+
+func (repo *Memory) SetKey(height int32, id change.ClaimID, key []byte) error {
+
+	previous, ok := repo.keys[id]
+	if !ok && len(key) == 0 {
+		return nil
+	}
+	repo.saveUndo(height, keyPrefix, id, undo{key: previous})
+
+	if len(key) == 0 {
+		delete(repo.keys, id)
+	} else {
+		repo.keys[id] = append([]byte{}, key...)
+	}
+	return nil
+}
+
+func (repo *Memory) Key(id change.ClaimID) ([]byte, error) {
+
+	key, ok := repo.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte{}, key...), nil
+}
+
+func (repo *Memory) SetMember(height int32, ch change.ClaimID, m channel.Member) error {
+
+	previous, ok := repo.memberships[m.ClaimID]
+	if !ok && ch == (change.ClaimID{}) {
+		return nil
+	}
+	u := undo{}
+	if ok {
+		u.membership = &previous
+	}
+	repo.saveUndo(height, claimPrefix, m.ClaimID, u)
+
+	if ch == (change.ClaimID{}) {
+		delete(repo.memberships, m.ClaimID)
+		return nil
+	}
+	m.Height = height
+	if ok && previous.channel == ch {
+		m.Height = previous.member.Height
+	}
+	m.Name = append([]byte{}, m.Name...)
+	repo.memberships[m.ClaimID] = membership{channel: ch, member: m}
+	return nil
+}
+
+func (repo *Memory) Members(ch change.ClaimID, start, limit int) ([]channel.Member, error) {
+
+	if start < 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	var members []channel.Member
+	for _, ms := range repo.memberships {
+		if ms.channel == ch {
+			members = append(members, ms.member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Height != members[j].Height {
+			return members[i].Height < members[j].Height
+		}
+		return bytes.Compare(members[i].ClaimID[:], members[j].ClaimID[:]) < 0
+	})
+
+	if start >= len(members) {
+		return nil, nil
+	}
+	members = members[start:]
+	if limit < len(members) {
+		members = members[:limit]
+	}
+	return members, nil
+}
+
+func (repo *Memory) DropAfter(height int32) error {
+
+	var heights []int32
+	for h := range repo.undos {
+		if h > height {
+			heights = append(heights, h)
+		}
+	}
+	// the earliest changes are undone last, so what's left is what came before them
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+
+	for _, h := range heights {
+		for k, u := range repo.undos[h] {
+			var id change.ClaimID
+			copy(id[:], k[1:])
+			switch k[0] {
+			case keyPrefix:
+				if u.key == nil {
+					delete(repo.keys, id)
+				} else {
+					repo.keys[id] = u.key
+				}
+			case claimPrefix:
+				if u.membership == nil {
+					delete(repo.memberships, id)
+				} else {
+					repo.memberships[id] = *u.membership
+				}
+			}
+		}
+		delete(repo.undos, h)
+	}
+	return nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+	repo.height = height
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
@@ -0,0 +1,305 @@
+package channelrepo
+
+import (
+	"encoding/binary"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/channel"
+)
+
+// key formats:
+//
+//	key:    'k'(1B) + channelID(20B) -> public key(variable length)
+//	claim:  'c'(1B) + claimID(20B) -> channelID(20B) + height(4B)
+//	member: 'm'(1B) + channelID(20B) + height(4B) + claimID(20B) -> txid(32B) + index(4B) + name(variable length)
+//	undo:   'u'(1B) + height(4B) + 'k' or 'c'(1B) + ID(20B) -> the key or claim + member value before the height
+//	tip:    't'(1B) -> height(4B)
+//
+// The height of a claim and its member is the one it joined the channel at. An empty undo value stands for
+// a channel or claim that wasn't there.
+const (
+	keyPrefix    = 'k'
+	claimPrefix  = 'c'
+	memberPrefix = 'm'
+	undoPrefix   = 'u'
+	tipPrefix    = 't'
+)
+
+type Pebble struct {
+	db *pebble.DB
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(16 << 20), MaxOpenFiles: 2000})
+	repo := &Pebble{db: db}
+
+	return repo, errors.Wrapf(err, "unable to open %s", path)
+}
+
+func idKey(prefix byte, id change.ClaimID) []byte {
+	return append([]byte{prefix}, id[:]...)
+}
+
+func memberKey(ch change.ClaimID, height int32, id change.ClaimID) []byte {
+	key := make([]byte, 1+change.ClaimIDSize+4, 1+2*change.ClaimIDSize+4)
+	key[0] = memberPrefix
+	copy(key[1:], ch[:])
+	binary.BigEndian.PutUint32(key[1+change.ClaimIDSize:], uint32(height))
+	return append(key, id[:]...)
+}
+
+func undoKey(height int32, kind byte, id []byte) []byte {
+	key := make([]byte, 6, 6+change.ClaimIDSize)
+	key[0] = undoPrefix
+	binary.BigEndian.PutUint32(key[1:], uint32(height))
+	key[5] = kind
+	return append(key, id...)
+}
+
+// get returns a copy of the value of key, and whether it's there.
+func get(r pebble.Reader, key []byte) ([]byte, bool, error) {
+
+	data, closer, err := r.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return append([]byte{}, data...), true, nil
+}
+
+// setUndo records what a channel key or claim was before height, unless something else changed it at height.
+func setUndo(batch *pebble.Batch, height int32, kind byte, id change.ClaimID, value []byte) error {
+
+	key := undoKey(height, kind, id[:])
+	_, found, err := get(batch, key)
+	if err != nil || found {
+		return err
+	}
+	return errors.Wrap(batch.Set(key, value, pebble.NoSync), "in set undo")
+}
+
+func (repo *Pebble) SetKey(height int32, id change.ClaimID, key []byte) error {
+
+	batch := repo.db.NewIndexedBatch()
+	defer batch.Close()
+
+	previous, found, err := get(batch, idKey(keyPrefix, id))
+	if err != nil || (!found && len(key) == 0) {
+		return err
+	}
+	if err = setUndo(batch, height, keyPrefix, id, previous); err != nil {
+		return err
+	}
+
+	if len(key) == 0 {
+		err = batch.Delete(idKey(keyPrefix, id), pebble.NoSync)
+	} else {
+		err = batch.Set(idKey(keyPrefix, id), key, pebble.NoSync)
+	}
+	if err != nil {
+		return errors.Wrap(err, "in set key")
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) Key(id change.ClaimID) ([]byte, error) {
+
+	key, _, err := get(repo.db, idKey(keyPrefix, id))
+	return key, err
+}
+
+// removeMember takes a claim out of its channel, returning the claim and member values it had.
+func removeMember(batch *pebble.Batch, id change.ClaimID) ([]byte, error) {
+
+	claim, found, err := get(batch, idKey(claimPrefix, id))
+	if err != nil || !found {
+		return nil, err
+	}
+	var ch change.ClaimID
+	copy(ch[:], claim)
+	key := memberKey(ch, int32(binary.BigEndian.Uint32(claim[change.ClaimIDSize:])), id)
+	member, _, err := get(batch, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = batch.Delete(key, pebble.NoSync); err != nil {
+		return nil, errors.Wrap(err, "in delete member")
+	}
+	if err = batch.Delete(idKey(claimPrefix, id), pebble.NoSync); err != nil {
+		return nil, errors.Wrap(err, "in delete claim")
+	}
+	return append(claim, member...), nil
+}
+
+// putMember puts a claim in a channel from the claim and member values.
+func putMember(batch *pebble.Batch, id change.ClaimID, value []byte) error {
+
+	var ch change.ClaimID
+	copy(ch[:], value)
+	height := int32(binary.BigEndian.Uint32(value[change.ClaimIDSize:]))
+
+	err := batch.Set(idKey(claimPrefix, id), value[:change.ClaimIDSize+4], pebble.NoSync)
+	if err != nil {
+		return errors.Wrap(err, "in set claim")
+	}
+	err = batch.Set(memberKey(ch, height, id), value[change.ClaimIDSize+4:], pebble.NoSync)
+	return errors.Wrap(err, "in set member")
+}
+
+func (repo *Pebble) SetMember(height int32, ch change.ClaimID, m channel.Member) error {
+
+	batch := repo.db.NewIndexedBatch()
+	defer batch.Close()
+
+	previous, err := removeMember(batch, m.ClaimID)
+	if err != nil || (previous == nil && ch == change.ClaimID{}) {
+		return err
+	}
+	if err = setUndo(batch, height, claimPrefix, m.ClaimID, previous); err != nil {
+		return err
+	}
+
+	if ch != (change.ClaimID{}) {
+		joined := height
+		if previous != nil && string(previous[:change.ClaimIDSize]) == string(ch[:]) {
+			joined = int32(binary.BigEndian.Uint32(previous[change.ClaimIDSize:]))
+		}
+		value := make([]byte, change.ClaimIDSize+4+chainhash.HashSize+4, change.ClaimIDSize+4+chainhash.HashSize+4+len(m.Name))
+		copy(value, ch[:])
+		binary.BigEndian.PutUint32(value[change.ClaimIDSize:], uint32(joined))
+		copy(value[change.ClaimIDSize+4:], m.OutPoint.Hash[:])
+		binary.BigEndian.PutUint32(value[change.ClaimIDSize+4+chainhash.HashSize:], m.OutPoint.Index)
+		if err = putMember(batch, m.ClaimID, append(value, m.Name...)); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) Members(ch change.ClaimID, start, limit int) ([]channel.Member, error) {
+
+	if start < 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	// the heights are never past math.MaxInt32, so their first byte is below 0x80
+	prefix := idKey(memberPrefix, ch)
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(idKey(memberPrefix, ch), 0x80),
+	})
+	defer iter.Close()
+
+	var members []channel.Member
+	for iter.First(); iter.Valid() && len(members) < limit; iter.Next() {
+		if start > 0 {
+			start--
+			continue
+		}
+		key, value := iter.Key(), iter.Value()
+		m := channel.Member{
+			Height: int32(binary.BigEndian.Uint32(key[len(prefix):])),
+			Name:   append([]byte{}, value[chainhash.HashSize+4:]...),
+		}
+		copy(m.ClaimID[:], key[len(prefix)+4:])
+		copy(m.OutPoint.Hash[:], value)
+		m.OutPoint.Index = binary.BigEndian.Uint32(value[chainhash.HashSize:])
+		members = append(members, m)
+	}
+	return members, errors.Wrap(iter.Error(), "in iterate")
+}
+
+func (repo *Pebble) DropAfter(height int32) error {
+
+	batch := repo.db.NewIndexedBatch()
+	defer batch.Close()
+
+	var keys, values [][]byte
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: undoKey(height+1, 0, nil),
+		UpperBound: []byte{undoPrefix + 1},
+	})
+	for iter.First(); iter.Valid(); iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()...))
+		values = append(values, append([]byte{}, iter.Value()...))
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Wrap(err, "in close")
+	}
+
+	// the earliest changes are undone last, so what's left is what came before them
+	for i := len(keys) - 1; i >= 0; i-- {
+		var id change.ClaimID
+		copy(id[:], keys[i][6:])
+
+		var err error
+		switch keys[i][5] {
+		case keyPrefix:
+			if len(values[i]) == 0 {
+				err = batch.Delete(idKey(keyPrefix, id), pebble.NoSync)
+			} else {
+				err = batch.Set(idKey(keyPrefix, id), values[i], pebble.NoSync)
+			}
+		case claimPrefix:
+			_, err = removeMember(batch, id)
+			if err == nil && len(values[i]) > 0 {
+				err = putMember(batch, id, values[i])
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, "in undo")
+		}
+		if err = batch.Delete(keys[i], pebble.NoSync); err != nil {
+			return errors.Wrap(err, "in delete undo")
+		}
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) SetHeight(height int32) error {
+
+	var tip [4]byte
+	binary.BigEndian.PutUint32(tip[:], uint32(height))
+	return errors.Wrap(repo.db.Set([]byte{tipPrefix}, tip[:], pebble.NoSync), "in set")
+}
+
+func (repo *Pebble) Height() (int32, error) {
+
+	data, closer, err := repo.db.Get([]byte{tipPrefix})
+	if err == pebble.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
+func (repo *Pebble) Close() error {
+
+	err := repo.db.Flush()
+	if err != nil {
+		// if we fail to close are we going to try again later?
+		return errors.Wrap(err, "on flush")
+	}
+
+	err = repo.db.Close()
+	return errors.Wrap(err, "on close")
+}
+
+func (repo *Pebble) Flush() error {
+	_, err := repo.db.AsyncFlush()
+	return err
+}
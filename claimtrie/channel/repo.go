@@ -0,0 +1,43 @@
+package channel
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/wire"
+)
+
+// Member is a stream claim signed by a channel.
+type Member struct {
+	ClaimID  change.ClaimID
+	Name     []byte
+	OutPoint wire.OutPoint // The output of the latest version of the claim.
+	Height   int32         // The height the claim joined the channel at; updates within the channel keep it.
+}
+
+// Repo defines APIs for the channel index to access persistence layer.
+type Repo interface {
+	// SetKey records the public key of a channel as of height. A nil key removes the channel.
+	SetKey(height int32, id change.ClaimID, key []byte) error
+
+	// Key returns the public key of a channel, or nil if it's unknown.
+	Key(id change.ClaimID) ([]byte, error)
+
+	// SetMember records the channel a claim is in as of height, along with its latest version. A zero channel
+	// takes the claim out of the one it's in. The height of the member is ignored; the repo keeps track of it.
+	SetMember(height int32, channel change.ClaimID, m Member) error
+
+	// Members returns up to limit claims of the channel, after skipping the first start of them,
+	// in the order they joined it.
+	Members(channel change.ClaimID, start, limit int) ([]Member, error)
+
+	// DropAfter undoes the changes made after height.
+	DropAfter(height int32) error
+
+	// SetHeight records the height the index has been built to.
+	SetHeight(height int32) error
+
+	// Height returns the height the index has been built to, or -1 for a new index.
+	Height() (int32, error)
+
+	Close() error
+	Flush() error
+}
@@ -0,0 +1,117 @@
+package claimidrepo
+
+import (
+	"sort"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/claimid"
+)
+
+type Memory struct {
+	claims map[change.ClaimID]claimid.Claim
+	undo   map[int32]map[change.ClaimID]*claimid.Claim // nil for the claims that weren't there
+	base   int32
+	height int32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		claims: map[change.ClaimID]claimid.Claim{},
+		undo:   map[int32]map[change.ClaimID]*claimid.Claim{},
+		height: -1,
+	}
+}
+
+func clone(c claimid.Claim) claimid.Claim {
+	c.Name = append([]byte{}, c.Name...)
+	return c
+}
+
+func (repo *Memory) Set(height int32, ids []change.ClaimID, claims []claimid.Claim) error {
+
+	if height <= repo.base { // there's no undoing to the base or below it
+		for i, id := range ids {
+			repo.claims[id] = clone(claims[i])
+		}
+		return nil
+	}
+
+	undo := repo.undo[height]
+	if undo == nil {
+		undo = map[change.ClaimID]*claimid.Claim{}
+		repo.undo[height] = undo
+	}
+	for i, id := range ids {
+		if _, found := undo[id]; !found {
+			var previous *claimid.Claim
+			if c, ok := repo.claims[id]; ok {
+				previous = &c
+			}
+			undo[id] = previous
+		}
+		repo.claims[id] = clone(claims[i])
+	}
+	return nil
+}
+
+func (repo *Memory) Get(id change.ClaimID) (*claimid.Claim, error) {
+
+	c, ok := repo.claims[id]
+	if !ok {
+		return nil, nil
+	}
+	c = clone(c)
+	return &c, nil
+}
+
+func (repo *Memory) DropAfter(height int32) error {
+
+	var heights []int32
+	for h := range repo.undo {
+		if h > height {
+			heights = append(heights, h)
+		}
+	}
+
+	// the latest changes are undone first, so what's left is what came before the earliest ones
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	for _, h := range heights {
+		for id, previous := range repo.undo[h] {
+			if previous == nil {
+				delete(repo.claims, id)
+			} else {
+				repo.claims[id] = *previous
+			}
+		}
+		delete(repo.undo, h)
+	}
+	return nil
+}
+
+func (repo *Memory) Clear(height int32) error {
+	repo.claims = map[change.ClaimID]claimid.Claim{}
+	repo.undo = map[int32]map[change.ClaimID]*claimid.Claim{}
+	repo.base = height
+	return nil
+}
+
+func (repo *Memory) Base() (int32, error) {
+	return repo.base, nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+	repo.height = height
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
@@ -0,0 +1,237 @@
+package claimidrepo
+
+import (
+	"encoding/binary"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/claimid"
+)
+
+// key formats:
+//
+//	claim: 'c'(1B) + claimID(20B) -> created(4B) + txid(32B) + index(4B) + amount(8B) + status(1B) + name(variable length)
+//	undo:  'u'(1B) + height(4B) + claimID(20B) -> the claim value before the height
+//	base:  'b'(1B) -> height(4B)
+//	tip:   't'(1B) -> height(4B)
+//
+// An empty undo value stands for a claim that wasn't there.
+const (
+	claimPrefix = 'c'
+	undoPrefix  = 'u'
+	basePrefix  = 'b'
+	tipPrefix   = 't'
+
+	nameOffset = 4 + chainhash.HashSize + 4 + 8 + 1
+)
+
+type Pebble struct {
+	db *pebble.DB
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(16 << 20), MaxOpenFiles: 2000})
+	repo := &Pebble{db: db}
+
+	return repo, errors.Wrapf(err, "unable to open %s", path)
+}
+
+func claimKey(id []byte) []byte {
+	return append([]byte{claimPrefix}, id...)
+}
+
+func undoKey(height int32, id []byte) []byte {
+	key := make([]byte, 5, 5+change.ClaimIDSize)
+	key[0] = undoPrefix
+	binary.BigEndian.PutUint32(key[1:], uint32(height))
+	return append(key, id...)
+}
+
+func marshal(c claimid.Claim) []byte {
+	value := make([]byte, nameOffset, nameOffset+len(c.Name))
+	binary.BigEndian.PutUint32(value, uint32(c.Created))
+	copy(value[4:], c.OutPoint.Hash[:])
+	binary.BigEndian.PutUint32(value[4+chainhash.HashSize:], c.OutPoint.Index)
+	binary.BigEndian.PutUint64(value[8+chainhash.HashSize:], uint64(c.Amount))
+	value[nameOffset-1] = byte(c.Status)
+	return append(value, c.Name...)
+}
+
+func unmarshal(value []byte) *claimid.Claim {
+	c := &claimid.Claim{
+		Name:    append([]byte{}, value[nameOffset:]...),
+		Created: int32(binary.BigEndian.Uint32(value)),
+		Amount:  int64(binary.BigEndian.Uint64(value[8+chainhash.HashSize:])),
+		Status:  claimid.Status(value[nameOffset-1]),
+	}
+	copy(c.OutPoint.Hash[:], value[4:])
+	c.OutPoint.Index = binary.BigEndian.Uint32(value[4+chainhash.HashSize:])
+	return c
+}
+
+// get returns a copy of the value of key, and whether it's there.
+func get(r pebble.Reader, key []byte) ([]byte, bool, error) {
+
+	data, closer, err := r.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return append([]byte{}, data...), true, nil
+}
+
+func (repo *Pebble) Set(height int32, ids []change.ClaimID, claims []claimid.Claim) error {
+
+	batch := repo.db.NewIndexedBatch()
+	defer batch.Close()
+
+	base, err := repo.Base()
+	if err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		// the first change at a height has the value from before it; there's no undoing to the base or below it
+		found := height <= base
+		if !found {
+			_, found, err = get(batch, undoKey(height, id[:]))
+			if err != nil {
+				return err
+			}
+		}
+		if !found {
+			previous, _, err := get(batch, claimKey(id[:]))
+			if err != nil {
+				return err
+			}
+			if err = batch.Set(undoKey(height, id[:]), previous, pebble.NoSync); err != nil {
+				return errors.Wrap(err, "in set undo")
+			}
+		}
+		if err = batch.Set(claimKey(id[:]), marshal(claims[i]), pebble.NoSync); err != nil {
+			return errors.Wrap(err, "in set claim")
+		}
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) Get(id change.ClaimID) (*claimid.Claim, error) {
+
+	value, found, err := get(repo.db, claimKey(id[:]))
+	if err != nil || !found {
+		return nil, err
+	}
+	return unmarshal(value), nil
+}
+
+func (repo *Pebble) DropAfter(height int32) error {
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	var keys, values [][]byte
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: undoKey(height+1, nil),
+		UpperBound: []byte{undoPrefix + 1},
+	})
+	for iter.First(); iter.Valid(); iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()...))
+		values = append(values, append([]byte{}, iter.Value()...))
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Wrap(err, "in close")
+	}
+
+	// the earliest changes are undone last, so what's left is what came before them
+	for i := len(keys) - 1; i >= 0; i-- {
+		var err error
+		if len(values[i]) == 0 {
+			err = batch.Delete(claimKey(keys[i][5:]), pebble.NoSync)
+		} else {
+			err = batch.Set(claimKey(keys[i][5:]), values[i], pebble.NoSync)
+		}
+		if err != nil {
+			return errors.Wrap(err, "in undo")
+		}
+		if err = batch.Delete(keys[i], pebble.NoSync); err != nil {
+			return errors.Wrap(err, "in delete undo")
+		}
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) Clear(height int32) error {
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	err := batch.DeleteRange([]byte{claimPrefix}, []byte{claimPrefix + 1}, pebble.NoSync)
+	if err != nil {
+		return errors.Wrap(err, "in delete claims")
+	}
+	err = batch.DeleteRange([]byte{undoPrefix}, []byte{undoPrefix + 1}, pebble.NoSync)
+	if err != nil {
+		return errors.Wrap(err, "in delete undo")
+	}
+	var base [4]byte
+	binary.BigEndian.PutUint32(base[:], uint32(height))
+	if err = batch.Set([]byte{basePrefix}, base[:], pebble.NoSync); err != nil {
+		return errors.Wrap(err, "in set base")
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) Base() (int32, error) {
+
+	data, found, err := get(repo.db, []byte{basePrefix})
+	if err != nil || !found {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
+func (repo *Pebble) SetHeight(height int32) error {
+
+	var tip [4]byte
+	binary.BigEndian.PutUint32(tip[:], uint32(height))
+	return errors.Wrap(repo.db.Set([]byte{tipPrefix}, tip[:], pebble.NoSync), "in set")
+}
+
+func (repo *Pebble) Height() (int32, error) {
+
+	data, closer, err := repo.db.Get([]byte{tipPrefix})
+	if err == pebble.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
+func (repo *Pebble) Close() error {
+
+	err := repo.db.Flush()
+	if err != nil {
+		// if we fail to close are we going to try again later?
+		return errors.Wrap(err, "on flush")
+	}
+
+	err = repo.db.Close()
+	return errors.Wrap(err, "on close")
+}
+
+func (repo *Pebble) Flush() error {
+	_, err := repo.db.AsyncFlush()
+	return err
+}
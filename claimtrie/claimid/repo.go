@@ -0,0 +1,51 @@
+package claimid
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/wire"
+)
+
+// Status is where a claim stands in the ClaimTrie.
+type Status byte
+
+const (
+	Accepted  Status = iota // In the trie, waiting for its activation.
+	Activated               // In the trie and active.
+	Removed                 // Spent or expired.
+)
+
+// Claim is what the claim ID index records of a claim.
+type Claim struct {
+	Name     []byte        // The name in the trie at the height the claim was created; normalize it for later heights.
+	Created  int32         // The height the claim was created at.
+	OutPoint wire.OutPoint // The output of the latest version of the claim.
+	Amount   int64         // The amount of the latest version of the claim.
+	Status   Status
+}
+
+// Repo defines APIs for the claim ID index to access persistence layer.
+type Repo interface {
+	// Set records the claims as of height, keeping what they were before it so that DropAfter can undo it.
+	Set(height int32, ids []change.ClaimID, claims []Claim) error
+
+	// Get returns the latest record of a claim, or nil if the claim is unknown.
+	Get(id change.ClaimID) (*Claim, error)
+
+	// DropAfter undoes the changes made after height.
+	DropAfter(height int32) error
+
+	// Clear removes all the claims, along with what it takes to undo their changes, and sets the base to height.
+	Clear(height int32) error
+
+	// Base returns the lowest height the changes can be undone to.
+	Base() (int32, error)
+
+	// SetHeight records the height the index has been built to.
+	SetHeight(height int32) error
+
+	// Height returns the height the index has been built to, or -1 for a new index.
+	Height() (int32, error)
+
+	Close() error
+	Flush() error
+}
@@ -12,7 +12,13 @@ import (
 
 	"github.com/lbryio/lbcd/claimtrie/block"
 	"github.com/lbryio/lbcd/claimtrie/block/blockrepo"
+	"github.com/lbryio/lbcd/claimtrie/chain"
+	"github.com/lbryio/lbcd/claimtrie/chain/chainrepo"
 	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/channel"
+	"github.com/lbryio/lbcd/claimtrie/channel/channelrepo"
+	"github.com/lbryio/lbcd/claimtrie/claimid"
+	"github.com/lbryio/lbcd/claimtrie/claimid/claimidrepo"
 	"github.com/lbryio/lbcd/claimtrie/config"
 	"github.com/lbryio/lbcd/claimtrie/merkletrie"
 	"github.com/lbryio/lbcd/claimtrie/merkletrie/merkletrierepo"
@@ -20,6 +26,13 @@ import (
 	"github.com/lbryio/lbcd/claimtrie/node/noderepo"
 	"github.com/lbryio/lbcd/claimtrie/normalization"
 	"github.com/lbryio/lbcd/claimtrie/param"
+	"github.com/lbryio/lbcd/claimtrie/proof"
+	"github.com/lbryio/lbcd/claimtrie/ranking"
+	"github.com/lbryio/lbcd/claimtrie/ranking/rankingrepo"
+	"github.com/lbryio/lbcd/claimtrie/stats"
+	"github.com/lbryio/lbcd/claimtrie/stats/statsrepo"
+	"github.com/lbryio/lbcd/claimtrie/takeover"
+	"github.com/lbryio/lbcd/claimtrie/takeover/takeoverrepo"
 	"github.com/lbryio/lbcd/claimtrie/temporal"
 	"github.com/lbryio/lbcd/claimtrie/temporal/temporalrepo"
 
@@ -27,6 +40,24 @@ import (
 	"github.com/lbryio/lbcd/wire"
 )
 
+// ErrNoClaimIDIndex is returned by queries that need the claim ID index when it is disabled.
+var ErrNoClaimIDIndex = errors.New("the claim ID index is not enabled")
+
+// ErrNoTakeoverIndex is returned by queries that need the takeover index when it is disabled.
+var ErrNoTakeoverIndex = errors.New("the takeover index is not enabled")
+
+// ErrNoChangeIndex is returned by queries that need the change index when it is disabled.
+var ErrNoChangeIndex = errors.New("the claimtrie change index is not enabled")
+
+// ErrNoChannelIndex is returned by queries that need the channel index when it is disabled.
+var ErrNoChannelIndex = errors.New("the channel index is not enabled")
+
+// ErrNoRankingIndex is returned by queries that need the ranking index when it is disabled.
+var ErrNoRankingIndex = errors.New("the ranking index is not enabled")
+
+// ErrNoStats is returned by queries that need the statistics when they are disabled.
+var ErrNoStats = errors.New("the claimtrie statistics are not enabled")
+
 // ClaimTrie implements a Merkle Trie supporting linear history of commits.
 type ClaimTrie struct {
 
@@ -41,12 +72,41 @@ type ClaimTrie struct {
 	// Cache layer of Nodes.
 	nodeManager node.Manager
 
+	// Repository for changes to nodes, which is owned by the nodeManager; kept for bulk reads.
+	nodeRepo node.Repo
+
 	// Prefix tree (trie) that manages merkle hash of each node.
 	merkleTrie merkletrie.MerkleTrie
 
+	// Optional index of claim IDs to the names, latest versions and statuses of their claims; nil when disabled.
+	claimIDRepo claimid.Repo
+
+	// Optional log of the takeovers of each name; nil when disabled.
+	takeoverRepo takeover.Repo
+
+	// Optional log of the changes each block made to the claims and supports; nil when disabled.
+	chainRepo chain.Repo
+
+	// Optional index of the stream claims signed by each channel; nil when disabled.
+	channelRepo channel.Repo
+
+	// Optional rankings of the names and claims by their effective amounts; nil when disabled.
+	rankingRepo ranking.Repo
+
+	// Optional aggregate statistics, kept up to date with each block; nil when disabled.
+	statsRepo stats.Repo
+	stats     stats.Stats
+
+	// Number of blocks below the tip that the history is kept for; 0 keeps all of it.
+	pruneDepth int32
+
 	// Current block height, which is increased by one when AppendBlock() is called.
 	height int32
 
+	// The hash of the trie at the current height, once it has been computed; the proofs are read from the
+	// hashes computed for it rather than computing any, so they don't change the trie.
+	committedHash *chainhash.Hash
+
 	// Registrered cleanup functions which are invoked in the Close() in reverse order.
 	cleanups []func() error
 }
@@ -54,34 +114,60 @@ type ClaimTrie struct {
 func New(cfg config.Config) (*ClaimTrie, error) {
 
 	var cleanups []func() error
+	var err error
 
 	// The passed in cfg.DataDir has been prepended with netname.
 	dataDir := filepath.Join(cfg.DataDir, "claim_dbs")
 
-	dbPath := filepath.Join(dataDir, cfg.BlockRepoPebble.Path)
-	blockRepo, err := blockrepo.NewPebble(dbPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating block repo")
+	var blockRepo block.Repo
+	if cfg.Memory {
+		blockRepo = blockrepo.NewMemory()
+	} else {
+		dbPath := filepath.Join(dataDir, cfg.BlockRepoPebble.Path)
+		blockRepo, err = blockrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating block repo")
+		}
 	}
 	cleanups = append(cleanups, blockRepo.Close)
+
+	// The repos hold what the nodes were computed to with the parameters they were built with.
+	if !cfg.Memory {
+		if err = checkParams(filepath.Join(dataDir, cfg.ParamsFile)); err != nil {
+			_ = blockRepo.Close()
+			return nil, err
+		}
+	}
+
 	err = blockRepo.Set(0, merkletrie.EmptyTrieHash)
 	if err != nil {
 		return nil, errors.Wrap(err, "setting block repo genesis")
 	}
 
-	dbPath = filepath.Join(dataDir, cfg.TemporalRepoPebble.Path)
-	temporalRepo, err := temporalrepo.NewPebble(dbPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating temporal repo")
+	var temporalRepo temporal.Repo
+	if cfg.Memory {
+		temporalRepo = temporalrepo.NewMemory()
+	} else {
+		dbPath := filepath.Join(dataDir, cfg.TemporalRepoPebble.Path)
+		temporalRepo, err = temporalrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating temporal repo")
+		}
 	}
 	cleanups = append(cleanups, temporalRepo.Close)
 
 	// Initialize repository for changes to nodes.
 	// The cleanup is delegated to the Node Manager.
-	dbPath = filepath.Join(dataDir, cfg.NodeRepoPebble.Path)
-	nodeRepo, err := noderepo.NewPebble(dbPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating node repo")
+	var nodeRepo node.Repo
+	if cfg.Memory {
+		nodeRepo = noderepo.NewMemory()
+	} else {
+		dbPath := filepath.Join(dataDir, cfg.NodeRepoPebble.Path)
+		snapshotPath := filepath.Join(dataDir, cfg.SnapshotRepoPebble.Path)
+		nodeRepo, err = noderepo.NewPebble(dbPath, snapshotPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating node repo")
+		}
 	}
 
 	baseManager, err := node.NewBaseManager(nodeRepo)
@@ -98,10 +184,15 @@ func New(cfg config.Config) (*ClaimTrie, error) {
 	} else {
 
 		// Initialize repository for MerkleTrie. The cleanup is delegated to MerkleTrie.
-		dbPath = filepath.Join(dataDir, cfg.MerkleTrieRepoPebble.Path)
-		trieRepo, err := merkletrierepo.NewPebble(dbPath)
-		if err != nil {
-			return nil, errors.Wrap(err, "creating trie repo")
+		var trieRepo merkletrie.Repo
+		if cfg.Memory {
+			trieRepo = merkletrierepo.NewMemory()
+		} else {
+			dbPath := filepath.Join(dataDir, cfg.MerkleTrieRepoPebble.Path)
+			trieRepo, err = merkletrierepo.NewPebble(dbPath)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating trie repo")
+			}
 		}
 
 		persistentTrie := merkletrie.NewPersistentTrie(trieRepo)
@@ -109,6 +200,90 @@ func New(cfg config.Config) (*ClaimTrie, error) {
 		trie = persistentTrie
 	}
 
+	var claimIDRepo claimid.Repo
+	if cfg.ClaimIDIndex && cfg.Memory {
+		claimIDRepo = claimidrepo.NewMemory()
+	} else if cfg.ClaimIDIndex {
+		dbPath := filepath.Join(dataDir, cfg.ClaimIDRepoPebble.Path)
+		claimIDRepo, err = claimidrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating claim ID repo")
+		}
+	}
+	if claimIDRepo != nil {
+		cleanups = append(cleanups, claimIDRepo.Close)
+	}
+
+	var takeoverRepo takeover.Repo
+	if cfg.TakeoverIndex && cfg.Memory {
+		takeoverRepo = takeoverrepo.NewMemory()
+	} else if cfg.TakeoverIndex {
+		dbPath := filepath.Join(dataDir, cfg.TakeoverRepoPebble.Path)
+		takeoverRepo, err = takeoverrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating takeover repo")
+		}
+	}
+	if takeoverRepo != nil {
+		cleanups = append(cleanups, takeoverRepo.Close)
+	}
+
+	var chainRepo chain.Repo
+	if cfg.ChangeIndex && cfg.Memory {
+		chainRepo = chainrepo.NewMemory()
+	} else if cfg.ChangeIndex {
+		dbPath := filepath.Join(dataDir, cfg.ChainRepoPebble.Path)
+		chainRepo, err = chainrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating chain repo")
+		}
+	}
+	if chainRepo != nil {
+		cleanups = append(cleanups, chainRepo.Close)
+	}
+
+	var channelRepo channel.Repo
+	if cfg.ChannelIndex && cfg.Memory {
+		channelRepo = channelrepo.NewMemory()
+	} else if cfg.ChannelIndex {
+		dbPath := filepath.Join(dataDir, cfg.ChannelRepoPebble.Path)
+		channelRepo, err = channelrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating channel repo")
+		}
+	}
+	if channelRepo != nil {
+		cleanups = append(cleanups, channelRepo.Close)
+	}
+
+	var rankingRepo ranking.Repo
+	if cfg.RankingIndex && cfg.Memory {
+		rankingRepo = rankingrepo.NewMemory()
+	} else if cfg.RankingIndex {
+		dbPath := filepath.Join(dataDir, cfg.RankingRepoPebble.Path)
+		rankingRepo, err = rankingrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating ranking repo")
+		}
+	}
+	if rankingRepo != nil {
+		cleanups = append(cleanups, rankingRepo.Close)
+	}
+
+	var statsRepo stats.Repo
+	if cfg.Stats && cfg.Memory {
+		statsRepo = statsrepo.NewMemory()
+	} else if cfg.Stats {
+		dbPath := filepath.Join(dataDir, cfg.StatsRepoPebble.Path)
+		statsRepo, err = statsrepo.NewPebble(dbPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating stats repo")
+		}
+	}
+	if statsRepo != nil {
+		cleanups = append(cleanups, statsRepo.Close)
+	}
+
 	// Restore the last height.
 	previousHeight, err := blockRepo.Load()
 	if err != nil {
@@ -120,14 +295,77 @@ func New(cfg config.Config) (*ClaimTrie, error) {
 		temporalRepo: temporalRepo,
 
 		nodeManager: nodeManager,
+		nodeRepo:    nodeRepo,
 		merkleTrie:  trie,
+		claimIDRepo: claimIDRepo,
+
+		takeoverRepo: takeoverRepo,
+		chainRepo:    chainRepo,
+		channelRepo:  channelRepo,
+		rankingRepo:  rankingRepo,
+		statsRepo:    statsRepo,
+		pruneDepth:   cfg.PruneDepth,
 
 		height: previousHeight,
 	}
 
 	ct.cleanups = cleanups
 
-	if previousHeight > 0 {
+	if claimIDRepo != nil {
+		err = ct.catchUpClaimIDIndex(cfg.Interrupt)
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "catch up claim ID index")
+		}
+	}
+
+	if takeoverRepo != nil {
+		err = ct.catchUpTakeoverIndex(cfg.Interrupt)
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "catch up takeover index")
+		}
+	}
+
+	if chainRepo != nil {
+		err = ct.catchUpChangeIndex(cfg.Interrupt)
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "catch up change index")
+		}
+	}
+
+	if channelRepo != nil {
+		err = ct.trimChannelIndex()
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "trim channel index")
+		}
+	}
+
+	if rankingRepo != nil {
+		err = ct.catchUpRankings(cfg.Interrupt)
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "catch up rankings")
+		}
+	}
+
+	if statsRepo != nil {
+		err = ct.catchUpStats(cfg.Interrupt)
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "catch up stats")
+		}
+	}
+
+	if previousHeight > 0 && cfg.SkipTrieRestore {
+		_, err = nodeManager.IncrementHeightTo(previousHeight)
+		if err != nil {
+			ct.Close()
+			return nil, errors.Wrap(err, "increment height to")
+		}
+	} else if previousHeight > 0 {
 		hash, err := blockRepo.Get(previousHeight)
 		if err != nil {
 			ct.Close() // TODO: the cleanups aren't run when we exit with an err above here (but should be)
@@ -147,6 +385,9 @@ func New(cfg config.Config) (*ClaimTrie, error) {
 			ct.Close()
 			return nil, errors.Errorf("unable to restore the claim hash to %s at height %d", hash.String(), previousHeight)
 		}
+		ct.committedHash = hash
+	} else if previousHeight == 0 {
+		ct.committedHash = ct.MerkleHash()
 	}
 
 	return ct, nil
@@ -246,6 +487,7 @@ func (ct *ClaimTrie) AppendBlock() error {
 	names = append(names, expirations...)
 	names = removeDuplicates(names)
 
+	ct.committedHash = nil
 	nhns := ct.makeNameHashNext(names, false, nil)
 	for nhn := range nhns {
 
@@ -269,12 +511,122 @@ func (ct *ClaimTrie) AppendBlock() error {
 
 	h := ct.MerkleHash()
 	ct.blockRepo.Set(ct.height, h)
+	ct.committedHash = h
 
 	if hitFork {
 		err = ct.merkleTrie.SetRoot(h) // for clearing the memory entirely
+		if err != nil {
+			return errors.Wrap(err, "merkle trie clear memory")
+		}
+	}
+
+	ct.appendIndexes(names)
+
+	if ct.pruneDepth > 0 {
+		err = ct.prune(ct.height - ct.pruneDepth)
+	}
+
+	return errors.Wrap(err, "prune")
+}
+
+// appendIndexes brings the optional indexes up to the current height once its hash is committed. An index
+// that fails to is disabled rather than failing the block, which is fine without it.
+func (ct *ClaimTrie) appendIndexes(names [][]byte) {
+
+	if ct.statsRepo != nil {
+		if err := ct.updateStats(names, ct.height-1, ct.height); err != nil {
+			disableIndex("statistics", err, ct.statsRepo.Clear)
+			ct.statsRepo = nil
+		}
+	}
+
+	if ct.rankingRepo != nil {
+		if err := ct.updateRankings(names, ct.height); err != nil {
+			disableIndex("ranking index", err, func() error { return ct.rankingRepo.SetHeight(-1) })
+			ct.rankingRepo = nil
+		}
+	}
+
+	if ct.claimIDRepo != nil {
+		if err := ct.appendClaimIDs(names); err != nil {
+			disableIndex("claim ID index", err, func() error { return ct.claimIDRepo.SetHeight(-1) })
+			ct.claimIDRepo = nil
+		}
+	}
+
+	if ct.takeoverRepo != nil {
+		if err := ct.appendTakeovers(names); err != nil {
+			disableIndex("takeover index", err, func() error { return ct.takeoverRepo.SetHeight(-1) })
+			ct.takeoverRepo = nil
+		}
+	}
+
+	if ct.chainRepo != nil {
+		if err := ct.appendChanges(names); err != nil {
+			disableIndex("change index", err, func() error { return ct.chainRepo.SetHeight(-1) })
+			ct.chainRepo = nil
+		}
+	}
+}
+
+// disableIndex logs why an optional index couldn't be kept up to date, and marks it to be rebuilt when the
+// ClaimTrie is opened again; until then, it's left behind.
+func disableIndex(index string, err error, invalidate func() error) {
+
+	node.Warn(fmt.Sprintf("Disabling the claimtrie %s until the next start: %s", index, err))
+	if err = invalidate(); err != nil {
+		node.Warn(fmt.Sprintf("Unable to mark the claimtrie %s for a rebuild: %s", index, err))
+	}
+}
+
+// prune drops the history below the height, which must not go down. The nodes of the names changed since
+// the last time are folded into snapshots at the height, along with all of them the first time.
+func (ct *ClaimTrie) prune(height int32) error {
+
+	pruned := ct.nodeManager.PrunedHeight()
+	// changes before the normalization fork get copied at the fork with their original heights
+	if height <= pruned || height < param.ActiveParams.NormalizedNameForkHeight {
+		return nil
+	}
+
+	var err error
+	if pruned == 0 {
+		node.LogOnce(fmt.Sprintf("Pruning the claimtrie history below height %d...", height))
+		var names [][]byte
+		ct.nodeManager.IterateNames(func(name []byte) bool {
+			clone := make([]byte, len(name))
+			copy(clone, name) // iteration name buffer is reused on future loops
+			names = append(names, clone)
+			if len(names) < 10000 {
+				return true
+			}
+			err = ct.nodeManager.Prune(height, names)
+			names = names[:0]
+			return err == nil
+		})
+		if err == nil {
+			err = ct.nodeManager.Prune(height, names)
+		}
+	} else {
+		var names [][]byte
+		for h := pruned + 1; h <= height; h++ {
+			results, err := ct.temporalRepo.NodesAt(h)
+			if err != nil {
+				return errors.Wrap(err, "temporal repo get")
+			}
+			names = append(names, results...)
+		}
+		err = ct.nodeManager.Prune(height, removeDuplicates(names))
+	}
+	if err != nil {
+		return errors.Wrap(err, "node manager prune")
 	}
 
-	return errors.Wrap(err, "merkle trie clear memory")
+	// ResetHeight needs the names of the heights above the one it resets to, and the hash at it
+	if err = ct.temporalRepo.DropBefore(height + 1); err != nil {
+		return errors.Wrap(err, "temporal repo drop")
+	}
+	return errors.Wrap(ct.blockRepo.DropBefore(height), "block repo drop")
 }
 
 func (ct *ClaimTrie) updateTrieForHashForkIfNecessary() bool {
@@ -303,6 +655,16 @@ func removeDuplicates(names [][]byte) [][]byte { // this might be too expensive;
 // ResetHeight resets the ClaimTrie to a previous known height..
 func (ct *ClaimTrie) ResetHeight(height int32) error {
 
+	if pruned := ct.nodeManager.PrunedHeight(); height < pruned {
+		return errors.Wrapf(node.ErrPruned, "unable to reset to height %d below %d", height, pruned)
+	}
+
+	// an imported ClaimTrie only has the hashes of the blocks exported with it; check before changing anything
+	hash, err := ct.blockRepo.Get(height)
+	if err != nil {
+		return errors.Wrapf(err, "unable to reset to height %d", height)
+	}
+
 	names := make([][]byte, 0)
 	for h := height + 1; h <= ct.height; h++ {
 		results, err := ct.temporalRepo.NodesAt(h)
@@ -311,22 +673,90 @@ func (ct *ClaimTrie) ResetHeight(height int32) error {
 		}
 		names = append(names, results...)
 	}
-	err := ct.nodeManager.DecrementHeightTo(names, height)
-	if err != nil {
-		return err
+
+	// the stats need the nodes from before the decrement
+	var before []stats.Stats
+	expirations := map[int32]int64{}
+	if ct.statsRepo != nil {
+		names = removeDuplicates(names)
+		for _, name := range names {
+			n, err := ct.nodeManager.NodeAt(ct.height, name)
+			if err != nil {
+				return errors.Wrap(err, "node before reset")
+			}
+			before = append(before, stats.Of(n))
+			if h := stats.ExpireAt(n); h > 0 {
+				expirations[h]--
+			}
+		}
 	}
 
-	passedHashFork := ct.height >= param.ActiveParams.AllClaimsInMerkleForkHeight && height < param.ActiveParams.AllClaimsInMerkleForkHeight
-	hash, err := ct.blockRepo.Get(height)
+	err = ct.nodeManager.DecrementHeightTo(names, height)
 	if err != nil {
 		return err
 	}
 
+	if ct.statsRepo != nil {
+		for i, name := range names {
+			n, err := ct.nodeManager.NodeAt(height, name)
+			if err != nil {
+				return errors.Wrap(err, "node after reset")
+			}
+			ct.stats.Sub(before[i])
+			ct.stats.Add(stats.Of(n))
+			if h := stats.ExpireAt(n); h > 0 {
+				expirations[h]++
+			}
+		}
+		if err = ct.statsRepo.Set(height, ct.stats, expirations); err != nil {
+			return errors.Wrap(err, "stats repo set")
+		}
+	}
+
+	if ct.rankingRepo != nil {
+		if err = ct.updateRankings(removeDuplicates(names), height); err != nil {
+			return errors.Wrap(err, "ranking repo set")
+		}
+	}
+
+	if ct.claimIDRepo != nil {
+		if err = ct.trimClaimIDIndexTo(height); err != nil {
+			return errors.Wrap(err, "claim ID repo drop")
+		}
+	}
+
+	if ct.takeoverRepo != nil {
+		if err = ct.takeoverRepo.DropAfter(height); err != nil {
+			return errors.Wrap(err, "takeover repo drop")
+		}
+		if err = ct.takeoverRepo.SetHeight(height); err != nil {
+			return errors.Wrap(err, "takeover repo set height")
+		}
+	}
+
+	if ct.chainRepo != nil {
+		if err = ct.chainRepo.DropAfter(height); err != nil {
+			return errors.Wrap(err, "chain repo drop")
+		}
+		if err = ct.chainRepo.SetHeight(height); err != nil {
+			return errors.Wrap(err, "chain repo set height")
+		}
+	}
+
+	if ct.channelRepo != nil {
+		if err = ct.trimChannelIndexTo(height); err != nil {
+			return errors.Wrap(err, "channel repo drop")
+		}
+	}
+
+	passedHashFork := ct.height >= param.ActiveParams.AllClaimsInMerkleForkHeight && height < param.ActiveParams.AllClaimsInMerkleForkHeight
+
 	ct.height = height // keep this before the rebuild
 
 	if passedHashFork {
 		names = nil // force them to reconsider all names
 	}
+	ct.committedHash = nil
 	err = ct.merkleTrie.SetRoot(hash)
 	if err == merkletrie.ErrFullRebuildRequired {
 		ct.runFullTrieRebuild(names, nil)
@@ -335,6 +765,7 @@ func (ct *ClaimTrie) ResetHeight(height int32) error {
 	if !ct.MerkleHash().IsEqual(hash) {
 		return errors.Errorf("unable to restore the hash at height %d", height)
 	}
+	ct.committedHash = hash
 	return nil
 }
 
@@ -370,6 +801,11 @@ func (ct *ClaimTrie) Height() int32 {
 // Any calls to the ClaimTrie after Close() being called results undefined behaviour.
 func (ct *ClaimTrie) Close() {
 
+	if hits, misses := ct.NodeCacheStats(); hits+misses > 0 {
+		node.LogOnce(fmt.Sprintf("Node cache hit rate: %.1f%% of %d lookups",
+			100*float64(hits)/float64(hits+misses), hits+misses))
+	}
+
 	for i := len(ct.cleanups) - 1; i >= 0; i-- {
 		cleanup := ct.cleanups[i]
 		err := cleanup()
@@ -391,56 +827,929 @@ func (ct *ClaimTrie) NodeAt(height int32, name []byte) (*node.Node, error) {
 	return ct.nodeManager.NodeAt(height, name)
 }
 
-func (ct *ClaimTrie) NamesChangedInBlock(height int32) ([]string, error) {
-	hits, err := ct.temporalRepo.NodesAt(height)
-	r := make([]string, len(hits))
-	for i := range hits {
-		r[i] = string(hits[i])
+// NodeWithPending returns the node of the (normalized) name at the current height with those of the pending
+// changes that apply to it, such as the ones of unconfirmed transactions, applied as if they were in the next block.
+// The pending changes must be in order.
+func (ct *ClaimTrie) NodeWithPending(name []byte, pending []change.Change) (*node.Node, error) {
+
+	var changes []change.Change
+	for _, chg := range pending {
+		chg.Height = ct.height + 1
+		chg.Name = normalization.NormalizeIfNecessary(chg.Name, chg.Height)
+		if bytes.Equal(chg.Name, name) {
+			changes = append(changes, chg)
+		}
 	}
-	return r, err
+	return ct.nodeManager.NodeWithChanges(ct.height, name, changes)
 }
 
-func (ct *ClaimTrie) FlushToDisk() {
-	// maybe the user can fix the file lock shown in the warning before they shut down
-	if err := ct.nodeManager.Flush(); err != nil {
-		node.Warn("During nodeManager flush: " + err.Error())
+// ListNames calls f, in byte order, with the node of each (normalized) name that starts with prefix, is not
+// before start, and has a controlling claim at the specified height. Return false on f to stop the iteration.
+func (ct *ClaimTrie) ListNames(height int32, prefix, start []byte, f func(name []byte, n *node.Node) bool) error {
+
+	var err error
+	ct.nodeManager.IterateNamesFrom(prefix, start, func(key []byte) bool {
+		name := make([]byte, len(key)) // the key is only valid until we return
+		copy(name, key)
+
+		var n *node.Node
+		n, err = ct.nodeManager.NodeAt(height, name)
+		if err != nil {
+			err = errors.Wrapf(err, "node at %s", name)
+			return false
+		}
+		if n == nil || !n.HasActiveBestClaim() {
+			return true
+		}
+		return f(name, n)
+	})
+	return err
+}
+
+// History returns every change made to the node up to (includes) the specified height,
+// along with the state computed for each of them.
+func (ct *ClaimTrie) History(height int32, name []byte) ([]node.HistoryEntry, error) {
+	return ct.nodeManager.History(height, name)
+}
+
+// NameProof returns a proof for the claim with the given ID, or for the winning claim when id is nil,
+// against the current MerkleHash. Without an ID, a name with no winning claim gets a proof of non-existence
+// before the AllClaimsInMerkle fork; later hashes don't commit to the names, so it's rejected from then on.
+func (ct *ClaimTrie) NameProof(name []byte, id *change.ClaimID) (*proof.Proof, error) {
+
+	// the tries only produce proofs for computed hashes, and computing them here would race other readers
+	if ct.committedHash == nil {
+		return nil, errors.Errorf("the trie hash at height %d is not available", ct.height)
 	}
-	if err := ct.temporalRepo.Flush(); err != nil {
-		node.Warn("During temporalRepo flush: " + err.Error())
+
+	n, err := ct.nodeManager.NodeAt(ct.height, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "in node at")
 	}
-	if err := ct.merkleTrie.Flush(); err != nil {
-		node.Warn("During merkleTrie flush: " + err.Error())
+
+	p := &proof.Proof{
+		Name:              name,
+		AllClaimsInMerkle: ct.height >= param.ActiveParams.AllClaimsInMerkleForkHeight,
 	}
-	if err := ct.blockRepo.Flush(); err != nil {
-		node.Warn("During blockRepo flush: " + err.Error())
+
+	if !p.AllClaimsInMerkle {
+		if n != nil && n.HasActiveBestClaim() {
+			if id != nil && *id != n.BestClaim.ClaimID {
+				return nil, errors.Errorf("only the winning claim can be proven before height %d",
+					param.ActiveParams.AllClaimsInMerkleForkHeight)
+			}
+			op := n.BestClaim.OutPoint
+			p.OutPoint = &op
+			p.TakeoverHeight = n.TakenOverAt
+		} else if id != nil {
+			return nil, errors.Errorf("claim %s is not active on %s", id, name)
+		}
+		p.Nodes, err = ct.merkleTrie.Proof(name)
+		return p, errors.Wrap(err, "in trie proof")
 	}
-}
 
-type NameHashNext struct {
-	Name []byte
-	Hash *chainhash.Hash
-	Next int32
+	if n == nil || !n.HasActiveBestClaim() {
+		if id != nil {
+			return nil, errors.Errorf("claim %s is not active on %s", id, name)
+		}
+		return nil, errors.Errorf("%s has no winning claim to prove; the trie doesn't commit to absent names "+
+			"from height %d", name, param.ActiveParams.AllClaimsInMerkleForkHeight)
+	}
+
+	// this matches the claim hashes computed by the HashV2Manager
+	n.SortClaimsByBid()
+	index := -1
+	claimHashes := make([]*chainhash.Hash, 0, len(n.Claims))
+	for _, c := range n.Claims {
+		if c.Status != node.Activated {
+			continue
+		}
+		if (id == nil && c.ClaimID == n.BestClaim.ClaimID) || (id != nil && c.ClaimID == *id) {
+			index = len(claimHashes)
+			op := c.OutPoint
+			p.OutPoint = &op
+			p.TakeoverHeight = n.TakenOverAt
+		}
+		claimHashes = append(claimHashes, proof.ClaimHash(c.OutPoint, n.TakenOverAt))
+	}
+	if index < 0 {
+		return nil, errors.Errorf("claim %s is not active on %s", id, name)
+	}
+
+	pairs, err := ct.merkleTrie.ProofAllClaims(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "in trie proof")
+	}
+	p.Pairs = append(proof.MerklePath(claimHashes, index), pairs...)
+	return p, nil
 }
 
-func interruptRequested(interrupted <-chan struct{}) bool {
-	select {
-	case <-interrupted: // should never block on nil
-		return true
-	default:
+// Claim returns the latest record of a claim in the claim ID index, or nil if the claim is unknown.
+func (ct *ClaimTrie) Claim(id change.ClaimID) (*claimid.Claim, error) {
+	if ct.claimIDRepo == nil {
+		return nil, ErrNoClaimIDIndex
 	}
+	return ct.claimIDRepo.Get(id)
+}
 
-	return false
+// toClaimIDRecord updates the record of a claim, or starts one, with the claim as it is in the node of name.
+func toClaimIDRecord(previous *claimid.Claim, name []byte, height int32, c *node.Claim) claimid.Claim {
+	r := claimid.Claim{Name: name, Created: height}
+	if previous != nil {
+		r.Name, r.Created = previous.Name, previous.Created
+	}
+	r.OutPoint, r.Amount, r.Status = c.OutPoint, c.Amount, claimid.Accepted
+	if c.Status == node.Activated {
+		r.Status = claimid.Activated
+	}
+	return r
 }
 
-func (ct *ClaimTrie) makeNameHashNext(names [][]byte, all bool, interrupt <-chan struct{}) chan NameHashNext {
+// appendClaimIDs records the claims of the names that changed in the block, from the nodes before and after it.
+func (ct *ClaimTrie) appendClaimIDs(names [][]byte) error {
+
+	var ids []change.ClaimID
+	records := map[change.ClaimID]claimid.Claim{}
+	for _, name := range names {
+		before, err := ct.nodeManager.NodeAt(ct.height-1, name)
+		if err != nil {
+			return err
+		}
+		after, err := ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			return err
+		}
+
+		previous := map[change.ClaimID]*node.Claim{}
+		if before != nil {
+			for _, c := range before.Claims {
+				previous[c.ClaimID] = c
+			}
+		}
+		if after != nil {
+			for _, c := range after.Claims {
+				p := previous[c.ClaimID]
+				delete(previous, c.ClaimID)
+				if p != nil && p.OutPoint == c.OutPoint && p.Amount == c.Amount && p.Status == c.Status {
+					continue
+				}
+				record, err := ct.claimIDRepo.Get(c.ClaimID)
+				if err != nil {
+					return err
+				}
+				if _, ok := records[c.ClaimID]; !ok {
+					ids = append(ids, c.ClaimID)
+				}
+				records[c.ClaimID] = toClaimIDRecord(record, name, ct.height, c)
+			}
+		}
+
+		// what's left was spent or expired, unless it moved to another name, such as at the normalization fork
+		for id := range previous {
+			if _, ok := records[id]; ok {
+				continue
+			}
+			record, err := ct.claimIDRepo.Get(id)
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				record.Status = claimid.Removed
+				ids = append(ids, id)
+				records[id] = *record
+			}
+		}
+	}
+
+	claims := make([]claimid.Claim, len(ids))
+	for i, id := range ids {
+		claims[i] = records[id]
+	}
+	if err := ct.claimIDRepo.Set(ct.height, ids, claims); err != nil {
+		return err
+	}
+	return ct.claimIDRepo.SetHeight(ct.height)
+}
+
+// trimClaimIDIndexTo undoes the changes to the claim ID index after height, or builds it again at height
+// when it can't undo them, such as when it was just built. The nodes must have been reset to height already.
+func (ct *ClaimTrie) trimClaimIDIndexTo(height int32) error {
+	base, err := ct.claimIDRepo.Base()
+	if err != nil {
+		return err
+	}
+	if height < base {
+		return ct.buildClaimIDIndex(height, nil)
+	}
+	if err = ct.claimIDRepo.DropAfter(height); err != nil {
+		return err
+	}
+	return ct.claimIDRepo.SetHeight(height)
+}
+
+// catchUpClaimIDIndex rebuilds the claim ID index when it doesn't match the rest of the ClaimTrie,
+// such as when it was just enabled.
+func (ct *ClaimTrie) catchUpClaimIDIndex(interrupt <-chan struct{}) error {
+	height, err := ct.claimIDRepo.Height()
+	if err != nil || height == ct.height {
+		return err
+	}
+	return ct.buildClaimIDIndex(ct.height, interrupt)
+}
+
+// buildClaimIDIndex records every claim as it is at height: those created from the node repo, with their latest
+// versions taken from the updates, then those in the trie from their nodes. The changes made up to height can't
+// be undone.
+func (ct *ClaimTrie) buildClaimIDIndex(height int32, interrupt <-chan struct{}) error {
+
+	if ct.nodeManager.PrunedHeight() > 0 {
+		return errors.Wrap(node.ErrPruned, "the claim ID index needs all of it")
+	}
+
+	node.LogOnce("Building the claim ID index...")
+	if err := ct.claimIDRepo.Clear(height); err != nil {
+		return err
+	}
+
+	var ids []change.ClaimID
+	var claims []claimid.Claim
+	set := func() error {
+		if len(ids) == 0 {
+			return nil
+		}
+		err := ct.claimIDRepo.Set(height, ids, claims)
+		ids, claims = ids[:0], claims[:0]
+		return err
+	}
+
+	// the updates are read after all the claims are in, since they can be under a different name after the
+	// normalization fork; the copies made at the fork are skipped, as the originals have the right heights.
+	// Nothing at height or below can be undone, so no undo is kept for it.
+	for _, updates := range []bool{false, true} {
+		var innerErr error
+		err := ct.nodeRepo.IterateChildren(nil, func(changes []change.Change) bool {
+			for _, chg := range changes {
+				if chg.VisibleHeight > 0 || chg.Height > height {
+					continue
+				}
+				var c claimid.Claim
+				if !updates && chg.Type == change.AddClaim {
+					name := make([]byte, len(chg.Name))
+					copy(name, chg.Name) // iteration name buffer is reused on future loops
+					c = claimid.Claim{Name: name, Created: chg.Height}
+				} else if updates && chg.Type == change.UpdateClaim {
+					record, err := ct.claimIDRepo.Get(chg.ClaimID)
+					if err != nil {
+						innerErr = err
+						return false
+					}
+					if record == nil {
+						continue
+					}
+					c = *record
+				} else {
+					continue
+				}
+				c.OutPoint, c.Amount, c.Status = chg.OutPoint, chg.Amount, claimid.Removed
+				ids = append(ids, chg.ClaimID)
+				claims = append(claims, c)
+			}
+			if len(ids) < 10000 {
+				return !interruptRequested(interrupt)
+			}
+			innerErr = set()
+			return innerErr == nil && !interruptRequested(interrupt)
+		})
+		if err == nil {
+			err = innerErr
+		}
+		if err == nil {
+			err = set()
+		}
+		if err == nil && interruptRequested(interrupt) {
+			err = errors.New("interrupted")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var innerErr error
+	ct.nodeManager.IterateNames(func(name []byte) bool {
+		var n *node.Node
+		n, innerErr = ct.nodeManager.NodeAt(height, name)
+		if innerErr != nil || n == nil {
+			return innerErr == nil
+		}
+		for _, c := range n.Claims {
+			var record *claimid.Claim
+			record, innerErr = ct.claimIDRepo.Get(c.ClaimID)
+			if innerErr != nil {
+				return false
+			}
+			clone := make([]byte, len(name))
+			copy(clone, name) // iteration name buffer is reused on future loops
+			ids = append(ids, c.ClaimID)
+			claims = append(claims, toClaimIDRecord(record, clone, height, c))
+		}
+		if len(ids) < 10000 {
+			return !interruptRequested(interrupt)
+		}
+		innerErr = set()
+		return innerErr == nil && !interruptRequested(interrupt)
+	})
+	err := innerErr
+	if err == nil {
+		err = set()
+	}
+	if err == nil && interruptRequested(interrupt) {
+		err = errors.New("interrupted")
+	}
+	if err != nil {
+		return err
+	}
+	return ct.claimIDRepo.SetHeight(height)
+}
+
+// Takeovers returns the takeovers of name between the heights, inclusive, from the takeover index.
+func (ct *ClaimTrie) Takeovers(name []byte, fromHeight, toHeight int32) ([]node.Takeover, error) {
+	if ct.takeoverRepo == nil {
+		return nil, ErrNoTakeoverIndex
+	}
+	return ct.takeoverRepo.Takeovers(name, fromHeight, toHeight)
+}
+
+// appendTakeovers records the takeovers that happened at the current height on any of the names.
+func (ct *ClaimTrie) appendTakeovers(names [][]byte) error {
+	var takenOver [][]byte
+	var takeovers []node.Takeover
+	for _, name := range names {
+		t, err := ct.takeoverAt(ct.height, name)
+		if err != nil {
+			return err
+		}
+		if t != nil {
+			takenOver = append(takenOver, name)
+			takeovers = append(takeovers, *t)
+		}
+	}
+	if err := ct.takeoverRepo.Set(takenOver, takeovers); err != nil {
+		return err
+	}
+	return ct.takeoverRepo.SetHeight(ct.height)
+}
+
+// takeoverAt returns the takeover of name at height, if there was one, from the nodes before and at the height.
+// The catch-up replays the changes of each name instead, but both make the takeovers with node.TakeoverAt.
+func (ct *ClaimTrie) takeoverAt(height int32, name []byte) (*node.Takeover, error) {
+	before, err := ct.nodeManager.NodeAt(height-1, name)
+	if err != nil {
+		return nil, err
+	}
+	after, err := ct.nodeManager.NodeAt(height, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous change.ClaimID
+	if before != nil && before.HasActiveBestClaim() {
+		previous = before.BestClaim.ClaimID
+	}
+	return node.TakeoverAt(height, previous, after), nil
+}
+
+// catchUpTakeoverIndex rebuilds the takeover index from the node repo when it
+// doesn't match the rest of the ClaimTrie, such as when it was just enabled.
+func (ct *ClaimTrie) catchUpTakeoverIndex(interrupt <-chan struct{}) error {
+	height, err := ct.takeoverRepo.Height()
+	if err != nil || height == ct.height {
+		return err
+	}
+
+	if ct.nodeManager.PrunedHeight() > 0 {
+		return errors.Wrap(node.ErrPruned, "the takeover index needs all of it")
+	}
+
+	node.LogOnce("Building the takeover index...")
+	if err = ct.takeoverRepo.DropAfter(-1); err != nil {
+		return err
+	}
+
+	var names [][]byte
+	var takeovers []node.Takeover
+	ct.nodeManager.IterateNames(func(name []byte) bool {
+		var ts []node.Takeover
+		ts, err = ct.nodeManager.Takeovers(ct.height, name)
+		if err != nil {
+			return false
+		}
+		for range ts {
+			clone := make([]byte, len(name))
+			copy(clone, name) // iteration name buffer is reused on future loops
+			names = append(names, clone)
+		}
+		takeovers = append(takeovers, ts...)
+		if len(takeovers) < 10000 {
+			return !interruptRequested(interrupt)
+		}
+		err = ct.takeoverRepo.Set(names, takeovers)
+		names, takeovers = names[:0], takeovers[:0]
+		return err == nil && !interruptRequested(interrupt)
+	})
+	if err == nil {
+		err = ct.takeoverRepo.Set(names, takeovers)
+	}
+	if err == nil && interruptRequested(interrupt) {
+		err = errors.New("interrupted")
+	}
+	if err != nil {
+		return err
+	}
+	return ct.takeoverRepo.SetHeight(ct.height)
+}
+
+// Changes returns the changes the block at height made to the claims and supports, from the change index.
+func (ct *ClaimTrie) Changes(height int32) ([]change.Change, error) {
+	if ct.chainRepo == nil {
+		return nil, ErrNoChangeIndex
+	}
+	if height > ct.height {
+		return nil, errors.Errorf("height %d is past the tip %d", height, ct.height)
+	}
+	return ct.chainRepo.Load(height)
+}
+
+// appendChanges records the changes the current block made to the names it touched.
+func (ct *ClaimTrie) appendChanges(names [][]byte) error {
+	changes, err := ct.blockChanges(ct.height, names)
+	if err != nil {
+		return err
+	}
+	if err = ct.chainRepo.Save(ct.height, changes); err != nil {
+		return err
+	}
+	return ct.chainRepo.SetHeight(ct.height)
+}
+
+// catchUpChangeIndex rebuilds the change index from the node repo when it
+// doesn't match the rest of the ClaimTrie, such as when it was just enabled.
+func (ct *ClaimTrie) catchUpChangeIndex(interrupt <-chan struct{}) error {
+	height, err := ct.chainRepo.Height()
+	if err != nil || height == ct.height {
+		return err
+	}
+
+	if ct.nodeManager.PrunedHeight() > 0 {
+		return errors.Wrap(node.ErrPruned, "the change index needs all of it")
+	}
+
+	node.LogOnce("Building the claimtrie change index...")
+	if err = ct.chainRepo.DropAfter(-1); err != nil {
+		return err
+	}
+
+	for h := int32(1); h <= ct.height && !interruptRequested(interrupt); h++ {
+		names, err := ct.temporalRepo.NodesAt(h)
+		if err != nil {
+			return errors.Wrap(err, "temporal repo get")
+		}
+		changes, err := ct.blockChanges(h, removeDuplicates(names))
+		if err != nil {
+			return err
+		}
+		if err = ct.chainRepo.Save(h, changes); err != nil {
+			return err
+		}
+	}
+	if interruptRequested(interrupt) {
+		return errors.New("interrupted")
+	}
+	return ct.chainRepo.SetHeight(ct.height)
+}
+
+// ChannelIndexEnabled returns whether the ClaimTrie has a channel index to keep up to date.
+func (ct *ClaimTrie) ChannelIndexEnabled() bool {
+	return ct.channelRepo != nil
+}
+
+// DisableChannelIndex stops keeping the channel index up to date after it failed to be, and marks it to be
+// rebuilt when the chain is loaded again.
+func (ct *ClaimTrie) DisableChannelIndex(err error) {
+	if ct.channelRepo == nil {
+		return
+	}
+	disableIndex("channel index", err, func() error {
+		if err := ct.channelRepo.DropAfter(-1); err != nil {
+			return err
+		}
+		return ct.channelRepo.SetHeight(-1)
+	})
+	ct.channelRepo = nil
+}
+
+// ChannelIndexHeight returns the height the channel index has been built to, which is behind the ClaimTrie
+// when the index was enabled after it.
+func (ct *ClaimTrie) ChannelIndexHeight() (int32, error) {
+	if ct.channelRepo == nil {
+		return 0, ErrNoChannelIndex
+	}
+	return ct.channelRepo.Height()
+}
+
+// SetChannelIndexHeight records the height the channel index has caught up to.
+func (ct *ClaimTrie) SetChannelIndexHeight(height int32) error {
+	if ct.channelRepo == nil {
+		return ErrNoChannelIndex
+	}
+	return ct.channelRepo.SetHeight(height)
+}
+
+// ChannelKey returns the public key of a channel, or nil if it's not in the channel index.
+func (ct *ClaimTrie) ChannelKey(id change.ClaimID) ([]byte, error) {
+	if ct.channelRepo == nil {
+		return nil, ErrNoChannelIndex
+	}
+	return ct.channelRepo.Key(id)
+}
+
+// SetChannelKey records the public key of a channel as of height, or that it's no longer one when the key is nil.
+func (ct *ClaimTrie) SetChannelKey(height int32, id change.ClaimID, key []byte) error {
+	if ct.channelRepo == nil {
+		return ErrNoChannelIndex
+	}
+	return ct.channelRepo.SetKey(height, id, key)
+}
+
+// SetChannelMember records the channel that validly signed the latest version of a claim as of height.
+// A zero channel takes the claim out of the one it was in.
+func (ct *ClaimTrie) SetChannelMember(height int32, ch change.ClaimID, m channel.Member) error {
+	if ct.channelRepo == nil {
+		return ErrNoChannelIndex
+	}
+	return ct.channelRepo.SetMember(height, ch, m)
+}
+
+// ChannelMembers returns up to limit stream claims of the channel, after skipping the first start of them,
+// in the order they joined it.
+func (ct *ClaimTrie) ChannelMembers(ch change.ClaimID, start, limit int) ([]channel.Member, error) {
+	if ct.channelRepo == nil {
+		return nil, ErrNoChannelIndex
+	}
+	return ct.channelRepo.Members(ch, start, limit)
+}
+
+// trimChannelIndex undoes what the channel index has past the ClaimTrie, such as when the ClaimTrie was
+// reset while the index was disabled. What it's missing is left for the one parsing the blocks to catch up on.
+func (ct *ClaimTrie) trimChannelIndex() error {
+	height, err := ct.channelRepo.Height()
+	if err != nil {
+		return err
+	}
+	if height < 0 && ct.height == 0 {
+		return ct.channelRepo.SetHeight(0) // a new index of a new ClaimTrie has nothing to catch up on
+	}
+	if height <= ct.height {
+		return nil
+	}
+	return ct.trimChannelIndexTo(ct.height)
+}
+
+func (ct *ClaimTrie) trimChannelIndexTo(height int32) error {
+	indexHeight, err := ct.channelRepo.Height()
+	if err != nil {
+		return err
+	}
+	if err = ct.channelRepo.DropAfter(height); err != nil {
+		return err
+	}
+	if indexHeight > height {
+		return ct.channelRepo.SetHeight(height)
+	}
+	return nil
+}
+
+// maxRankedDepth is the number of blocks below the current height that TopRanked goes back to;
+// the names that changed since then are ranked apart.
+const maxRankedDepth = 100
+
+// TopRanked returns up to limit of the claims, or of the names when byName is set, in the order they rank at
+// the height, skipping the names without the (normalized) prefix and stopping at those below minAmount.
+// The effective amount of a name is that of its controlling claim. The height must be no more than
+// maxRankedDepth blocks below the current height.
+func (ct *ClaimTrie) TopRanked(height int32, byName bool, prefix []byte, minAmount int64, limit int) ([]ranking.Entry, error) {
+	if ct.rankingRepo == nil {
+		return nil, ErrNoRankingIndex
+	}
+	low := ct.height - maxRankedDepth
+	if low < 0 {
+		low = 0
+	}
+	if height < low || height > ct.height {
+		return nil, errors.Errorf("height %d is not in [%d, %d]", height, low, ct.height)
+	}
+	if pruned := ct.nodeManager.PrunedHeight(); height < pruned {
+		return nil, errors.Wrapf(node.ErrPruned, "unable to rank at height %d below %d", height, pruned)
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	// the rankings are those of the current height, so the names that changed since are ranked apart
+	changed := map[string]bool{}
+	var recomputed []ranking.Entry
+	for h := height + 1; h <= ct.height; h++ {
+		names, err := ct.temporalRepo.NodesAt(h)
+		if err != nil {
+			return nil, errors.Wrap(err, "temporal repo get")
+		}
+		for _, name := range names {
+			if changed[string(name)] {
+				continue
+			}
+			changed[string(name)] = true
+			if !bytes.HasPrefix(name, prefix) {
+				continue
+			}
+			n, err := ct.nodeManager.NodeAt(height, name)
+			if err != nil {
+				return nil, errors.Wrap(err, "node manager get")
+			}
+			r := ranking.Of(name, n)
+			if !byName {
+				recomputed = append(recomputed, r.Claims...)
+			} else if r.Best != nil {
+				recomputed = append(recomputed, *r.Best)
+			}
+		}
+	}
+	ranking.Sort(recomputed)
+
+	var top []ranking.Entry
+	add := func(e ranking.Entry) bool {
+		if e.Amount < minAmount {
+			return false
+		}
+		top = append(top, e)
+		return len(top) < limit
+	}
+
+	iterate := ct.rankingRepo.IterateClaims
+	if byName {
+		iterate = ct.rankingRepo.IterateNames
+	}
+	done := false
+	err := iterate(prefix, func(e ranking.Entry) bool {
+		if changed[string(e.Name)] {
+			return true
+		}
+		for len(recomputed) > 0 && ranking.Less(recomputed[0], e) {
+			next := recomputed[0]
+			recomputed = recomputed[1:]
+			if !add(next) {
+				done = true
+				return false
+			}
+		}
+		done = !add(e)
+		return !done
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ranking repo iterate")
+	}
+	for ; !done && len(recomputed) > 0; recomputed = recomputed[1:] {
+		done = !add(recomputed[0])
+	}
+	return top, nil
+}
+
+// Stats returns the aggregate statistics of the ClaimTrie at the current height.
+func (ct *ClaimTrie) Stats() (stats.Stats, error) {
+	if ct.statsRepo == nil {
+		return stats.Stats{}, ErrNoStats
+	}
+	return ct.stats, nil
+}
+
+// NodeCacheStats returns the number of node lookups that were served from the node cache and the number that weren't.
+func (ct *ClaimTrie) NodeCacheStats() (hits, misses uint64) {
+	return ct.nodeManager.CacheStats()
+}
+
+// ExpiringNames returns the number of names whose controlling claim expires in the blocks after the current height,
+// up to (includes) the given number of them. It's kept along with the statistics.
+func (ct *ClaimTrie) ExpiringNames(within int32) (int64, error) {
+	if ct.statsRepo == nil {
+		return 0, ErrNoStats
+	}
+	return ct.statsRepo.Expirations(ct.height+1, ct.height+within)
+}
+
+// updateStats replaces the contributions of the names at the from height with those at the to height.
+func (ct *ClaimTrie) updateStats(names [][]byte, from, to int32) error {
+	expirations := map[int32]int64{}
+	for _, name := range names {
+		before, err := ct.nodeManager.NodeAt(from, name)
+		if err != nil {
+			return err
+		}
+		after, err := ct.nodeManager.NodeAt(to, name)
+		if err != nil {
+			return err
+		}
+		ct.stats.Sub(stats.Of(before))
+		ct.stats.Add(stats.Of(after))
+		if h := stats.ExpireAt(before); h > 0 {
+			expirations[h]--
+		}
+		if h := stats.ExpireAt(after); h > 0 {
+			expirations[h]++
+		}
+	}
+	return ct.statsRepo.Set(to, ct.stats, expirations)
+}
+
+// catchUpStats recomputes the statistics from every node when they don't match
+// the rest of the ClaimTrie, such as when they were just enabled.
+func (ct *ClaimTrie) catchUpStats(interrupt <-chan struct{}) error {
+	s, height, err := ct.statsRepo.Get()
+	if err != nil || height == ct.height {
+		ct.stats = s
+		return err
+	}
+
+	node.LogOnce("Computing the claimtrie statistics...")
+	if err = ct.statsRepo.Clear(); err != nil {
+		return err
+	}
+	s = stats.Stats{}
+	expirations := map[int32]int64{}
+	ct.nodeManager.IterateNames(func(name []byte) bool {
+		var n *node.Node
+		n, err = ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			return false
+		}
+		s.Add(stats.Of(n))
+		if h := stats.ExpireAt(n); h > 0 {
+			expirations[h]++
+		}
+		return !interruptRequested(interrupt)
+	})
+	if err == nil && interruptRequested(interrupt) {
+		err = errors.New("interrupted")
+	}
+	if err != nil {
+		return err
+	}
+	ct.stats = s
+	return ct.statsRepo.Set(ct.height, s, expirations)
+}
+
+// updateRankings replaces what the names contribute to the rankings with what they do at the height.
+func (ct *ClaimTrie) updateRankings(names [][]byte, height int32) error {
+	rankings := make([]ranking.Ranking, 0, len(names))
+	for _, name := range names {
+		n, err := ct.nodeManager.NodeAt(height, name)
+		if err != nil {
+			return err
+		}
+		rankings = append(rankings, ranking.Of(name, n))
+	}
+	if err := ct.rankingRepo.Set(rankings); err != nil {
+		return err
+	}
+	return ct.rankingRepo.SetHeight(height)
+}
+
+// catchUpRankings ranks every node again when the rankings don't match
+// the rest of the ClaimTrie, such as when they were just enabled.
+func (ct *ClaimTrie) catchUpRankings(interrupt <-chan struct{}) error {
+	height, err := ct.rankingRepo.Height()
+	if err != nil || height == ct.height {
+		return err
+	}
+
+	node.LogOnce("Building the ranking index...")
+	if err = ct.rankingRepo.Clear(); err != nil {
+		return err
+	}
+
+	var rankings []ranking.Ranking
+	ct.nodeManager.IterateNames(func(name []byte) bool {
+		var n *node.Node
+		n, err = ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			return false
+		}
+		clone := make([]byte, len(name))
+		copy(clone, name) // iteration name buffer is reused on future loops
+		rankings = append(rankings, ranking.Of(clone, n))
+		if len(rankings) < 10000 {
+			return !interruptRequested(interrupt)
+		}
+		err = ct.rankingRepo.Set(rankings)
+		rankings = rankings[:0]
+		return err == nil && !interruptRequested(interrupt)
+	})
+	if err == nil {
+		err = ct.rankingRepo.Set(rankings)
+	}
+	if err == nil && interruptRequested(interrupt) {
+		err = errors.New("interrupted")
+	}
+	if err != nil {
+		return err
+	}
+	return ct.rankingRepo.SetHeight(ct.height)
+}
+
+func (ct *ClaimTrie) NamesChangedInBlock(height int32) ([]string, error) {
+	hits, err := ct.temporalRepo.NodesAt(height)
+	r := make([]string, len(hits))
+	for i := range hits {
+		r[i] = string(hits[i])
+	}
+	return r, err
+}
+
+func (ct *ClaimTrie) FlushToDisk() {
+	// maybe the user can fix the file lock shown in the warning before they shut down
+	if err := ct.nodeManager.Flush(); err != nil {
+		node.Warn("During nodeManager flush: " + err.Error())
+	}
+	if err := ct.temporalRepo.Flush(); err != nil {
+		node.Warn("During temporalRepo flush: " + err.Error())
+	}
+	if err := ct.merkleTrie.Flush(); err != nil {
+		node.Warn("During merkleTrie flush: " + err.Error())
+	}
+	if err := ct.blockRepo.Flush(); err != nil {
+		node.Warn("During blockRepo flush: " + err.Error())
+	}
+	if ct.claimIDRepo != nil {
+		if err := ct.claimIDRepo.Flush(); err != nil {
+			node.Warn("During claimIDRepo flush: " + err.Error())
+		}
+	}
+	if ct.takeoverRepo != nil {
+		if err := ct.takeoverRepo.Flush(); err != nil {
+			node.Warn("During takeoverRepo flush: " + err.Error())
+		}
+	}
+	if ct.chainRepo != nil {
+		if err := ct.chainRepo.Flush(); err != nil {
+			node.Warn("During chainRepo flush: " + err.Error())
+		}
+	}
+	if ct.channelRepo != nil {
+		if err := ct.channelRepo.Flush(); err != nil {
+			node.Warn("During channelRepo flush: " + err.Error())
+		}
+	}
+	if ct.rankingRepo != nil {
+		if err := ct.rankingRepo.Flush(); err != nil {
+			node.Warn("During rankingRepo flush: " + err.Error())
+		}
+	}
+	if ct.statsRepo != nil {
+		if err := ct.statsRepo.Flush(); err != nil {
+			node.Warn("During statsRepo flush: " + err.Error())
+		}
+	}
+}
+
+type NameHashNext struct {
+	Name []byte
+	Hash *chainhash.Hash
+	Next int32
+}
+
+func interruptRequested(interrupted <-chan struct{}) bool {
+	select {
+	case <-interrupted: // should never block on nil
+		return true
+	default:
+	}
+
+	return false
+}
+
+func (ct *ClaimTrie) makeNameHashNext(names [][]byte, all bool, interrupt <-chan struct{}) chan NameHashNext {
+	return ct.makeNameHashes(names, all, interrupt, ct.nodeManager.Hash)
+}
+
+// makeNameHashes computes the hashes of the names, or all of them, with hash on a few threads.
+func (ct *ClaimTrie) makeNameHashes(names [][]byte, all bool, interrupt <-chan struct{},
+	hash func(name []byte) (*chainhash.Hash, int32)) chan NameHashNext {
+
 	inputs := make(chan []byte, 512)
 	outputs := make(chan NameHashNext, 512)
 
 	var wg sync.WaitGroup
 	hashComputationWorker := func() {
 		for name := range inputs {
-			hash, next := ct.nodeManager.Hash(name)
-			outputs <- NameHashNext{name, hash, next}
+			h, next := hash(name)
+			outputs <- NameHashNext{name, h, next}
 		}
 		wg.Done()
 	}
@@ -1,14 +1,22 @@
 package claimtrie
 
 import (
+	"bytes"
+	"errors"
 	"math/rand"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/channel"
+	"github.com/lbryio/lbcd/claimtrie/claimid"
 	"github.com/lbryio/lbcd/claimtrie/config"
 	"github.com/lbryio/lbcd/claimtrie/merkletrie"
+	"github.com/lbryio/lbcd/claimtrie/node"
 	"github.com/lbryio/lbcd/claimtrie/param"
+	"github.com/lbryio/lbcd/claimtrie/ranking"
+	"github.com/lbryio/lbcd/claimtrie/stats"
 
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
 	"github.com/lbryio/lbcd/wire"
@@ -1025,3 +1033,1287 @@ func TestBlock884431(t *testing.T) {
 	r.NoError(err)
 	r.Equal(o11.String(), n.BestClaim.OutPoint.String())
 }
+
+func TestNameProof(t *testing.T) {
+	for _, ramTrie := range []bool{true, false} {
+		for _, fork := range []int32{1000, 1} {
+			testNameProof(t, ramTrie, fork)
+		}
+	}
+}
+
+func testNameProof(t *testing.T, ramTrie bool, fork int32) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.AllClaimsInMerkleForkHeight = fork
+	c := cfg
+	c.RamTrie = ramTrie
+	ct, err := New(c)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{1, 2, 3})
+	names := []string{"test", "tes", "testing", "other", "tea"}
+	ops := make([]wire.OutPoint, len(names))
+	for i, name := range names {
+		ops[i] = wire.OutPoint{Hash: hash, Index: uint32(i)}
+		err = ct.AddClaim(b(name), ops[i], change.NewClaimID(ops[i]), int64(10+i))
+		r.NoError(err)
+	}
+	o := wire.OutPoint{Hash: hash, Index: 10}
+	err = ct.AddClaim(b("test"), o, change.NewClaimID(o), 5)
+	r.NoError(err)
+	incrementBlock(r, ct, 2)
+
+	header := wire.BlockHeader{ClaimTrie: *ct.MerkleHash()}
+	for i, name := range names {
+		p, err := ct.NameProof(b(name), nil)
+		r.NoError(err)
+		r.Equal(ops[i], *p.OutPoint)
+		r.NoError(p.Verify(&header))
+	}
+
+	id := change.NewClaimID(o)
+	p, err := ct.NameProof(b("test"), &id)
+	if fork > ct.height {
+		r.Error(err)
+		for _, name := range []string{"te", "tesx", "testingz", "x", ""} {
+			p, err = ct.NameProof(b(name), nil)
+			r.NoError(err)
+			r.Nil(p.OutPoint)
+			r.NoError(p.Verify(&header))
+		}
+		return
+	}
+	r.NoError(err)
+	r.Equal(o, *p.OutPoint)
+	r.NoError(p.Verify(&header))
+
+	p.OutPoint.Index++
+	r.Error(p.Verify(&header))
+
+	// the root doesn't commit to names after the fork, so absent ones are rejected
+	for _, name := range []string{"te", "x"} {
+		_, err = ct.NameProof(b(name), nil)
+		r.Error(err)
+		r.Contains(err.Error(), "no winning claim")
+	}
+}
+
+func TestClaimIDIndex(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+
+	hash := chainhash.HashH([]byte{4, 5, 6})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+
+	for _, memory := range []bool{false, true} {
+		c := cfg
+		c.DataDir = t.TempDir()
+		c.Memory = memory
+		ct, err := New(c)
+		r.NoError(err)
+
+		_, err = ct.Claim(id1)
+		r.ErrorIs(err, ErrNoClaimIDIndex)
+		ct.Close()
+
+		c.ClaimIDIndex = true
+		ct, err = New(c)
+		r.NoError(err)
+
+		r.NoError(ct.AddClaim(b("Test"), o1, id1, 1))
+		incrementBlock(r, ct, 1)
+
+		claim, err := ct.Claim(id1)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("Test"), Created: 1, OutPoint: o1, Amount: 1, Status: claimid.Activated}, claim)
+
+		r.NoError(ct.AddClaim(b("other"), o2, id2, 1))
+		r.NoError(ct.SpendClaim(b("Test"), o1, id1))
+		r.NoError(ct.UpdateClaim(b("Test"), o3, 5, id1))
+		incrementBlock(r, ct, 1)
+
+		claim, err = ct.Claim(id2)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("other"), Created: 2, OutPoint: o2, Amount: 1, Status: claimid.Activated}, claim)
+		claim, err = ct.Claim(id1)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("Test"), Created: 1, OutPoint: o3, Amount: 5, Status: claimid.Activated}, claim)
+
+		r.NoError(ct.SpendClaim(b("Test"), o3, id1))
+		incrementBlock(r, ct, 1)
+
+		claim, err = ct.Claim(id1)
+		r.NoError(err)
+		r.Equal(claimid.Removed, claim.Status)
+		r.Equal(o3, claim.OutPoint)
+
+		// the changes are undone
+		r.NoError(ct.ResetHeight(1))
+		claim, err = ct.Claim(id2)
+		r.NoError(err)
+		r.Nil(claim)
+		claim, err = ct.Claim(id1)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("Test"), Created: 1, OutPoint: o1, Amount: 1, Status: claimid.Activated}, claim)
+
+		r.NoError(ct.AddClaim(b("other"), o2, id2, 1))
+		r.NoError(ct.SpendClaim(b("Test"), o1, id1))
+		r.NoError(ct.UpdateClaim(b("Test"), o3, 5, id1))
+		incrementBlock(r, ct, 1)
+		r.NoError(ct.SpendClaim(b("Test"), o3, id1))
+		incrementBlock(r, ct, 1)
+		ct.Close()
+
+		if memory {
+			continue
+		}
+
+		// enabling the index later builds it from the node repo
+		c.ClaimIDIndex = false
+		ct, err = New(c)
+		r.NoError(err)
+		incrementBlock(r, ct, 1)
+		ct.Close()
+
+		c.ClaimIDIndex = true
+		ct, err = New(c)
+		r.NoError(err)
+
+		claim, err = ct.Claim(id1)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("Test"), Created: 1, OutPoint: o3, Amount: 5, Status: claimid.Removed}, claim)
+		claim, err = ct.Claim(id2)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("other"), Created: 2, OutPoint: o2, Amount: 1, Status: claimid.Activated}, claim)
+
+		// what the index was built with can't be undone, so it's built again
+		r.NoError(ct.ResetHeight(1))
+		claim, err = ct.Claim(id2)
+		r.NoError(err)
+		r.Nil(claim)
+		claim, err = ct.Claim(id1)
+		r.NoError(err)
+		r.Equal(&claimid.Claim{Name: b("Test"), Created: 1, OutPoint: o1, Amount: 1, Status: claimid.Activated}, claim)
+		ct.Close()
+	}
+}
+
+func TestTakeoverIndex(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	c := cfg
+	ct, err := New(c)
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 1))
+	incrementBlock(r, ct, 1)
+
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	r.NoError(ct.AddClaim(b("test"), o2, id2, 2))
+	incrementBlock(r, ct, 1)
+
+	_, err = ct.Takeovers(b("test"), 0, 2)
+	r.ErrorIs(err, ErrNoTakeoverIndex)
+	ct.Close()
+
+	// enabling the index later builds it from the node repo
+	c.TakeoverIndex = true
+	ct, err = New(c)
+	r.NoError(err)
+	defer ct.Close()
+
+	ts, err := ct.Takeovers(b("test"), 0, 2)
+	r.NoError(err)
+	r.Len(ts, 2)
+	r.Equal(node.Takeover{Height: 1, ClaimID: id1, Amount: 1}, ts[0])
+	r.Equal(node.Takeover{Height: 2, PreviousClaimID: id1, ClaimID: id2, PreviousAmount: 1, Amount: 2}, ts[1])
+
+	r.NoError(ct.SpendClaim(b("test"), o2, id2))
+	incrementBlock(r, ct, 1)
+
+	ts, err = ct.Takeovers(b("test"), 2, 3)
+	r.NoError(err)
+	r.Len(ts, 2)
+	r.Equal(node.Takeover{Height: 3, PreviousClaimID: id2, ClaimID: id1, Amount: 1}, ts[1])
+
+	incrementBlock(r, ct, -1)
+	ts, err = ct.Takeovers(b("test"), 0, 3)
+	r.NoError(err)
+	r.Len(ts, 2)
+
+	// the claims going away take the name over to nothing, as replaying its history does
+	r.NoError(ct.SpendClaim(b("test"), o1, id1))
+	r.NoError(ct.SpendClaim(b("test"), o2, id2))
+	incrementBlock(r, ct, 1)
+
+	ts, err = ct.Takeovers(b("test"), 0, 3)
+	r.NoError(err)
+	r.Len(ts, 3)
+	r.Equal(node.Takeover{Height: 3, PreviousClaimID: id2}, ts[2])
+	replayed, err := ct.nodeManager.Takeovers(ct.height, b("test"))
+	r.NoError(err)
+	r.Equal(replayed, ts)
+}
+
+func TestChangeIndex(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.ActiveDelayFactor = 2
+	param.ActiveParams.OriginalClaimExpirationTime = 20
+	c := cfg
+	ct, err := New(c)
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 1))
+	incrementBlock(r, ct, 10)
+
+	_, err = ct.Changes(1)
+	r.ErrorIs(err, ErrNoChangeIndex)
+	ct.Close()
+
+	// enabling the index later builds it from the node repo
+	c.ChangeIndex = true
+	ct, err = New(c)
+	r.NoError(err)
+	defer ct.Close()
+
+	changes, err := ct.Changes(1)
+	r.NoError(err)
+	r.Equal([]change.Change{
+		{Type: change.AddClaim, Height: 1, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1, ActiveHeight: 1, VisibleHeight: 1},
+		{Type: change.ActivateClaim, Height: 1, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1, ActiveHeight: 1, VisibleHeight: 1},
+	}, changes)
+
+	// delayed by (11 - 1) / 2 blocks, but not the support for the controlling claim
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+	r.NoError(ct.AddClaim(b("test"), o2, id2, 5))
+	r.NoError(ct.AddSupport(b("test"), o3, 1, id1))
+	incrementBlock(r, ct, 1)
+
+	changes, err = ct.Changes(11)
+	r.NoError(err)
+	r.Equal([]change.Change{
+		{Type: change.AddClaim, Height: 11, Name: b("test"), ClaimID: id2, OutPoint: o2, Amount: 5, ActiveHeight: 16, VisibleHeight: 11},
+		{Type: change.AddSupport, Height: 11, Name: b("test"), ClaimID: id1, OutPoint: o3, Amount: 1, ActiveHeight: 11, VisibleHeight: 11},
+		{Type: change.ActivateSupport, Height: 11, Name: b("test"), ClaimID: id1, OutPoint: o3, Amount: 1, ActiveHeight: 11, VisibleHeight: 11},
+	}, changes)
+
+	_, err = ct.Changes(12)
+	r.Error(err)
+
+	incrementBlock(r, ct, 10)
+	changes, err = ct.Changes(16)
+	r.NoError(err)
+	r.Equal([]change.Change{
+		{Type: change.ActivateClaim, Height: 16, Name: b("test"), ClaimID: id2, OutPoint: o2, Amount: 5, ActiveHeight: 16, VisibleHeight: 11},
+	}, changes)
+	changes, err = ct.Changes(21)
+	r.NoError(err)
+	r.Equal([]change.Change{
+		{Type: change.ExpireClaim, Height: 21, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1, ActiveHeight: 1, VisibleHeight: 1},
+	}, changes)
+
+	// the blocks that are rolled back leave nothing behind
+	incrementBlock(r, ct, -11)
+	r.NoError(ct.AddSupport(b("test"), o3, 1, id1))
+	incrementBlock(r, ct, 1)
+	changes, err = ct.Changes(11)
+	r.NoError(err)
+	r.Len(changes, 2)
+	r.Equal(change.AddSupport, changes[0].Type)
+	incrementBlock(r, ct, 5)
+	changes, err = ct.Changes(16)
+	r.NoError(err)
+	r.Empty(changes)
+}
+
+func TestChannelIndex(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+
+	for _, memory := range []bool{false, true} {
+		c := cfg
+		c.DataDir = t.TempDir()
+		c.Memory = memory
+		ct, err := New(c)
+		r.NoError(err)
+
+		_, err = ct.ChannelMembers(change.ClaimID{1}, 0, 10)
+		r.ErrorIs(err, ErrNoChannelIndex)
+		ct.Close()
+
+		c.ChannelIndex = true
+		ct, err = New(c)
+		r.NoError(err)
+
+		// a new index of a new ClaimTrie needs no catching up
+		height, err := ct.ChannelIndexHeight()
+		r.NoError(err)
+		r.Equal(int32(0), height)
+
+		hash := chainhash.HashH([]byte{10, 11, 12})
+		ch1, ch2 := change.ClaimID{1}, change.ClaimID{2}
+		o1, o2, o3 := wire.OutPoint{Hash: hash, Index: 1}, wire.OutPoint{Hash: hash, Index: 2}, wire.OutPoint{Hash: hash, Index: 3}
+		id1, id2 := change.NewClaimID(o1), change.NewClaimID(o2)
+
+		r.NoError(ct.SetChannelKey(1, ch1, b("key1")))
+		r.NoError(ct.SetChannelKey(1, ch2, b("key2")))
+		r.NoError(ct.SetChannelMember(1, ch1, channel.Member{ClaimID: id1, Name: b("one"), OutPoint: o1}))
+		incrementBlock(r, ct, 1)
+		r.NoError(ct.SetChannelIndexHeight(1))
+		r.NoError(ct.SetChannelMember(2, ch1, channel.Member{ClaimID: id2, Name: b("two"), OutPoint: o2}))
+		incrementBlock(r, ct, 1)
+		r.NoError(ct.SetChannelIndexHeight(2))
+
+		key, err := ct.ChannelKey(ch1)
+		r.NoError(err)
+		r.Equal(b("key1"), key)
+		members, err := ct.ChannelMembers(ch1, 0, 10)
+		r.NoError(err)
+		r.Equal([]channel.Member{
+			{ClaimID: id1, Name: b("one"), OutPoint: o1, Height: 1},
+			{ClaimID: id2, Name: b("two"), OutPoint: o2, Height: 2},
+		}, members)
+		members, err = ct.ChannelMembers(ch1, 1, 10)
+		r.NoError(err)
+		r.Len(members, 1)
+		r.Equal(id2, members[0].ClaimID)
+
+		// an update within the channel keeps its place, and one to another channel moves it
+		r.NoError(ct.SetChannelMember(3, ch1, channel.Member{ClaimID: id1, Name: b("one"), OutPoint: o3}))
+		r.NoError(ct.SetChannelMember(3, ch2, channel.Member{ClaimID: id2, Name: b("two"), OutPoint: o2}))
+		r.NoError(ct.SetChannelKey(3, ch2, nil))
+		incrementBlock(r, ct, 1)
+		r.NoError(ct.SetChannelIndexHeight(3))
+
+		members, err = ct.ChannelMembers(ch1, 0, 10)
+		r.NoError(err)
+		r.Equal([]channel.Member{{ClaimID: id1, Name: b("one"), OutPoint: o3, Height: 1}}, members)
+		members, err = ct.ChannelMembers(ch2, 0, 10)
+		r.NoError(err)
+		r.Equal([]channel.Member{{ClaimID: id2, Name: b("two"), OutPoint: o2, Height: 3}}, members)
+		key, err = ct.ChannelKey(ch2)
+		r.NoError(err)
+		r.Nil(key)
+
+		r.NoError(ct.SetChannelMember(4, change.ClaimID{}, channel.Member{ClaimID: id2}))
+		incrementBlock(r, ct, 1)
+		r.NoError(ct.SetChannelIndexHeight(4))
+		members, err = ct.ChannelMembers(ch2, 0, 10)
+		r.NoError(err)
+		r.Empty(members)
+
+		// the blocks that are rolled back leave nothing behind
+		incrementBlock(r, ct, -2)
+		members, err = ct.ChannelMembers(ch1, 0, 10)
+		r.NoError(err)
+		r.Equal([]channel.Member{
+			{ClaimID: id1, Name: b("one"), OutPoint: o1, Height: 1},
+			{ClaimID: id2, Name: b("two"), OutPoint: o2, Height: 2},
+		}, members)
+		key, err = ct.ChannelKey(ch2)
+		r.NoError(err)
+		r.Equal(b("key2"), key)
+		height, err = ct.ChannelIndexHeight()
+		r.NoError(err)
+		r.Equal(int32(2), height)
+
+		// the index is moved along by the one giving it the blocks, and only dropped by the ClaimTrie
+		incrementBlock(r, ct, 1)
+		height, err = ct.ChannelIndexHeight()
+		r.NoError(err)
+		r.Equal(int32(2), height)
+
+		// one that failed is left behind, and rebuilt from scratch
+		ct.DisableChannelIndex(errors.New("disk full"))
+		r.False(ct.ChannelIndexEnabled())
+		ct.Close()
+		if memory {
+			continue
+		}
+		ct, err = New(c)
+		r.NoError(err)
+		height, err = ct.ChannelIndexHeight()
+		r.NoError(err)
+		r.Equal(int32(-1), height)
+		members, err = ct.ChannelMembers(ch1, 0, 10)
+		r.NoError(err)
+		r.Empty(members)
+		ct.Close()
+	}
+}
+
+func TestEvents(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{4, 5, 6})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 1))
+	incrementBlock(r, ct, 1)
+
+	events, err := ct.Events(1)
+	r.NoError(err)
+	r.Equal([]Event{
+		{Type: ClaimAdded, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1},
+		{Type: ClaimActivated, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1},
+		{Type: Takeover, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1},
+	}, events)
+
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	r.NoError(ct.AddSupport(b("test"), o2, 2, id1))
+	incrementBlock(r, ct, 1)
+
+	events, err = ct.Events(2)
+	r.NoError(err)
+	r.Equal([]Event{
+		{Type: SupportAdded, Name: b("test"), ClaimID: id1, OutPoint: o2, Amount: 2},
+		{Type: SupportActivated, Name: b("test"), ClaimID: id1, OutPoint: o2, Amount: 2},
+	}, events)
+
+	// an update spends the old output; it is reported once
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+	r.NoError(ct.SpendClaim(b("test"), o1, id1))
+	r.NoError(ct.UpdateClaim(b("test"), o3, 3, id1))
+	r.NoError(ct.SpendSupport(b("test"), o2, id1))
+	incrementBlock(r, ct, 1)
+
+	events, err = ct.Events(3)
+	r.NoError(err)
+	r.Equal([]Event{
+		{Type: ClaimUpdated, Name: b("test"), ClaimID: id1, OutPoint: o3, Amount: 3},
+		{Type: SupportSpent, Name: b("test"), ClaimID: id1, OutPoint: o2},
+	}, events)
+
+	r.NoError(ct.SpendClaim(b("test"), o3, id1))
+	incrementBlock(r, ct, 1)
+
+	events, err = ct.Events(4)
+	r.NoError(err)
+	r.Equal([]Event{
+		{Type: ClaimSpent, Name: b("test"), ClaimID: id1, OutPoint: o3},
+		{Type: Takeover, Name: b("test"), PreviousClaimID: id1},
+	}, events)
+
+	_, err = ct.Events(5)
+	r.Error(err)
+}
+
+func TestScheduledEvents(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.ActiveDelayFactor = 2
+	param.ActiveParams.OriginalClaimExpirationTime = 20
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 1))
+	incrementBlock(r, ct, 10)
+
+	// delayed by (11 - 1) / 2 blocks
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+	r.NoError(ct.AddClaim(b("test"), o2, id2, 5))
+	r.NoError(ct.AddSupport(b("test"), o3, 1, id2))
+	incrementBlock(r, ct, 1)
+
+	events, err := ct.ScheduledEvents(12, 25)
+	r.NoError(err)
+	r.Equal([]ScheduledEvent{
+		{16, Event{Type: ClaimActivated, Name: b("test"), ClaimID: id2, OutPoint: o2, Amount: 5}},
+		{16, Event{Type: SupportActivated, Name: b("test"), ClaimID: id2, OutPoint: o3, Amount: 1}},
+		{21, Event{Type: ClaimExpired, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1}},
+	}, events)
+
+	events, err = ct.ScheduledEvents(17, 25)
+	r.NoError(err)
+	r.Equal([]ScheduledEvent{
+		{21, Event{Type: ClaimExpired, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1}},
+	}, events)
+
+	_, err = ct.ScheduledEvents(11, 25)
+	r.Error(err)
+
+	// they happen as predicted
+	incrementBlock(r, ct, 10)
+	happened, err := ct.Events(16)
+	r.NoError(err)
+	r.Equal(Event{Type: ClaimActivated, Name: b("test"), ClaimID: id2, OutPoint: o2, Amount: 5}, happened[0])
+	r.Equal(Event{Type: SupportActivated, Name: b("test"), ClaimID: id2, OutPoint: o3, Amount: 1}, happened[1])
+	happened, err = ct.Events(21)
+	r.NoError(err)
+	r.Equal([]Event{{Type: ClaimExpired, Name: b("test"), ClaimID: id1, OutPoint: o1, Amount: 1}}, happened)
+}
+
+func TestSimulateBid(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.ActiveDelayFactor = 2
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{4, 5, 6})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 5))
+	incrementBlock(r, ct, 10)
+
+	// delayed by (11 - 1) / 2 blocks either way
+	sim, err := ct.SimulateBid(b("test"), 3, change.AddClaim, change.ClaimID{})
+	r.NoError(err)
+	r.Equal(int32(11), sim.Height)
+	r.Equal(int32(16), sim.ActiveAt)
+	r.False(sim.Controlling)
+	r.Equal(int64(3), sim.EffectiveAmount)
+	r.Equal(int64(5), sim.WinningAmount)
+	r.Equal(int64(3), sim.Needed)
+
+	sim, err = ct.SimulateBid(b("test"), 6, change.AddClaim, change.ClaimID{})
+	r.NoError(err)
+	r.Equal(int32(16), sim.ActiveAt)
+	r.True(sim.Controlling)
+	r.Equal(int32(16), sim.TakenOverAt)
+	r.Equal(int64(0), sim.Needed)
+
+	// a support for the controlling claim is active right away
+	sim, err = ct.SimulateBid(b("test"), 2, change.AddSupport, id1)
+	r.NoError(err)
+	r.Equal(id1, sim.ClaimID)
+	r.Equal(int32(11), sim.ActiveAt)
+	r.True(sim.Controlling)
+	r.Equal(int32(1), sim.TakenOverAt)
+	r.Equal(int64(7), sim.EffectiveAmount)
+	r.Equal(int64(0), sim.WinningAmount)
+
+	sim, err = ct.SimulateBid(b("test"), 2, change.UpdateClaim, id1)
+	r.NoError(err)
+	r.True(sim.Controlling)
+	r.Equal(int64(2), sim.EffectiveAmount)
+
+	_, err = ct.SimulateBid(b("test"), 2, change.AddSupport, change.ClaimID{1})
+	r.Error(err)
+
+	// it takes over as predicted, and the simulations left no trace
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	r.NoError(ct.AddClaim(b("test"), o2, id2, 6))
+	incrementBlock(r, ct, 1)
+	n, err := ct.NodeAt(ct.height, b("test"))
+	r.NoError(err)
+	r.Len(n.Claims, 2)
+	r.Len(n.Supports, 0)
+	r.Equal(id1, n.BestClaim.ClaimID)
+	incrementBlock(r, ct, 5)
+	n, err = ct.NodeAt(ct.height, b("test"))
+	r.NoError(err)
+	r.Equal(id2, n.BestClaim.ClaimID)
+	r.Equal(int32(16), n.TakenOverAt)
+}
+
+func TestNodeWithPending(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{1, 1, 2})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 1))
+	incrementBlock(r, ct, 1)
+
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+	pending := []change.Change{
+		{Type: change.AddClaim, Name: b("test"), OutPoint: o2, ClaimID: id2, Amount: 2},
+		{Type: change.AddSupport, Name: b("test"), OutPoint: o3, ClaimID: id1, Amount: 5},
+		{Type: change.AddClaim, Name: b("other"), OutPoint: o3, ClaimID: id2, Amount: 2},
+	}
+	n, err := ct.NodeWithPending(b("test"), pending)
+	r.NoError(err)
+	r.Len(n.Claims, 2)
+	r.Len(n.Supports, 1)
+	r.Equal(int32(2), n.Claims[1].AcceptedAt)
+	r.Equal(id1, n.BestClaim.ClaimID)
+	r.Equal(int64(5), n.SupportSums[id1.Key()])
+
+	// nothing was written
+	n, err = ct.NodeAt(ct.Height(), b("test"))
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	r.Len(n.Supports, 0)
+
+	n, err = ct.NodeWithPending(b("none"), pending)
+	r.NoError(err)
+	r.Nil(n)
+}
+
+func TestListNames(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{4, 3, 2, 1})
+	names := []string{"a", "ab", "abc", "b", "ba"}
+	for i, name := range names {
+		o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+		r.NoError(ct.AddClaim(b(name), o, change.NewClaimID(o), 1))
+	}
+	incrementBlock(r, ct, 1)
+	o := wire.OutPoint{Hash: hash, Index: 0}
+	r.NoError(ct.SpendClaim(b("a"), o, change.NewClaimID(o)))
+	incrementBlock(r, ct, 1)
+
+	list := func(height int32, prefix, start string, limit int) []string {
+		var listed []string
+		err := ct.ListNames(height, b(prefix), b(start), func(name []byte, n *node.Node) bool {
+			r.True(n.HasActiveBestClaim())
+			listed = append(listed, string(name))
+			return len(listed) < limit
+		})
+		r.NoError(err)
+		return listed
+	}
+
+	r.Equal([]string{"ab", "abc", "b", "ba"}, list(ct.Height(), "", "", 10))
+	r.Equal([]string{"a", "ab", "abc", "b", "ba"}, list(ct.Height()-1, "", "", 10))
+	r.Equal([]string{"ab", "abc"}, list(ct.Height(), "a", "", 10))
+	r.Equal([]string{"abc", "b"}, list(ct.Height(), "", "abc", 2))
+	r.Equal([]string{"b", "ba"}, list(ct.Height(), "b", "a", 10))
+	r.Empty(list(ct.Height(), "a", "b", 10))
+}
+
+func TestStats(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	c := cfg
+	ct, err := New(c)
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{5, 6, 7})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	id1 := change.NewClaimID(o1)
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id2 := change.NewClaimID(o2)
+	r.NoError(ct.AddClaim(b("test"), o1, id1, 10))
+	r.NoError(ct.AddClaim(b("other"), o2, id2, 5))
+	incrementBlock(r, ct, 1)
+
+	_, err = ct.Stats()
+	r.ErrorIs(err, ErrNoStats)
+	ct.Close()
+
+	// enabling the stats later computes them from the node repo
+	c.Stats = true
+	ct, err = New(c)
+	r.NoError(err)
+
+	s, err := ct.Stats()
+	r.NoError(err)
+	first := stats.Stats{Names: 2, ActiveClaims: 2, ClaimAmount: 15}
+	r.Equal(first, s)
+
+	// both controlling claims were accepted together so they expire together
+	n, err := ct.nodeManager.NodeAt(ct.height, b("test"))
+	r.NoError(err)
+	within := n.BestClaim.ExpireAt() - ct.height
+	expiring, err := ct.ExpiringNames(within)
+	r.NoError(err)
+	r.Equal(int64(2), expiring)
+	expiring, err = ct.ExpiringNames(within - 1)
+	r.NoError(err)
+	r.Zero(expiring)
+
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+	r.NoError(ct.AddSupport(b("test"), o3, 3, id1))
+	o4 := wire.OutPoint{Hash: hash, Index: 4}
+	r.NoError(ct.AddClaim(b("test"), o4, change.NewClaimID(o4), 1))
+	incrementBlock(r, ct, 1)
+
+	s, err = ct.Stats()
+	r.NoError(err)
+	r.Equal(stats.Stats{Names: 2, ActiveClaims: 3, ActiveSupports: 1, ClaimAmount: 16, SupportAmount: 3}, s)
+
+	r.NoError(ct.SpendClaim(b("other"), o2, id2))
+	incrementBlock(r, ct, 1)
+
+	s, err = ct.Stats()
+	r.NoError(err)
+	r.Equal(stats.Stats{Names: 1, ActiveClaims: 2, ActiveSupports: 1, ClaimAmount: 11, SupportAmount: 3}, s)
+	expiring, err = ct.ExpiringNames(within)
+	r.NoError(err)
+	r.Equal(int64(1), expiring)
+
+	// the stats are kept on disk
+	ct.Close()
+	ct, err = New(c)
+	r.NoError(err)
+	defer ct.Close()
+	s2, err := ct.Stats()
+	r.NoError(err)
+	r.Equal(s, s2)
+
+	expiring, err = ct.ExpiringNames(within)
+	r.NoError(err)
+	r.Equal(int64(1), expiring)
+
+	incrementBlock(r, ct, -2)
+	s, err = ct.Stats()
+	r.NoError(err)
+	r.Equal(first, s)
+	expiring, err = ct.ExpiringNames(within)
+	r.NoError(err)
+	r.Equal(int64(2), expiring)
+}
+
+func TestRankingIndex(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+
+	hash := chainhash.HashH([]byte{6, 7, 8})
+	var ids [6]change.ClaimID
+	var ops [6]wire.OutPoint
+	for i := range ops {
+		ops[i] = wire.OutPoint{Hash: hash, Index: uint32(i)}
+		ids[i] = change.NewClaimID(ops[i])
+	}
+	entry := func(name string, i int, amount int64) ranking.Entry {
+		return ranking.Entry{Name: b(name), ClaimID: ids[i], Amount: amount}
+	}
+
+	for _, memory := range []bool{false, true} {
+		c := cfg
+		c.DataDir = t.TempDir()
+		c.Memory = memory
+		ct, err := New(c)
+		r.NoError(err)
+
+		_, err = ct.TopRanked(0, false, nil, 0, 10)
+		r.ErrorIs(err, ErrNoRankingIndex)
+		ct.Close()
+
+		c.RankingIndex = true
+		ct, err = New(c)
+		r.NoError(err)
+
+		r.NoError(ct.AddClaim(b("test"), ops[0], ids[0], 10))
+		r.NoError(ct.AddClaim(b("other"), ops[1], ids[1], 5))
+		r.NoError(ct.AddClaim(b("zed"), ops[2], ids[2], 7))
+		incrementBlock(r, ct, 1)
+
+		r.NoError(ct.AddSupport(b("test"), ops[3], 3, ids[0]))
+		r.NoError(ct.AddClaim(b("test"), ops[4], ids[4], 1))
+		r.NoError(ct.AddClaim(b("tester"), ops[5], ids[5], 20))
+		incrementBlock(r, ct, 1)
+
+		top, err := ct.TopRanked(ct.height, false, nil, 0, 10)
+		r.NoError(err)
+		all := []ranking.Entry{entry("tester", 5, 20), entry("test", 0, 13), entry("zed", 2, 7),
+			entry("other", 1, 5), entry("test", 4, 1)}
+		r.Equal(all, top)
+
+		top, err = ct.TopRanked(ct.height, true, nil, 0, 10)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{entry("tester", 5, 20), entry("test", 0, 13), entry("zed", 2, 7), entry("other", 1, 5)}, top)
+
+		// the filters
+		top, err = ct.TopRanked(ct.height, false, b("test"), 0, 10)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{entry("tester", 5, 20), entry("test", 0, 13), entry("test", 4, 1)}, top)
+		top, err = ct.TopRanked(ct.height, false, nil, 7, 10)
+		r.NoError(err)
+		r.Equal(all[:3], top)
+		top, err = ct.TopRanked(ct.height, true, b("test"), 0, 1)
+		r.NoError(err)
+		r.Equal(all[:1], top)
+
+		r.NoError(ct.SpendClaim(b("other"), ops[1], ids[1]))
+		incrementBlock(r, ct, 1)
+
+		top, err = ct.TopRanked(ct.height, false, nil, 0, 10)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{all[0], all[1], all[2], all[4]}, top)
+
+		// the names that changed since a past height are ranked as they were then
+		top, err = ct.TopRanked(1, false, nil, 0, 10)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{entry("test", 0, 10), entry("zed", 2, 7), entry("other", 1, 5)}, top)
+		top, err = ct.TopRanked(1, false, nil, 0, 2)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{entry("test", 0, 10), entry("zed", 2, 7)}, top)
+		_, err = ct.TopRanked(ct.height+1, false, nil, 0, 10)
+		r.Error(err)
+
+		incrementBlock(r, ct, -1)
+		top, err = ct.TopRanked(ct.height, false, nil, 0, 10)
+		r.NoError(err)
+		r.Equal(all, top)
+
+		r.NoError(ct.SpendClaim(b("other"), ops[1], ids[1]))
+		incrementBlock(r, ct, 1)
+
+		// only the heights close to the tip are ranked
+		incrementBlock(r, ct, maxRankedDepth)
+		_, err = ct.TopRanked(ct.height-maxRankedDepth-1, false, nil, 0, 10)
+		r.Error(err)
+		top, err = ct.TopRanked(ct.height-maxRankedDepth, false, b("test"), 0, 10)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{entry("tester", 5, 20), entry("test", 0, 13), entry("test", 4, 1)}, top)
+		ct.Close()
+
+		if memory {
+			continue
+		}
+
+		// enabling the index later ranks every name again
+		c.RankingIndex = false
+		ct, err = New(c)
+		r.NoError(err)
+		r.NoError(ct.SpendClaim(b("tester"), ops[5], ids[5]))
+		incrementBlock(r, ct, 1)
+		ct.Close()
+
+		c.RankingIndex = true
+		ct, err = New(c)
+		r.NoError(err)
+		top, err = ct.TopRanked(ct.height, false, nil, 0, 10)
+		r.NoError(err)
+		r.Equal([]ranking.Entry{all[1], all[2], all[4]}, top)
+		ct.Close()
+	}
+}
+
+// failingRankings fails to take any rankings.
+type failingRankings struct {
+	ranking.Repo
+}
+
+func (failingRankings) Set([]ranking.Ranking) error { return errors.New("disk full") }
+
+func TestIndexFailure(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+
+	c := cfg
+	c.RankingIndex = true
+	ct, err := New(c)
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{9, 8, 7})
+	o := wire.OutPoint{Hash: hash, Index: 0}
+	id := change.NewClaimID(o)
+	r.NoError(ct.AddClaim(b("test"), o, id, 10))
+	incrementBlock(r, ct, 1)
+
+	// a failing index is left behind while the block goes through
+	ct.rankingRepo = failingRankings{ct.rankingRepo}
+	o2 := wire.OutPoint{Hash: hash, Index: 1}
+	r.NoError(ct.AddClaim(b("other"), o2, change.NewClaimID(o2), 5))
+	incrementBlock(r, ct, 1)
+	r.Equal(int32(2), ct.height)
+	expected := ct.MerkleHash()
+	_, err = ct.TopRanked(ct.height, false, nil, 0, 10)
+	r.ErrorIs(err, ErrNoRankingIndex)
+	ct.Close()
+
+	// and rebuilt the next time
+	ct, err = New(c)
+	r.NoError(err)
+	defer ct.Close()
+	r.Equal(expected, ct.MerkleHash())
+	top, err := ct.TopRanked(ct.height, false, nil, 0, 10)
+	r.NoError(err)
+	r.Equal([]ranking.Entry{{Name: b("test"), ClaimID: id, Amount: 10},
+		{Name: b("other"), ClaimID: change.NewClaimID(o2), Amount: 5}}, top)
+}
+
+func TestParamsChanged(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+
+	ct, err := New(cfg)
+	r.NoError(err)
+	incrementBlock(r, ct, 1)
+	ct.Close()
+
+	// the repos only open with the parameters they were built with
+	param.ActiveParams.ActiveDelayFactor++
+	_, err = New(cfg)
+	r.ErrorIs(err, ErrParamsChanged)
+
+	param.ActiveParams.ActiveDelayFactor--
+	ct, err = New(cfg)
+	r.NoError(err)
+	ct.Close()
+}
+
+func TestExportImport(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.ActiveDelayFactor = 1
+
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	o3 := wire.OutPoint{Hash: hash, Index: 3}
+	r.NoError(ct.AddClaim(b("test"), o1, change.NewClaimID(o1), 8))
+	r.NoError(ct.AddClaim(b("tester"), o3, change.NewClaimID(o3), 1))
+	incrementBlock(r, ct, 10)
+	r.NoError(ct.AddClaim(b("test"), o2, change.NewClaimID(o2), 18))
+	incrementBlock(r, ct, 1)
+	r.NoError(ct.SpendClaim(b("tester"), o3, change.NewClaimID(o3)))
+	incrementBlock(r, ct, 1)
+
+	// the export can be from below the tip; the takeover of o2 is still pending at 11
+	var buffer bytes.Buffer
+	r.NoError(ct.Export(11, &buffer))
+	exported := buffer.Bytes()
+
+	r.Error(ct.Export(ct.height+1, &buffer))
+
+	c := cfg
+	c.DataDir = t.TempDir()
+
+	// a partial import leaves nothing behind that keeps the full one from happening
+	_, _, err = Import(c, bytes.NewReader(exported[:len(exported)-10]))
+	r.Error(err)
+	r.NotErrorIs(err, ErrImportNotEmpty)
+
+	height, imported, err := Import(c, bytes.NewReader(exported))
+	r.NoError(err)
+	r.Equal(int32(11), height)
+	expected, err := ct.blockRepo.Get(11)
+	r.NoError(err)
+	r.Equal(expected, imported)
+
+	ct2, err := New(c)
+	r.NoError(err)
+	defer ct2.Close()
+	r.Equal(int32(11), ct2.Height())
+	r.Equal(expected, ct2.MerkleHash())
+
+	// the blocks exported along with it let it be reset
+	r.NoError(ct2.ResetHeight(9))
+	expected9, err := ct.blockRepo.Get(9)
+	r.NoError(err)
+	r.Equal(expected9, ct2.MerkleHash())
+	incrementBlock(r, ct2, 1)
+	r.NoError(ct2.AddClaim(b("test"), o2, change.NewClaimID(o2), 18))
+	incrementBlock(r, ct2, 1)
+	r.Equal(expected, ct2.MerkleHash())
+
+	r.NoError(ct2.SpendClaim(b("tester"), o3, change.NewClaimID(o3)))
+	incrementBlock(r, ct2, 1)
+	r.Equal(ct.MerkleHash(), ct2.MerkleHash())
+
+	// the pending takeover happens in both
+	incrementBlock(r, ct, 10)
+	incrementBlock(r, ct2, 10)
+	r.Equal(ct.MerkleHash(), ct2.MerkleHash())
+	n, err := ct2.NodeAt(ct2.height, b("test"))
+	r.NoError(err)
+	r.Equal(o2, n.BestClaim.OutPoint)
+
+	// only empty repos take an import
+	ct2.Close()
+	_, _, err = Import(c, bytes.NewReader(exported))
+	r.ErrorIs(err, ErrImportNotEmpty)
+}
+
+func TestPruning(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.NormalizedNameForkHeight = 1
+	param.ActiveParams.OriginalClaimExpirationTime = 30
+	param.ActiveParams.ActiveDelayFactor = 1
+
+	full, err := New(cfg)
+	r.NoError(err)
+	defer full.Close()
+
+	c := cfg
+	c.DataDir = t.TempDir()
+	c.PruneDepth = 10
+	c.RankingIndex = true
+	pruned, err := New(c)
+	r.NoError(err)
+	defer func() { pruned.Close() }()
+
+	both := func(f func(ct *ClaimTrie) error) {
+		r.NoError(f(full))
+		r.NoError(f(pruned))
+	}
+	appendBlock := func() {
+		both(func(ct *ClaimTrie) error { return ct.AppendBlock() })
+		r.Equal(full.MerkleHash(), pruned.MerkleHash(), "at %d", full.height)
+	}
+
+	hash := chainhash.HashH([]byte{10, 11, 12})
+	gone := wire.OutPoint{Hash: hash, Index: 1000}
+	both(func(ct *ClaimTrie) error { return ct.AddClaim(b("gone"), gone, change.NewClaimID(gone), 1) })
+	appendBlock()
+	both(func(ct *ClaimTrie) error { return ct.SpendClaim(b("gone"), gone, change.NewClaimID(gone)) })
+
+	var previous wire.OutPoint
+	for i := 0; i < 60; i++ {
+		o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+		name := []byte{'a' + byte(i%5)}
+		both(func(ct *ClaimTrie) error { return ct.AddClaim(name, o, change.NewClaimID(o), int64(i+1)) })
+		if i%3 == 0 {
+			s := wire.OutPoint{Hash: hash, Index: uint32(i + 500)}
+			both(func(ct *ClaimTrie) error { return ct.AddSupport(name, s, 5, change.NewClaimID(o)) })
+		}
+		if i%4 == 1 {
+			pn, po := []byte{'a' + byte((i-1)%5)}, previous
+			both(func(ct *ClaimTrie) error { return ct.SpendClaim(pn, po, change.NewClaimID(po)) })
+		}
+		previous = o
+		appendBlock()
+	}
+
+	// the history below the depth is gone, along with the nodes with nothing left in them
+	_, err = pruned.NodeAt(pruned.height-11, b("a"))
+	r.ErrorIs(err, node.ErrPruned)
+	r.ErrorIs(pruned.ResetHeight(pruned.height-11), node.ErrPruned)
+	_, err = pruned.TopRanked(pruned.height-11, false, nil, 0, 10)
+	r.ErrorIs(err, node.ErrPruned)
+	changes, err := pruned.nodeRepo.LoadChanges(b("gone"))
+	r.NoError(err)
+	r.Empty(changes)
+	changes, err = pruned.nodeRepo.LoadChanges(b("a"))
+	r.NoError(err)
+	fullChanges, err := full.nodeRepo.LoadChanges(b("a"))
+	r.NoError(err)
+	r.Less(len(changes), len(fullChanges))
+
+	n, err := pruned.NodeAt(pruned.height, b("a"))
+	r.NoError(err)
+	expected, err := full.NodeAt(full.height, b("a"))
+	r.NoError(err)
+	r.Equal(expected, n)
+	ts, err := pruned.Takeovers(b("a"), 0, pruned.height)
+	r.ErrorIs(err, ErrNoTakeoverIndex)
+	nts, err := pruned.nodeManager.Takeovers(pruned.height, b("a"))
+	r.NoError(err)
+	fts, err := full.nodeManager.Takeovers(full.height, b("a"))
+	r.NoError(err)
+	r.Equal(fts[len(fts)-len(nts):], nts)
+	r.Nil(ts)
+
+	// rolling back within the depth still works, as does reopening
+	both(func(ct *ClaimTrie) error { return ct.ResetHeight(ct.height - 5) })
+	r.Equal(full.MerkleHash(), pruned.MerkleHash())
+	for i := 0; i < 5; i++ {
+		appendBlock()
+	}
+	pruned.Close()
+	pruned, err = New(c)
+	r.NoError(err)
+	r.Equal(full.MerkleHash(), pruned.MerkleHash())
+	appendBlock()
+}
+
+func TestMemory(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.ActiveDelayFactor = 1
+
+	c := cfg
+	c.ClaimIDIndex, c.TakeoverIndex, c.ChangeIndex, c.Stats = true, true, true, true
+	disk, err := New(c)
+	r.NoError(err)
+	defer disk.Close()
+
+	c.DataDir = t.TempDir()
+	c.Memory = true
+	memory, err := New(c)
+	r.NoError(err)
+	c.RamTrie = false
+	memoryTrie, err := New(c)
+	r.NoError(err)
+
+	tries := []*ClaimTrie{disk, memory, memoryTrie}
+	all := func(f func(ct *ClaimTrie) error) {
+		for _, ct := range tries {
+			r.NoError(f(ct))
+		}
+	}
+	appendBlock := func() {
+		all(func(ct *ClaimTrie) error { return ct.AppendBlock() })
+		for _, ct := range tries[1:] {
+			r.Equal(disk.MerkleHash(), ct.MerkleHash(), "at %d", disk.height)
+		}
+	}
+
+	hash := chainhash.HashH([]byte{20, 21, 22})
+	var previous wire.OutPoint
+	for i := 0; i < 40; i++ {
+		o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+		name := []byte{'a' + byte(i%4), 'b' + byte(i%3)}
+		all(func(ct *ClaimTrie) error { return ct.AddClaim(name, o, change.NewClaimID(o), int64(i%7+1)) })
+		if i%3 == 0 {
+			s := wire.OutPoint{Hash: hash, Index: uint32(i + 500)}
+			all(func(ct *ClaimTrie) error { return ct.AddSupport(name, s, 4, change.NewClaimID(o)) })
+		}
+		if i%5 == 2 {
+			pn, po := []byte{'a' + byte((i-1)%4), 'b' + byte((i-1)%3)}, previous
+			all(func(ct *ClaimTrie) error { return ct.SpendClaim(pn, po, change.NewClaimID(po)) })
+		}
+		previous = o
+		appendBlock()
+	}
+
+	all(func(ct *ClaimTrie) error { return ct.ResetHeight(ct.height - 10) })
+	r.Equal(disk.MerkleHash(), memory.MerkleHash())
+	r.Equal(disk.MerkleHash(), memoryTrie.MerkleHash())
+	for i := 0; i < 10; i++ {
+		appendBlock()
+	}
+
+	id := change.NewClaimID(wire.OutPoint{Hash: hash, Index: 3})
+	for _, ct := range tries[1:] {
+		expected, err := disk.Takeovers(b("db"), 0, disk.height)
+		r.NoError(err)
+		ts, err := ct.Takeovers(b("db"), 0, ct.height)
+		r.NoError(err)
+		r.Equal(expected, ts)
+
+		expectedClaim, err := disk.Claim(id)
+		r.NoError(err)
+		claim, err := ct.Claim(id)
+		r.NoError(err)
+		r.Equal(expectedClaim, claim)
+
+		expectedStats, err := disk.Stats()
+		r.NoError(err)
+		s, err := ct.Stats()
+		r.NoError(err)
+		r.Equal(expectedStats, s)
+
+		for h := int32(25); h <= ct.height; h++ {
+			expectedChanges, err := disk.Changes(h)
+			r.NoError(err)
+			changes, err := ct.Changes(h)
+			r.NoError(err)
+			r.Equal(expectedChanges, changes)
+		}
+		ct.Close()
+	}
+
+	r.NoDirExists(filepath.Join(c.DataDir, "claim_dbs"))
+}
+
+func TestVerify(t *testing.T) {
+	r := require.New(t)
+	setup(t)
+	param.ActiveParams.ActiveDelayFactor = 1
+	param.ActiveParams.AllClaimsInMerkleForkHeight = 20
+
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer ct.Close()
+
+	hash := chainhash.HashH([]byte{30, 31, 32})
+	var previous wire.OutPoint
+	for i := 0; i < 30; i++ {
+		o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+		name := []byte{'a' + byte(i%4)}
+		r.NoError(ct.AddClaim(name, o, change.NewClaimID(o), int64(i%5+1)))
+		if i%4 == 3 {
+			r.NoError(ct.SpendClaim([]byte{'a' + byte((i-1)%4)}, previous, change.NewClaimID(previous)))
+		}
+		previous = o
+		r.NoError(ct.AppendBlock())
+	}
+
+	d, err := ct.Verify(0, ct.height, nil, nil, nil)
+	r.NoError(err)
+	r.Nil(d)
+	d, err = ct.Verify(10, 25, nil, nil, nil)
+	r.NoError(err)
+	r.Nil(d)
+
+	// the header is checked as well
+	wrong := chainhash.HashH([]byte("wrong"))
+	header := func(height int32) (*chainhash.Hash, error) {
+		if height == 7 {
+			return &wrong, nil
+		}
+		return ct.blockRepo.Get(height)
+	}
+	d, err = ct.Verify(5, ct.height, header, nil, nil)
+	r.NoError(err)
+	r.NotNil(d)
+	r.Equal(int32(7), d.Height)
+	r.Equal(&wrong, d.Header)
+	r.Equal(d.Computed, d.Stored)
+	r.Equal([][]byte{b("c")}, d.Names)
+
+	// as is the stored hash
+	stored, err := ct.blockRepo.Get(12)
+	r.NoError(err)
+	r.NoError(ct.blockRepo.Set(12, &wrong))
+	d, err = ct.Verify(0, ct.height, nil, nil, nil)
+	r.NoError(err)
+	r.NotNil(d)
+	r.Equal(int32(12), d.Height)
+	r.Equal(stored, d.Computed)
+	r.Equal(&wrong, d.Stored)
+	r.Nil(d.Header)
+
+	_, err = ct.Verify(0, ct.height+1, nil, nil, nil)
+	r.Error(err)
+
+	// the ClaimTrie is only locked for each height, so it can be reset in between
+	r.NoError(ct.blockRepo.Set(12, stored))
+	locks := 0
+	locker := &testLocker{lock: func() {
+		if locks++; locks == 3 {
+			r.NoError(ct.ResetHeight(6))
+		}
+	}}
+	_, err = ct.Verify(5, ct.height, nil, locker, nil)
+	r.Error(err)
+	r.Equal(3, locks)
+}
+
+// testLocker calls lock when it's locked.
+type testLocker struct {
+	lock func()
+}
+
+func (l *testLocker) Lock()   { l.lock() }
+func (l *testLocker) Unlock() {}
@@ -19,7 +19,6 @@ import (
 	btcutil "github.com/lbryio/lbcutil"
 
 	"github.com/cockroachdb/errors"
-	"github.com/cockroachdb/pebble"
 	"github.com/spf13/cobra"
 )
 
@@ -62,9 +61,6 @@ func NewChainDumpCommand() *cobra.Command {
 
 			for height := fromHeight; height <= toHeight; height++ {
 				changes, err := chainRepo.Load(height)
-				if errors.Is(err, pebble.ErrNotFound) {
-					continue
-				}
 				if err != nil {
 					return errors.Wrapf(err, "load charnges for height: %d")
 				}
@@ -100,6 +96,7 @@ func NewChainReplayCommand() *cobra.Command {
 			for _, dbName := range []string{
 				cfg.BlockRepoPebble.Path,
 				cfg.NodeRepoPebble.Path,
+				cfg.SnapshotRepoPebble.Path,
 				cfg.MerkleTrieRepoPebble.Path,
 				cfg.TemporalRepoPebble.Path,
 			} {
@@ -141,9 +138,7 @@ func NewChainReplayCommand() *cobra.Command {
 			for ht := fromHeight; ht < toHeight; ht++ {
 
 				changes, err := chainRepo.Load(ht + 1)
-				if errors.Is(err, pebble.ErrNotFound) {
-					// do nothing.
-				} else if err != nil {
+				if err != nil {
 					return errors.Wrapf(err, "load changes for block %d", ht)
 				}
 
@@ -160,6 +155,8 @@ func NewChainReplayCommand() *cobra.Command {
 						err = ct.AddSupport(chg.Name, chg.OutPoint, chg.Amount, chg.ClaimID)
 					case change.SpendSupport:
 						err = ct.SpendSupport(chg.Name, chg.OutPoint, chg.ClaimID)
+					case change.ActivateClaim, change.ExpireClaim, change.ActivateSupport, change.ExpireSupport:
+						// the blocks bring them about by themselves
 					default:
 						err = errors.Errorf("invalid change type: %v", chg)
 					}
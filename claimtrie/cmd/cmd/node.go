@@ -45,8 +45,9 @@ func NewNodeDumpCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			dbPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.NodeRepoPebble.Path)
+			snapshotPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.SnapshotRepoPebble.Path)
 			log.Debugf("Open node repo: %q", dbPath)
-			repo, err := noderepo.NewPebble(dbPath)
+			repo, err := noderepo.NewPebble(dbPath, snapshotPath)
 			if err != nil {
 				return errors.Wrapf(err, "open node repo")
 			}
@@ -87,8 +88,9 @@ func NewNodeReplayCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			dbPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.NodeRepoPebble.Path)
+			snapshotPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.SnapshotRepoPebble.Path)
 			log.Debugf("Open node repo: %q", dbPath)
-			repo, err := noderepo.NewPebble(dbPath)
+			repo, err := noderepo.NewPebble(dbPath, snapshotPath)
 			if err != nil {
 				return errors.Wrapf(err, "open node repo")
 			}
@@ -130,8 +132,9 @@ func NewNodeChildrenCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			dbPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.NodeRepoPebble.Path)
+			snapshotPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.SnapshotRepoPebble.Path)
 			log.Debugf("Open node repo: %q", dbPath)
-			repo, err := noderepo.NewPebble(dbPath)
+			repo, err := noderepo.NewPebble(dbPath, snapshotPath)
 			if err != nil {
 				return errors.Wrapf(err, "open node repo")
 			}
@@ -167,8 +170,9 @@ func NewNodeStatsCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			dbPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.NodeRepoPebble.Path)
+			snapshotPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.SnapshotRepoPebble.Path)
 			log.Debugf("Open node repo: %q", dbPath)
-			repo, err := noderepo.NewPebble(dbPath)
+			repo, err := noderepo.NewPebble(dbPath, snapshotPath)
 			if err != nil {
 				return errors.Wrapf(err, "open node repo")
 			}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lbryio/lbcd/claimtrie"
+	"github.com/lbryio/lbcd/claimtrie/config"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewSnapshotCommands())
+}
+
+func NewSnapshotCommands() *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export or import the claimtrie state at a height",
+	}
+
+	cmd.AddCommand(NewSnapshotExportCommand())
+	cmd.AddCommand(NewSnapshotImportCommand())
+
+	return cmd
+}
+
+func NewSnapshotExportCommand() *cobra.Command {
+
+	var height int32
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the nodes and temporal entries at <height> to <file>",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			cfg := config.DefaultConfig
+			cfg.RamTrie = true
+			cfg.DataDir = filepath.Join(dataDir, netName)
+
+			ct, err := claimtrie.New(cfg)
+			if err != nil {
+				return errors.Wrapf(err, "create claimtrie")
+			}
+			defer ct.Close()
+
+			if height <= 0 {
+				height = ct.Height()
+			}
+
+			f, err := os.Create(file)
+			if err != nil {
+				return errors.Wrapf(err, "create file")
+			}
+			defer f.Close()
+
+			startTime := time.Now()
+			err = ct.Export(height, f)
+			if err != nil {
+				return errors.Wrapf(err, "export claimtrie")
+			}
+			log.Infof("Exported the claimtrie at height %d to %s (%s)", height, file, time.Since(startTime))
+
+			return f.Close()
+		},
+	}
+
+	cmd.Flags().Int32Var(&height, "height", 0, "Height (default: the claimtrie height)")
+	cmd.Flags().StringVar(&file, "file", "claimtrie.snapshot", "Snapshot file")
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
+
+func NewSnapshotImportCommand() *cobra.Command {
+
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Load an exported <file> into empty claimtrie databases and verify it against the block header",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			cfg := config.DefaultConfig
+			cfg.RamTrie = true
+			cfg.DataDir = filepath.Join(dataDir, netName)
+
+			db, err := loadBlocksDB()
+			if err != nil {
+				return errors.Wrapf(err, "load blocks database")
+			}
+			defer db.Close()
+
+			chain, err := loadChain(db)
+			if err != nil {
+				return errors.Wrapf(err, "load chain")
+			}
+
+			f, err := os.Open(file)
+			if err != nil {
+				return errors.Wrapf(err, "open file")
+			}
+			defer f.Close()
+
+			startTime := time.Now()
+			height, hash, err := claimtrie.Import(cfg, f)
+			if err != nil {
+				return errors.Wrapf(err, "import claimtrie")
+			}
+
+			blockHash, err := chain.BlockHashByHeight(height)
+			if err != nil {
+				return errors.Wrapf(err, "load block hash at %d", height)
+			}
+			header, err := chain.HeaderByHash(blockHash)
+			if err != nil {
+				return errors.Wrapf(err, "load header at %d", height)
+			}
+			if *hash != header.ClaimTrie {
+				return errors.Errorf("hash mismatched at height %5d: exp: %s, got: %s; delete %s before trying again",
+					height, header.ClaimTrie, hash, filepath.Join(cfg.DataDir, "claim_dbs"))
+			}
+
+			// opening it recomputes the MerkleHash from the imported nodes
+			ct, err := claimtrie.New(cfg)
+			if err != nil {
+				return errors.Wrapf(err, "verify claimtrie")
+			}
+			ct.Close()
+
+			log.Infof("Imported the claimtrie at height %d from %s (%s)", height, file, time.Since(startTime))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "claimtrie.snapshot", "Snapshot file")
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
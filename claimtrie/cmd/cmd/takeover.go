@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"math"
+	"path/filepath"
+
+	"github.com/lbryio/lbcd/claimtrie/takeover/takeoverrepo"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewTakeoverCommand())
+}
+
+func NewTakeoverCommand() *cobra.Command {
+
+	var name string
+	var fromHeight int32
+	var toHeight int32
+
+	cmd := &cobra.Command{
+		Use:   "takeover",
+		Short: "List the takeovers of <name> in a range of heights (requires the takeover index)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			dbPath := filepath.Join(dataDir, netName, "claim_dbs", cfg.TakeoverRepoPebble.Path)
+			log.Debugf("Open takeover repo: %s", dbPath)
+			repo, err := takeoverrepo.NewPebble(dbPath)
+			if err != nil {
+				return errors.Wrapf(err, "open takeover repo")
+			}
+			defer repo.Close()
+
+			takeovers, err := repo.Takeovers([]byte(name), fromHeight, toHeight)
+			if err != nil {
+				return errors.Wrapf(err, "get takeovers")
+			}
+
+			for _, t := range takeovers {
+				showTakeover(t)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Name")
+	cmd.MarkFlagRequired("name")
+	cmd.Flags().Int32Var(&fromHeight, "from", 0, "From height (inclusive)")
+	cmd.Flags().Int32Var(&toHeight, "to", math.MaxInt32, "To height (inclusive)")
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
@@ -26,6 +26,14 @@ func changeType(c change.ChangeType) string {
 		return "AddSupport"
 	case change.SpendSupport:
 		return "SpendSupport"
+	case change.ActivateClaim:
+		return "ActivateClaim"
+	case change.ExpireClaim:
+		return "ExpireClaim"
+	case change.ActivateSupport:
+		return "ActivateSupport"
+	case change.ExpireSupport:
+		return "ExpireSupport"
 	}
 	return "Unknown"
 }
@@ -35,6 +43,11 @@ func showChange(chg change.Change) {
 		chg.Height, changeType(chg.Type), chg.ClaimID, chg.Amount, chg.OutPoint, chg.Name)
 }
 
+func showTakeover(t node.Takeover) {
+	fmt.Printf("Height: %7d, %s (%15d) -> %s (%15d)\n",
+		t.Height, t.PreviousClaimID, t.PreviousAmount, t.ClaimID, t.Amount)
+}
+
 func showClaim(c *node.Claim, n *node.Node) {
 	mark := " "
 	if c == n.BestClaim {
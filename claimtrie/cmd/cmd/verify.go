@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie"
+	"github.com/lbryio/lbcd/claimtrie/config"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewVerifyCommand())
+}
+
+func NewVerifyCommand() *cobra.Command {
+
+	var fromHeight int32
+	var toHeight int32
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Recompute the claimtrie hash between <from_height> <to_height> and compare it with the stored hash and the block header",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			cfg := config.DefaultConfig
+			cfg.RamTrie = true
+			cfg.SkipTrieRestore = true
+			cfg.DataDir = filepath.Join(dataDir, netName)
+
+			db, err := loadBlocksDB()
+			if err != nil {
+				return errors.Wrapf(err, "load blocks database")
+			}
+			defer db.Close()
+
+			chain, err := loadChain(db)
+			if err != nil {
+				return errors.Wrapf(err, "load chain")
+			}
+
+			ct, err := claimtrie.New(cfg)
+			if err != nil {
+				return errors.Wrapf(err, "create claimtrie")
+			}
+			defer ct.Close()
+
+			if toHeight <= 0 || toHeight > ct.Height() {
+				toHeight = ct.Height()
+			}
+			header := func(height int32) (*chainhash.Hash, error) {
+				if height > chain.BestSnapshot().Height {
+					return nil, nil
+				}
+				hash, err := chain.BlockHashByHeight(height)
+				if err != nil {
+					return nil, err
+				}
+				h, err := chain.HeaderByHash(hash)
+				if err != nil {
+					return nil, err
+				}
+				return &h.ClaimTrie, nil
+			}
+
+			interrupt := make(chan struct{})
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt)
+			defer signal.Stop(signals)
+			go func() {
+				if _, ok := <-signals; ok {
+					close(interrupt)
+				}
+			}()
+
+			startTime := time.Now()
+			d, err := ct.Verify(fromHeight, toHeight, header, nil, interrupt)
+			if err != nil {
+				return errors.Wrapf(err, "verify claimtrie")
+			}
+			if d == nil {
+				log.Infof("Verified the claimtrie from height %d to %d (%s)", fromHeight, toHeight, time.Since(startTime))
+				return nil
+			}
+
+			fmt.Printf("Diverged at height %d:\n", d.Height)
+			fmt.Printf("  computed: %s\n", d.Computed)
+			fmt.Printf("  stored:   %s\n", d.Stored)
+			if d.Header != nil {
+				fmt.Printf("  header:   %s\n", d.Header)
+			}
+			for _, name := range d.Names {
+				fmt.Printf("  changed name: %q\n", name)
+			}
+			return errors.Errorf("the claimtrie diverged at height %d", d.Height)
+		},
+	}
+
+	cmd.Flags().Int32Var(&fromHeight, "from", 0, "From height (inclusive)")
+	cmd.Flags().Int32Var(&toHeight, "to", 0, "To height (inclusive; default: the claimtrie height)")
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
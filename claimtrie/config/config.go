@@ -20,12 +20,34 @@ var DefaultConfig = Config{
 	NodeRepoPebble: pebbleConfig{
 		Path: "node_change_pebble_db",
 	},
+	SnapshotRepoPebble: pebbleConfig{
+		Path: "node_snapshot_pebble_db",
+	},
 	TemporalRepoPebble: pebbleConfig{
 		Path: "temporal_pebble_db",
 	},
 	MerkleTrieRepoPebble: pebbleConfig{
 		Path: "merkletrie_pebble_db",
 	},
+	ClaimIDRepoPebble: pebbleConfig{
+		Path: "claimid_pebble_db",
+	},
+	TakeoverRepoPebble: pebbleConfig{
+		Path: "takeover_pebble_db",
+	},
+	StatsRepoPebble: pebbleConfig{
+		Path: "stats_pebble_db",
+	},
+	ChainRepoPebble: pebbleConfig{
+		Path: "chain_pebble_db",
+	},
+	ChannelRepoPebble: pebbleConfig{
+		Path: "channel_pebble_db",
+	},
+	RankingRepoPebble: pebbleConfig{
+		Path: "ranking_pebble_db",
+	},
+	ParamsFile: "params.json",
 }
 
 // Config is the container of all configurations.
@@ -34,12 +56,55 @@ type Config struct {
 
 	RamTrie bool
 
+	// Memory keeps all the repos in RAM rather than in DataDir, so nothing is left behind,
+	// nor kept across restarts.
+	Memory bool
+
+	// SkipTrieRestore opens the ClaimTrie without rebuilding its merkle trie, or checking it against the
+	// stored hash, so that one whose hash can't be restored can be looked into with Verify.
+	// Its MerkleHash is meaningless then, and it must not be appended to.
+	SkipTrieRestore bool
+
+	// ClaimIDIndex enables the index of claim IDs to the names, latest versions and statuses of their claims.
+	ClaimIDIndex bool
+
+	// TakeoverIndex enables the log of takeovers for each name.
+	TakeoverIndex bool
+
+	// ChangeIndex enables the log of the changes each block made to the claims and supports.
+	ChangeIndex bool
+
+	// ChannelIndex enables the index of the stream claims each channel signed, which is built from the claim
+	// values rather than the changes, so it's kept up to date by the one parsing the blocks.
+	ChannelIndex bool
+
+	// RankingIndex enables the rankings of the names and claims by their effective amounts.
+	RankingIndex bool
+
+	// Stats enables the aggregate statistics of the names, claims and supports.
+	Stats bool
+
+	// PruneDepth, when positive, is the number of blocks below the tip that the history of
+	// the nodes is kept for. The ClaimTrie can't be reset below that.
+	PruneDepth int32
+
 	DataDir string
 
 	BlockRepoPebble      pebbleConfig
 	NodeRepoPebble       pebbleConfig
+	SnapshotRepoPebble   pebbleConfig
 	TemporalRepoPebble   pebbleConfig
 	MerkleTrieRepoPebble pebbleConfig
+	ClaimIDRepoPebble    pebbleConfig
+	TakeoverRepoPebble   pebbleConfig
+	StatsRepoPebble      pebbleConfig
+	ChainRepoPebble      pebbleConfig
+	ChannelRepoPebble    pebbleConfig
+	RankingRepoPebble    pebbleConfig
+
+	// ParamsFile records the ClaimTrie parameters the repos were built with, so that they aren't
+	// opened with others.
+	ParamsFile string
 
 	Interrupt <-chan struct{}
 }
@@ -0,0 +1,327 @@
+package claimtrie
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/wire"
+)
+
+// EventType describes what happened to a claim, a support, or a name in a block.
+type EventType int
+
+const (
+	ClaimAdded EventType = iota
+	ClaimUpdated
+	ClaimSpent
+	ClaimActivated
+	ClaimExpired
+	SupportAdded
+	SupportSpent
+	SupportActivated
+	SupportExpired
+	Takeover
+)
+
+// Event is something that happened to a claim, a support, or a name in a block.
+// Supports carry the ID of the claim they support. Takeovers carry the previous
+// and the new controlling claim IDs (zero for none) and no outpoint.
+type Event struct {
+	Type    EventType
+	Name    []byte
+	ClaimID change.ClaimID
+
+	OutPoint wire.OutPoint
+	Amount   int64
+
+	PreviousClaimID change.ClaimID
+}
+
+// Events returns what happened in the block at height to the claims, supports and names it touched,
+// including activations, expirations and takeovers that were scheduled for that height.
+// The height must not be past the current height.
+func (ct *ClaimTrie) Events(height int32) ([]Event, error) {
+
+	if height > ct.height {
+		return nil, errors.Errorf("height %d is past the tip %d", height, ct.height)
+	}
+
+	names, err := ct.temporalRepo.NodesAt(height)
+	if err != nil {
+		return nil, errors.Wrap(err, "temporal repo get")
+	}
+	names = removeDuplicates(names)
+
+	var events []Event
+	for _, name := range names {
+		events, err = ct.appendEvents(events, height, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// nodesAround returns the node of name before and after the block at height, and the changes the block made to it.
+// The node after is nil when the name has never had any claims.
+func (ct *ClaimTrie) nodesAround(height int32, name []byte) (before, after *node.Node, changes []change.Change, err error) {
+
+	before, err = ct.nodeManager.NodeAt(height-1, name)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "node before")
+	}
+	if before == nil {
+		before = node.New()
+	}
+	after, err = ct.nodeManager.NodeAt(height, name)
+	if err != nil || after == nil {
+		return before, nil, nil, errors.Wrap(err, "node after")
+	}
+	changes, err = ct.changesAt(height, name)
+	return before, after, changes, err
+}
+
+// changesAt returns the changes of name that were made at height, in order, including the copies made at
+// the normalization fork, which keep the heights the originals were accepted at and are visible from it.
+func (ct *ClaimTrie) changesAt(height int32, name []byte) ([]change.Change, error) {
+
+	all, err := ct.nodeRepo.LoadChanges(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "load changes")
+	}
+	var changes []change.Change
+	for _, chg := range all {
+		if chg.Height == height || chg.VisibleHeight == height {
+			changes = append(changes, chg)
+		}
+	}
+	return changes, nil
+}
+
+func (ct *ClaimTrie) appendEvents(events []Event, height int32, name []byte) ([]Event, error) {
+
+	before, after, changes, err := ct.nodesAround(height, name)
+	if err != nil || after == nil {
+		return events, err
+	}
+
+	spent := map[wire.OutPoint]bool{}
+	for i, chg := range changes {
+		if chg.Height != height {
+			continue
+		}
+		ev := Event{Name: name, ClaimID: chg.ClaimID, OutPoint: chg.OutPoint, Amount: chg.Amount}
+		switch chg.Type {
+		case change.AddClaim:
+			ev.Type = ClaimAdded
+		case change.UpdateClaim:
+			ev.Type = ClaimUpdated
+		case change.AddSupport:
+			ev.Type = SupportAdded
+		case change.SpendClaim:
+			spent[chg.OutPoint] = true
+			if updatedLater(changes[i+1:], chg.ClaimID) {
+				continue // reported as an update
+			}
+			ev.Type = ClaimSpent
+		case change.SpendSupport:
+			spent[chg.OutPoint] = true
+			ev.Type = SupportSpent
+		}
+		events = append(events, ev)
+	}
+
+	events = appendStatusEvents(events, name, before.Claims, after.Claims, spent, ClaimActivated, ClaimExpired, claimKey)
+	events = appendStatusEvents(events, name, before.Supports, after.Supports, spent, SupportActivated, SupportExpired, supportKey)
+
+	if after.TakenOverAt == height {
+		ev := Event{Type: Takeover, Name: name}
+		if before.HasActiveBestClaim() {
+			ev.PreviousClaimID = before.BestClaim.ClaimID
+		}
+		if after.BestClaim != nil {
+			ev.ClaimID = after.BestClaim.ClaimID
+			ev.OutPoint = after.BestClaim.OutPoint
+			ev.Amount = after.BestClaim.Amount + after.SupportSums[ev.ClaimID.Key()]
+		}
+		if ev.ClaimID != ev.PreviousClaimID || ev.ClaimID != (change.ClaimID{}) {
+			events = append(events, ev)
+		}
+	}
+	return events, nil
+}
+
+// statusChangeTypes are the changes the activations and expirations are logged as.
+var statusChangeTypes = map[EventType]change.ChangeType{
+	ClaimActivated:   change.ActivateClaim,
+	ClaimExpired:     change.ExpireClaim,
+	SupportActivated: change.ActivateSupport,
+	SupportExpired:   change.ExpireSupport,
+}
+
+// blockChanges returns the changes the block at height made to the claims and supports of the names: the ones
+// of its transactions and of the normalization fork, with the active and visible heights they resulted in,
+// followed by the activations and expirations that were due. The ones that are gone by the end of the block
+// keep the heights they were made with.
+func (ct *ClaimTrie) blockChanges(height int32, names [][]byte) ([]change.Change, error) {
+
+	var changes []change.Change
+	for _, name := range names {
+		before, after, made, err := ct.nodesAround(height, name)
+		if err != nil {
+			return nil, err
+		}
+		if after == nil {
+			continue
+		}
+
+		stakes := map[wire.OutPoint]*node.Claim{}
+		for _, list := range []node.ClaimList{before.Claims, before.Supports, after.Claims, after.Supports} {
+			for _, c := range list {
+				stakes[c.OutPoint] = c
+			}
+		}
+		current := map[wire.OutPoint]*node.Claim{}
+		for _, list := range []node.ClaimList{after.Claims, after.Supports} {
+			for _, c := range list {
+				current[c.OutPoint] = c
+			}
+		}
+
+		spent := map[wire.OutPoint]bool{}
+		for _, chg := range made {
+			chg.Name, chg.SpentChildren = name, nil
+			if chg.Type == change.SpendClaim || chg.Type == change.SpendSupport {
+				spent[chg.OutPoint] = true
+			} else {
+				if c := current[chg.OutPoint]; c != nil {
+					chg.ActiveHeight = c.ActiveAt
+				}
+				if chg.VisibleHeight <= 0 {
+					chg.VisibleHeight = chg.Height
+				}
+			}
+			changes = append(changes, chg)
+		}
+
+		var due []Event
+		due = appendStatusEvents(due, name, before.Claims, after.Claims, spent, ClaimActivated, ClaimExpired, claimKey)
+		due = appendStatusEvents(due, name, before.Supports, after.Supports, spent, SupportActivated, SupportExpired, supportKey)
+		for _, e := range due {
+			c := stakes[e.OutPoint]
+			changes = append(changes, change.Change{
+				Type:          statusChangeTypes[e.Type],
+				Height:        height,
+				Name:          name,
+				ClaimID:       e.ClaimID,
+				OutPoint:      e.OutPoint,
+				Amount:        e.Amount,
+				ActiveHeight:  c.ActiveAt,
+				VisibleHeight: c.VisibleAt,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// updatedLater reports whether the claim is updated later in the block; changes end with that block.
+func updatedLater(changes []change.Change, id change.ClaimID) bool {
+	for _, chg := range changes {
+		if chg.Type == change.UpdateClaim && chg.ClaimID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func appendStatusEvents(events []Event, name []byte, before, after node.ClaimList, spent map[wire.OutPoint]bool,
+	activated, expired EventType, key func(c *node.Claim) string) []Event {
+
+	was := make(map[string]*node.Claim, len(before))
+	for _, c := range before {
+		was[key(c)] = c
+	}
+	for _, c := range after {
+		if c.Status == node.Activated && (was[key(c)] == nil || was[key(c)].Status != node.Activated) {
+			events = append(events, Event{Type: activated, Name: name, ClaimID: c.ClaimID, OutPoint: c.OutPoint, Amount: c.Amount})
+		}
+		delete(was, key(c))
+	}
+	for _, c := range before { // iterate the list rather than the map to keep the order stable
+		if was[key(c)] != nil && !spent[c.OutPoint] {
+			events = append(events, Event{Type: expired, Name: name, ClaimID: c.ClaimID, OutPoint: c.OutPoint, Amount: c.Amount})
+		}
+	}
+	return events
+}
+
+// Claims keep their ID across updates; supports are only known by their outpoint.
+func claimKey(c *node.Claim) string   { return c.ClaimID.Key() }
+func supportKey(c *node.Claim) string { return c.OutPoint.String() }
+
+// ScheduledEvent is an activation or an expiration of a claim or a support that is due at a height past the current one.
+type ScheduledEvent struct {
+	Height int32
+	Event
+}
+
+// ScheduledEvents returns the activations and expirations of claims and supports that are due from fromHeight
+// to toHeight, inclusive, in height order. They are as things stand at the current height, which both heights
+// must be past; the blocks to come can add to them, move them, or cancel them.
+func (ct *ClaimTrie) ScheduledEvents(fromHeight, toHeight int32) ([]ScheduledEvent, error) {
+
+	if fromHeight <= ct.height || fromHeight > toHeight {
+		return nil, errors.Errorf("invalid range from %d to %d with the tip at %d", fromHeight, toHeight, ct.height)
+	}
+
+	// a name with anything due in the range is scheduled for an update no later than that
+	var names [][]byte
+	for h := ct.height + 1; h <= toHeight; h++ {
+		scheduled, err := ct.temporalRepo.NodesAt(h)
+		if err != nil {
+			return nil, errors.Wrap(err, "temporal repo get")
+		}
+		names = append(names, scheduled...)
+		if h == math.MaxInt32 {
+			break // h++ would wrap around
+		}
+	}
+	names = removeDuplicates(names)
+
+	var events []ScheduledEvent
+	for _, name := range names {
+		n, err := ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			return nil, errors.Wrap(err, "node manager get")
+		}
+		if n == nil {
+			continue
+		}
+		n = n.Clone() // it's adjusted in place
+		for h := n.NextUpdate(); h <= toHeight; h = n.NextUpdate() {
+			if h == math.MaxInt32 {
+				break // NextUpdate returns it when nothing is due
+			}
+			before := n.Clone()
+			n.AdjustTo(h, h, name)
+			if h < fromHeight {
+				continue
+			}
+			var due []Event
+			due = appendStatusEvents(due, name, before.Claims, n.Claims, nil, ClaimActivated, ClaimExpired, claimKey)
+			due = appendStatusEvents(due, name, before.Supports, n.Supports, nil, SupportActivated, SupportExpired, supportKey)
+			for _, e := range due {
+				events = append(events, ScheduledEvent{Height: h, Event: e})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Height < events[j].Height
+	})
+	return events, nil
+}
@@ -0,0 +1,330 @@
+package claimtrie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/block/blockrepo"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/config"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/node/noderepo"
+	"github.com/lbryio/lbcd/claimtrie/normalization"
+	"github.com/lbryio/lbcd/claimtrie/temporal/temporalrepo"
+)
+
+// Export file format, all integers big endian:
+//
+//	header:   exportMagic(8B) + height(4B) + merkle hash(32B)
+//	node:     'n' + len(name)(2B) + name + count(4B) + count * (len(change)(4B) + change)
+//	block:    'b' + height(4B) + merkle hash(32B)
+//	temporal: 't' + len(name)(2B) + name + height(4B)
+//	end:      'e'
+var exportMagic = []byte("lbcdct\x00\x02")
+
+// ErrImportNotEmpty is returned by Import when the ClaimTrie already has blocks.
+var ErrImportNotEmpty = errors.New("the claimtrie is not empty")
+
+const (
+	exportNode     = 'n'
+	exportBlock    = 'b'
+	exportTemporal = 't'
+	exportEnd      = 'e'
+)
+
+// exportDepth is the number of blocks below the exported height that an imported ClaimTrie can be reset by.
+const exportDepth = 200
+
+// Export writes the changes of every node and the upcoming updates of the names, as of the specified
+// height, to w, along with the hashes and the updated names of the exportDepth blocks below it.
+// A ClaimTrie imported from it starts at that height and can't be reset further than those blocks.
+func (ct *ClaimTrie) Export(height int32, w io.Writer) error {
+
+	if height <= 0 || height > ct.height {
+		return errors.Errorf("height %d is not between 1 and the tip %d", height, ct.height)
+	}
+	if ct.nodeManager.PrunedHeight() > 0 {
+		return errors.Wrap(node.ErrPruned, "the export needs all of it")
+	}
+	hash, err := ct.blockRepo.Get(height)
+	if err != nil {
+		return errors.Wrap(err, "block repo get")
+	}
+
+	bw := bufio.NewWriter(w)
+	var temp [4]byte
+	bw.Write(exportMagic)
+	binary.BigEndian.PutUint32(temp[:], uint32(height))
+	bw.Write(temp[:])
+	bw.Write(hash[:])
+
+	buffer := bytes.NewBuffer(nil)
+	var nextNames [][]byte
+	var nextHeights []int32
+	iterErr := ct.nodeRepo.IterateChildren(nil, func(changes []change.Change) bool {
+		count := 0
+		for _, chg := range changes {
+			// changes back-dated at the normalization fork come after the height that got them
+			if chg.Height <= height && chg.VisibleHeight <= height {
+				changes[count] = chg
+				count++
+			}
+		}
+		if count == 0 {
+			return true
+		}
+		changes = changes[:count]
+		name := make([]byte, len(changes[0].Name))
+		copy(name, changes[0].Name) // iteration name buffer is reused on future loops
+
+		writeName(bw, exportNode, name)
+		binary.BigEndian.PutUint32(temp[:], uint32(count))
+		bw.Write(temp[:])
+		for i := range changes {
+			buffer.Reset()
+			if err = changes[i].Marshal(buffer); err != nil {
+				return false
+			}
+			binary.BigEndian.PutUint32(temp[:], uint32(buffer.Len()))
+			bw.Write(temp[:])
+			bw.Write(buffer.Bytes())
+		}
+
+		// only the next update is needed; AppendBlock schedules the ones after it
+		var n *node.Node
+		n, err = ct.nodeManager.NodeAt(height, name)
+		if err != nil {
+			return false
+		}
+		if n != nil && n.NextUpdate() > height {
+			next := n.NextUpdate()
+			nextNames = append(nextNames, normalization.NormalizeIfNecessary(name, next))
+			nextHeights = append(nextHeights, next)
+		}
+		return true
+	})
+	if iterErr != nil {
+		return errors.Wrap(iterErr, "node repo iterate")
+	}
+	if err != nil {
+		return errors.Wrap(err, "export node")
+	}
+
+	// ResetHeight needs the hash of the height it goes to and the names updated above it; 0 has the empty hash
+	low := height - exportDepth
+	if low < 0 {
+		low = 0
+	}
+	for h := low + 1; h <= height; h++ {
+		if h < height {
+			blockHash, err := ct.blockRepo.Get(h)
+			if err != nil {
+				return errors.Wrap(err, "block repo get")
+			}
+			bw.WriteByte(exportBlock)
+			binary.BigEndian.PutUint32(temp[:], uint32(h))
+			bw.Write(temp[:])
+			bw.Write(blockHash[:])
+		}
+		names, err := ct.temporalRepo.NodesAt(h)
+		if err != nil {
+			return errors.Wrap(err, "temporal repo get")
+		}
+		for _, name := range names {
+			nextNames = append(nextNames, name)
+			nextHeights = append(nextHeights, h)
+		}
+	}
+
+	for i, name := range nextNames {
+		writeName(bw, exportTemporal, name)
+		binary.BigEndian.PutUint32(temp[:], uint32(nextHeights[i]))
+		bw.Write(temp[:])
+	}
+	bw.WriteByte(exportEnd)
+
+	return errors.Wrap(bw.Flush(), "write")
+}
+
+func writeName(w *bufio.Writer, typ byte, name []byte) {
+	var temp [2]byte
+	w.WriteByte(typ)
+	binary.BigEndian.PutUint16(temp[:], uint16(len(name)))
+	w.Write(temp[:])
+	w.Write(name)
+}
+
+// Import loads a ClaimTrie written by Export into the repos of cfg, which must not have any blocks yet,
+// and returns its height and MerkleHash. It can be reset by up to exportDepth blocks. The hash is checked against the imported nodes when New opens
+// them; it's up to the caller to check it against the ClaimTrie of the block header at that height.
+// There is nothing to import into when cfg.Memory is set, as the repos only live as long as a ClaimTrie.
+// A failed import removes the repos it wrote to, so it can be tried again.
+func Import(cfg config.Config, r io.Reader) (int32, *chainhash.Hash, error) {
+
+	if cfg.Memory {
+		return 0, nil, errors.New("unable to import into memory")
+	}
+
+	br := bufio.NewReader(r)
+	header := make([]byte, len(exportMagic)+4+chainhash.HashSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return 0, nil, errors.Wrap(err, "read header")
+	}
+	if !bytes.Equal(header[:len(exportMagic)], exportMagic) {
+		return 0, nil, errors.New("not a claimtrie export")
+	}
+	height := int32(binary.BigEndian.Uint32(header[len(exportMagic):]))
+	hash, err := chainhash.NewHash(header[len(exportMagic)+4:])
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "read hash")
+	}
+
+	dataDir := filepath.Join(cfg.DataDir, "claim_dbs")
+	written, err := importRepos(cfg, dataDir, br, height, hash)
+	if err != nil && written {
+		// a partial import would pass for a ClaimTrie with some of the nodes; the repos were empty before it
+		for _, path := range []string{cfg.BlockRepoPebble.Path, cfg.NodeRepoPebble.Path,
+			cfg.SnapshotRepoPebble.Path, cfg.TemporalRepoPebble.Path} {
+			if rerr := os.RemoveAll(filepath.Join(dataDir, path)); rerr != nil {
+				return 0, nil, errors.Errorf("%s, and removing the partial import failed: %s", err, rerr)
+			}
+		}
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return height, hash, nil
+}
+
+// importRepos writes the records read from br into the repos in dataDir, which must be empty.
+// It reports whether it got to write anything, which is only once the repos are known to be empty.
+func importRepos(cfg config.Config, dataDir string, br *bufio.Reader, height int32, hash *chainhash.Hash) (bool, error) {
+
+	blockRepo, err := blockrepo.NewPebble(filepath.Join(dataDir, cfg.BlockRepoPebble.Path))
+	if err != nil {
+		return false, errors.Wrap(err, "creating block repo")
+	}
+	defer blockRepo.Close()
+	previous, err := blockRepo.Load()
+	if err != nil {
+		return false, errors.Wrap(err, "load block tip")
+	}
+	if previous > 0 {
+		return false, ErrImportNotEmpty
+	}
+
+	temporalRepo, err := temporalrepo.NewPebble(filepath.Join(dataDir, cfg.TemporalRepoPebble.Path))
+	if err != nil {
+		return false, errors.Wrap(err, "creating temporal repo")
+	}
+	defer temporalRepo.Close()
+
+	nodeRepo, err := noderepo.NewPebble(filepath.Join(dataDir, cfg.NodeRepoPebble.Path),
+		filepath.Join(dataDir, cfg.SnapshotRepoPebble.Path))
+	if err != nil {
+		return false, errors.Wrap(err, "creating node repo")
+	}
+	defer nodeRepo.Close()
+	empty := true
+	nodeRepo.IterateAll(func(name []byte) bool {
+		empty = false
+		return false
+	})
+	if !empty {
+		return false, ErrImportNotEmpty
+	}
+
+	var changes []change.Change
+	var names [][]byte
+	var heights []int32
+	for {
+		typ, err := br.ReadByte()
+		if err != nil {
+			return true, errors.Wrap(err, "read record")
+		}
+		if typ == exportEnd {
+			break
+		}
+		if typ == exportBlock {
+			record := make([]byte, 4+chainhash.HashSize)
+			if _, err = io.ReadFull(br, record); err != nil {
+				return true, errors.Wrap(err, "read block")
+			}
+			blockHash, err := chainhash.NewHash(record[4:])
+			if err != nil {
+				return true, errors.Wrap(err, "read block")
+			}
+			if err = blockRepo.Set(int32(binary.BigEndian.Uint32(record)), blockHash); err != nil {
+				return true, errors.Wrap(err, "block repo set")
+			}
+			continue
+		}
+
+		name, err := readName(br)
+		if err != nil {
+			return true, err
+		}
+		var temp [4]byte
+		if _, err = io.ReadFull(br, temp[:]); err != nil {
+			return true, errors.Wrap(err, "read record")
+		}
+		value := binary.BigEndian.Uint32(temp[:])
+
+		switch typ {
+		case exportNode:
+			for i := uint32(0); i < value; i++ {
+				if _, err = io.ReadFull(br, temp[:]); err != nil {
+					return true, errors.Wrap(err, "read change")
+				}
+				data := make([]byte, binary.BigEndian.Uint32(temp[:]))
+				if _, err = io.ReadFull(br, data); err != nil {
+					return true, errors.Wrap(err, "read change")
+				}
+				chg := change.Change{Name: name}
+				if err = chg.Unmarshal(bytes.NewBuffer(data)); err != nil {
+					return true, errors.Wrap(err, "unmarshal change")
+				}
+				changes = append(changes, chg)
+			}
+			if len(changes) >= 10000 {
+				if err = nodeRepo.AppendChanges(changes); err != nil {
+					return true, errors.Wrap(err, "node repo append")
+				}
+				changes = changes[:0]
+			}
+		case exportTemporal:
+			names = append(names, name)
+			heights = append(heights, int32(value))
+		default:
+			return true, errors.Errorf("unknown record type %q", typ)
+		}
+	}
+
+	if err = nodeRepo.AppendChanges(changes); err != nil {
+		return true, errors.Wrap(err, "node repo append")
+	}
+	if err = temporalRepo.SetNodesAt(names, heights); err != nil {
+		return true, errors.Wrap(err, "temporal repo set")
+	}
+	if err = blockRepo.Set(height, hash); err != nil {
+		return true, errors.Wrap(err, "block repo set")
+	}
+	return true, nil
+}
+
+func readName(r *bufio.Reader) ([]byte, error) {
+	var temp [2]byte
+	if _, err := io.ReadFull(r, temp[:]); err != nil {
+		return nil, errors.Wrap(err, "read name")
+	}
+	name := make([]byte, binary.BigEndian.Uint16(temp[:]))
+	_, err := io.ReadFull(r, name)
+	return name, errors.Wrap(err, "read name")
+}
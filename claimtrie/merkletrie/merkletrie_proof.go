@@ -0,0 +1,138 @@
+package merkletrie
+
+import (
+	"errors"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/proof"
+)
+
+var ErrNameNotFound = errors.New("name is not in the trie")
+
+// loadNode reads the stored children and claims hash of the vertex at key with the given hash.
+func (t *PersistentTrie) loadNode(key []byte, hash *chainhash.Hash) ([]proof.Child, *chainhash.Hash, error) {
+	if hash == nil {
+		return nil, nil, nil
+	}
+	k := make([]byte, 0, len(key)+chainhash.HashSize)
+	k = append(k, key...)
+	k = append(k, hash[:]...)
+
+	result, closer, err := t.repo.Get(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result == nil {
+		return nil, nil, ErrNameNotFound
+	}
+	defer closer.Close()
+
+	nb := nbuf(result)
+	_, claimsHash := nb.hasValue()
+	children := make([]proof.Child, 0, nb.entries())
+	for i := 0; i < nb.entries(); i++ {
+		p, h := nb.entry(i)
+		children = append(children, proof.Child{Character: p, Hash: *h})
+	}
+	return children, claimsHash, nil
+}
+
+// Proof returns the path of nodes from the root to name in the pre-fork layout.
+// The path stops early at the deepest existing prefix when name is absent.
+// MerkleHash must have been called since the last Update.
+func (t *PersistentTrie) Proof(name []byte) ([]proof.Node, error) {
+	nodes := make([]proof.Node, 0, len(name)+1)
+	hash := t.root.merkleHash
+	if hash.IsEqual(EmptyTrieHash) {
+		return append(nodes, proof.Node{}), nil
+	}
+	for i := 0; i <= len(name); i++ {
+		children, claimsHash, err := t.loadNode(name[:i], hash)
+		if err != nil {
+			return nil, err
+		}
+		pn := proof.Node{ValueHash: claimsHash}
+		hash = nil
+		for _, c := range children {
+			if i < len(name) && c.Character == name[i] {
+				h := c.Hash
+				hash = &h
+				continue
+			}
+			pn.Children = append(pn.Children, c)
+		}
+		if hash == nil {
+			pn.Children = children
+			return append(nodes, pn), nil
+		}
+		nodes = append(nodes, pn)
+	}
+	return nodes, nil
+}
+
+// ProofAllClaims returns the pairs that lead from the claims hash of name to the root in the post-fork layout.
+// MerkleHashAllClaims must have been called since the last Update.
+func (t *PersistentTrie) ProofAllClaims(name []byte) ([]proof.Pair, error) {
+	type level struct {
+		children   []proof.Child
+		claimsHash *chainhash.Hash
+	}
+	levels := make([]level, 0, len(name)+1)
+	hash := t.root.merkleHash
+	for i := 0; i <= len(name); i++ {
+		children, claimsHash, err := t.loadNode(name[:i], hash)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, level{children, claimsHash})
+		if i == len(name) {
+			break
+		}
+		hash = nil
+		for _, c := range children {
+			if c.Character == name[i] {
+				h := c.Hash
+				hash = &h
+				break
+			}
+		}
+		if hash == nil {
+			return nil, ErrNameNotFound
+		}
+	}
+	if levels[len(levels)-1].claimsHash == nil {
+		return nil, ErrNameNotFound
+	}
+
+	var pairs []proof.Pair
+	for i := len(levels) - 1; i >= 0; i-- {
+		l := levels[i]
+		hashes := make([]*chainhash.Hash, 0, len(l.children))
+		index := -1
+		for j := range l.children {
+			if i < len(name) && l.children[j].Character == name[i] {
+				index = j
+			}
+			hashes = append(hashes, &l.children[j].Hash)
+		}
+		if i == len(levels)-1 {
+			childHash := NoChildrenHash
+			if len(hashes) > 0 {
+				childHash = node.ComputeMerkleRoot(hashes)
+			}
+			pairs = append(pairs, proof.Pair{Hash: *childHash, Right: false})
+			continue
+		}
+		if len(hashes) == 1 && l.claimsHash == nil {
+			continue // the hash was passed up the tree unchanged
+		}
+		pairs = append(pairs, proof.MerklePath(hashes, index)...)
+		claimsHash := NoClaimsHash
+		if l.claimsHash != nil {
+			claimsHash = l.claimsHash
+		}
+		pairs = append(pairs, proof.Pair{Hash: *claimsHash, Right: true})
+	}
+	return pairs, nil
+}
@@ -0,0 +1,41 @@
+package merkletrierepo
+
+import (
+	"io"
+)
+
+type Memory struct {
+	data map[string][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		data: map[string][]byte{},
+	}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+func (repo *Memory) Get(key []byte) ([]byte, io.Closer, error) {
+	value, ok := repo.data[string(key)]
+	if !ok {
+		return nil, nil, nil
+	}
+	return value, nopCloser{}, nil
+}
+
+func (repo *Memory) Set(key, value []byte) error {
+	// the callers reuse their buffers
+	repo.data[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
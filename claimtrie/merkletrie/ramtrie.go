@@ -8,6 +8,7 @@ import (
 
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
 	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/proof"
 )
 
 type MerkleTrie interface {
@@ -15,6 +16,8 @@ type MerkleTrie interface {
 	Update(name []byte, h *chainhash.Hash, restoreChildren bool)
 	MerkleHash() *chainhash.Hash
 	MerkleHashAllClaims() *chainhash.Hash
+	Proof(name []byte) ([]proof.Node, error)
+	ProofAllClaims(name []byte) ([]proof.Pair, error)
 	Flush() error
 }
 
@@ -0,0 +1,100 @@
+package merkletrie
+
+import (
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/proof"
+)
+
+// Proof returns the path of nodes from the root to name in the pre-fork layout.
+// The path stops early at the deepest existing prefix when name is absent.
+// MerkleHash must have been called since the last Update.
+func (rt *RamTrie) Proof(name []byte) ([]proof.Node, error) {
+	nodes := make([]proof.Node, 0, len(name)+1)
+	v := rt.Root
+	depth := 0
+	for {
+		if depth == len(name) {
+			return append(nodes, rt.proofNode(v, -1)), nil
+		}
+		_, child := v.findNearest(name[depth:])
+		if child == nil || child.key[0] != name[depth] {
+			return append(nodes, rt.proofNode(v, -1)), nil
+		}
+		nodes = append(nodes, rt.proofNode(v, int(name[depth])))
+
+		// walk the collapsed edge one byte at a time
+		for j := 1; j < len(child.key); j++ {
+			depth++
+			if depth == len(name) || child.key[j] != name[depth] {
+				h := edgeHash(child.merkleHash, child.key, j+1)
+				return append(nodes, proof.Node{Children: []proof.Child{{Character: child.key[j], Hash: *h}}}), nil
+			}
+			nodes = append(nodes, proof.Node{})
+		}
+		depth++
+		v = child
+	}
+}
+
+// proofNode returns the links of v except the one starting with skip (-1 keeps all).
+func (rt *RamTrie) proofNode(v *collapsedVertex, skip int) proof.Node {
+	pn := proof.Node{ValueHash: v.claimHash}
+	for _, ch := range v.children {
+		if int(ch.key[0]) == skip {
+			continue
+		}
+		pn.Children = append(pn.Children, proof.Child{Character: ch.key[0], Hash: *edgeHash(ch.merkleHash, ch.key, 1)})
+	}
+	return pn
+}
+
+// edgeHash returns the pre-fork hash of the uncollapsed node found after key[:from] on a collapsed edge.
+func edgeHash(h *chainhash.Hash, key KeyType, from int) *chainhash.Hash {
+	var data [chainhash.HashSize + 1]byte
+	copy(data[1:], h[:])
+	for i := len(key) - 1; i >= from; i-- {
+		data[0] = key[i]
+		copy(data[1:], chainhash.DoubleHashB(data[:]))
+	}
+	r := chainhash.Hash{}
+	copy(r[:], data[1:])
+	return &r
+}
+
+// ProofAllClaims returns the pairs that lead from the claims hash of name to the root in the post-fork layout.
+// MerkleHashAllClaims must have been called since the last Update.
+func (rt *RamTrie) ProofAllClaims(name []byte) ([]proof.Pair, error) {
+	indexes, path := rt.FindPath(name)
+	if path == nil || path[len(path)-1].claimHash == nil {
+		return nil, ErrNameNotFound
+	}
+
+	var pairs []proof.Pair
+	for i := len(path) - 1; i >= 0; i-- {
+		v := path[i]
+		if i < len(path)-1 {
+			pairs = append(pairs, proof.MerklePath(childHashes(v), indexes[i+1])...)
+			claimHash := NoClaimsHash
+			if v.claimHash != nil {
+				claimHash = v.claimHash
+			}
+			pairs = append(pairs, proof.Pair{Hash: *claimHash, Right: true})
+			continue
+		}
+		childHash := NoChildrenHash
+		if len(v.children) > 0 {
+			childHash = node.ComputeMerkleRoot(childHashes(v))
+		}
+		pairs = append(pairs, proof.Pair{Hash: *childHash, Right: false})
+	}
+	return pairs, nil
+}
+
+func childHashes(v *collapsedVertex) []*chainhash.Hash {
+	hashes := make([]*chainhash.Hash, 0, len(v.children))
+	for _, ch := range v.children {
+		hashes = append(hashes, ch.merkleHash)
+	}
+	return hashes
+}
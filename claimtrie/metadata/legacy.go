@@ -0,0 +1,245 @@
+package metadata
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcutil/base58"
+
+	"github.com/pkg/errors"
+)
+
+// The legacy claim types.
+const (
+	legacyStreamType      = 1
+	legacyCertificateType = 2
+)
+
+// decodeLegacyClaim decodes a claim of the first protobuf schema. Its signature is a field of the claim, and what
+// it covers is the claim without it.
+func (c *Claim) decodeLegacyClaim(b []byte) error {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	var claimType uint64
+	var stream, certificate []byte
+	for _, f := range fields {
+		switch f.tag {
+		case key(2, wireVarint):
+			claimType = f.value
+		case key(3, wireBytes):
+			stream = f.data
+		case key(4, wireBytes):
+			certificate = f.data
+		case key(5, wireBytes):
+			err = c.decodeLegacySignature(f.data)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		c.signed = append(c.signed, f.raw...)
+	}
+
+	switch claimType {
+	case legacyStreamType:
+		c.Type = TypeStream
+		return c.decodeLegacyStream(stream)
+	case legacyCertificateType:
+		c.Type = TypeChannel
+		c.Channel = &Channel{}
+		fields, err = parseFields(certificate)
+		for _, f := range fields {
+			if f.tag == key(4, wireBytes) {
+				c.Channel.PublicKey = f.data
+			}
+		}
+		return err
+	}
+	return errors.Errorf("unknown legacy claim type %d", claimType)
+}
+
+func (c *Claim) decodeLegacySignature(b []byte) error {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.tag {
+		case key(3, wireBytes):
+			c.Signature = f.data
+		case key(4, wireBytes):
+			// the certificate ID is the claim ID as it's written, the reverse of the claim scripts
+			if len(f.data) != change.ClaimIDSize {
+				return errors.Errorf("certificate ID of %d bytes", len(f.data))
+			}
+			for i, b := range f.data {
+				c.SigningChannel[change.ClaimIDSize-1-i] = b
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Claim) decodeLegacyStream(b []byte) error {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	c.Stream = &Stream{}
+	for _, f := range fields {
+		switch f.tag {
+		case key(2, wireBytes):
+			err = c.decodeLegacyMetadata(f.data)
+		case key(3, wireBytes):
+			err = c.decodeLegacySource(f.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Claim) decodeLegacyMetadata(b []byte) error {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.tag {
+		case key(3, wireBytes):
+			c.Title = f.str()
+		case key(4, wireBytes):
+			c.Description = f.str()
+		case key(5, wireBytes):
+			c.Stream.Author = f.str()
+		case key(6, wireBytes):
+			c.Stream.License = f.str()
+		case key(7, wireVarint):
+			if f.value != 0 {
+				c.Tags = append(c.Tags, "mature")
+			}
+		case key(8, wireBytes):
+			c.Stream.Fee, err = decodeLegacyFee(f.data)
+			if err != nil {
+				return err
+			}
+		case key(9, wireBytes):
+			c.Thumbnail = f.str()
+		case key(11, wireBytes):
+			c.Stream.LicenseURL = f.str()
+		}
+	}
+	return nil
+}
+
+func (c *Claim) decodeLegacySource(b []byte) error {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.tag {
+		case key(3, wireBytes):
+			c.Stream.SDHash = f.data
+		case key(4, wireBytes):
+			c.Stream.MediaType = f.str()
+		}
+	}
+	return nil
+}
+
+func decodeLegacyFee(b []byte) (*Fee, error) {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	fee := &Fee{}
+	var amount float64
+	for _, f := range fields {
+		switch f.tag {
+		case key(2, wireVarint):
+			fee.Currency = currencyNames[f.value]
+		case key(3, wireBytes):
+			fee.Address = base58.Encode(f.data)
+		case key(4, wireFixed32):
+			amount = float64(f.float32())
+		}
+	}
+	fee.Amount = legacyAmount(fee.Currency, amount)
+	return fee, nil
+}
+
+// legacyAmount converts a fee amount of the legacy formats, in the whole currency, to the smallest unit of it.
+func legacyAmount(currency string, amount float64) uint64 {
+	if currency == "USD" {
+		return uint64(math.Round(amount * 100))
+	}
+	return uint64(math.Round(amount * 1e8))
+}
+
+// jsonClaim is the metadata of the JSON claims, which were all streams.
+type jsonClaim struct {
+	Title          string            `json:"title"`
+	Description    string            `json:"description"`
+	Author         string            `json:"author"`
+	License        string            `json:"license"`
+	LicenseURL     string            `json:"license_url"`
+	Thumbnail      string            `json:"thumbnail"`
+	ContentType    string            `json:"content_type"`
+	OldContentType string            `json:"content-type"`
+	NSFW           bool              `json:"nsfw"`
+	Sources        map[string]string `json:"sources"`
+	Fee            map[string]struct {
+		Amount  float64 `json:"amount"`
+		Address string  `json:"address"`
+	} `json:"fee"`
+}
+
+func (c *Claim) decodeJSON(b []byte) error {
+
+	var j jsonClaim
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	c.Type = TypeStream
+	c.Title, c.Description, c.Thumbnail = j.Title, j.Description, j.Thumbnail
+	if j.NSFW {
+		c.Tags = append(c.Tags, "mature")
+	}
+	c.Stream = &Stream{
+		MediaType:  j.ContentType,
+		Author:     j.Author,
+		License:    j.License,
+		LicenseURL: j.LicenseURL,
+	}
+	if c.Stream.MediaType == "" {
+		c.Stream.MediaType = j.OldContentType
+	}
+	if sdHash, ok := j.Sources["lbry_sd_hash"]; ok {
+		var err error
+		c.Stream.SDHash, err = hex.DecodeString(sdHash)
+		if err != nil {
+			return errors.Wrap(err, "lbry_sd_hash")
+		}
+	}
+	for currency, fee := range j.Fee {
+		c.Stream.Fee = &Fee{
+			Currency: currency,
+			Address:  fee.Address,
+			Amount:   legacyAmount(currency, fee.Amount),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+// Package metadata decodes the values of the claim scripts: the metadata LBRY stores with its streams, channels,
+// collections and reposts, and the signature of the channel that published them.
+package metadata
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcutil/base58"
+
+	"github.com/pkg/errors"
+)
+
+// Format is the serialization format of a claim value.
+type Format int
+
+const (
+	// FormatJSON is the JSON of the first claims.
+	FormatJSON Format = iota
+
+	// FormatLegacy is the protobuf of the first schema, with streams and certificates only.
+	FormatLegacy
+
+	// FormatCurrent is the protobuf of the current schema, prefixed with the signature when it's signed.
+	FormatCurrent
+)
+
+var formatNames = map[Format]string{
+	FormatJSON:    "json",
+	FormatLegacy:  "legacy",
+	FormatCurrent: "current",
+}
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// Type is the kind of content a claim is for.
+type Type int
+
+const (
+	TypeUnknown Type = iota
+	TypeStream
+	TypeChannel
+	TypeCollection
+	TypeRepost
+)
+
+var typeNames = map[Type]string{
+	TypeStream:     "stream",
+	TypeChannel:    "channel",
+	TypeCollection: "collection",
+	TypeRepost:     "repost",
+}
+
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+var currencyNames = map[uint64]string{
+	1: "LBC",
+	2: "BTC",
+	3: "USD",
+}
+
+// Claim is the metadata decoded from a claim value. Whatever the format it was in, it's laid out as in the
+// current schema.
+type Claim struct {
+	Format      Format
+	Type        Type
+	Title       string
+	Description string
+	Thumbnail   string   // The URL of the thumbnail.
+	Tags        []string // The legacy claims marked as NSFW have the "mature" tag.
+
+	Stream  *Stream          // Set for the streams.
+	Channel *Channel         // Set for the channels.
+	Claims  []change.ClaimID // The claims of a collection, or the one a repost is for.
+
+	SigningChannel change.ClaimID // The channel that signed the claim, if it's signed.
+	Signature      []byte         // The signature, r and s of 32 bytes each.
+
+	signed []byte // What the signature covers besides the transaction or the address.
+}
+
+// Stream is the metadata of the content of a stream.
+type Stream struct {
+	MediaType   string
+	SourceName  string
+	SourceHash  []byte
+	SDHash      []byte // The hash of the stream descriptor blob.
+	Size        uint64
+	Author      string
+	License     string
+	LicenseURL  string
+	ReleaseTime int64  // In seconds since the UNIX epoch.
+	Kind        string // One of image, video, audio or software, if set.
+	Fee         *Fee
+}
+
+// Fee is the price a stream is offered at.
+type Fee struct {
+	Currency string // One of LBC, BTC or USD.
+	Address  string // The address to pay, if set.
+	Amount   uint64 // In the smallest unit of the currency: dewies, satoshis or cents.
+}
+
+// Channel is the metadata of a channel.
+type Channel struct {
+	PublicKey  []byte // The public key of the channel, which signs its claims.
+	Email      string
+	WebsiteURL string
+	Cover      string // The URL of the cover image.
+	Featured   []change.ClaimID
+}
+
+// Signed returns whether the claim is signed by a channel.
+func (c *Claim) Signed() bool {
+	return len(c.Signature) > 0
+}
+
+// Decode decodes a claim value, as returned by txscript.ClaimScript.Value, in any of the formats.
+func Decode(value []byte) (*Claim, error) {
+
+	if len(value) == 0 {
+		return nil, errors.New("empty claim value")
+	}
+
+	c := &Claim{Format: FormatCurrent}
+	var err error
+	switch value[0] {
+	case 0:
+		err = c.decodeClaim(value[1:])
+	case 1:
+		if len(value) < 1+change.ClaimIDSize+64 {
+			return nil, errors.Errorf("signed claim value of %d bytes is too short", len(value))
+		}
+		copy(c.SigningChannel[:], value[1:])
+		c.Signature = value[1+change.ClaimIDSize : 1+change.ClaimIDSize+64]
+		c.signed = value[1+change.ClaimIDSize+64:]
+		err = c.decodeClaim(c.signed)
+	case '{':
+		c.Format = FormatJSON
+		err = c.decodeJSON(value)
+	default:
+		c.Format = FormatLegacy
+		err = c.decodeLegacyClaim(value)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "decode claim value")
+	}
+	return c, nil
+}
+
+func (c *Claim) decodeClaim(b []byte) error {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.tag {
+		case key(1, wireBytes):
+			c.Type = TypeStream
+			c.Stream, err = decodeStream(f.data)
+		case key(2, wireBytes):
+			c.Type = TypeChannel
+			c.Channel, err = decodeChannel(f.data)
+		case key(3, wireBytes):
+			c.Type = TypeCollection
+			c.Claims, err = decodeClaimList(f.data)
+		case key(4, wireBytes):
+			c.Type = TypeRepost
+			var id change.ClaimID
+			id, err = decodeClaimReference(f.data)
+			c.Claims = []change.ClaimID{id}
+		case key(8, wireBytes):
+			c.Title = f.str()
+		case key(9, wireBytes):
+			c.Description = f.str()
+		case key(10, wireBytes):
+			var src source
+			src, err = decodeSource(f.data)
+			c.Thumbnail = src.url
+		case key(11, wireBytes):
+			c.Tags = append(c.Tags, f.str())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeStream(b []byte) (*Stream, error) {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	s := &Stream{}
+	for _, f := range fields {
+		switch f.tag {
+		case key(1, wireBytes):
+			var src source
+			src, err = decodeSource(f.data)
+			s.SourceHash, s.SourceName, s.Size = src.hash, src.name, src.size
+			s.MediaType, s.SDHash = src.mediaType, src.sdHash
+		case key(2, wireBytes):
+			s.Author = f.str()
+		case key(3, wireBytes):
+			s.License = f.str()
+		case key(4, wireBytes):
+			s.LicenseURL = f.str()
+		case key(5, wireVarint):
+			s.ReleaseTime = int64(f.value)
+		case key(6, wireBytes):
+			s.Fee, err = decodeFee(f.data)
+		case key(10, wireBytes):
+			s.Kind = "image"
+		case key(11, wireBytes):
+			s.Kind = "video"
+		case key(12, wireBytes):
+			s.Kind = "audio"
+		case key(13, wireBytes):
+			s.Kind = "software"
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func decodeChannel(b []byte) (*Channel, error) {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	ch := &Channel{}
+	for _, f := range fields {
+		switch f.tag {
+		case key(1, wireBytes):
+			ch.PublicKey = f.data
+		case key(2, wireBytes):
+			ch.Email = f.str()
+		case key(3, wireBytes):
+			ch.WebsiteURL = f.str()
+		case key(4, wireBytes):
+			var src source
+			src, err = decodeSource(f.data)
+			ch.Cover = src.url
+		case key(5, wireBytes):
+			ch.Featured, err = decodeClaimList(f.data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ch, nil
+}
+
+// source is a file, or the URL of one.
+type source struct {
+	hash      []byte
+	name      string
+	size      uint64
+	mediaType string
+	url       string
+	sdHash    []byte
+}
+
+func decodeSource(b []byte) (source, error) {
+
+	var src source
+	fields, err := parseFields(b)
+	if err != nil {
+		return src, err
+	}
+	for _, f := range fields {
+		switch f.tag {
+		case key(1, wireBytes):
+			src.hash = f.data
+		case key(2, wireBytes):
+			src.name = f.str()
+		case key(3, wireVarint):
+			src.size = f.value
+		case key(4, wireBytes):
+			src.mediaType = f.str()
+		case key(5, wireBytes):
+			src.url = f.str()
+		case key(6, wireBytes):
+			src.sdHash = f.data
+		}
+	}
+	return src, nil
+}
+
+func decodeFee(b []byte) (*Fee, error) {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	fee := &Fee{}
+	for _, f := range fields {
+		switch f.tag {
+		case key(1, wireVarint):
+			fee.Currency = currencyNames[f.value]
+		case key(2, wireBytes):
+			fee.Address = base58.Encode(f.data)
+		case key(3, wireVarint):
+			fee.Amount = f.value
+		}
+	}
+	return fee, nil
+}
+
+func decodeClaimList(b []byte) ([]change.ClaimID, error) {
+
+	fields, err := parseFields(b)
+	if err != nil {
+		return nil, err
+	}
+	var ids []change.ClaimID
+	for _, f := range fields {
+		if f.tag == key(2, wireBytes) {
+			id, err := decodeClaimReference(f.data)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// decodeClaimReference returns the claim a reference is for. Its hash is the claim ID in the byte order of the
+// claim scripts.
+func decodeClaimReference(b []byte) (change.ClaimID, error) {
+
+	var id change.ClaimID
+	fields, err := parseFields(b)
+	if err != nil {
+		return id, err
+	}
+	for _, f := range fields {
+		if f.tag == key(1, wireBytes) {
+			if len(f.data) != change.ClaimIDSize {
+				return id, errors.Errorf("claim reference of %d bytes", len(f.data))
+			}
+			copy(id[:], f.data)
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,292 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/lbryio/lbcd/btcec"
+	"github.com/lbryio/lbcd/chaincfg"
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
+	"github.com/lbryio/lbcutil/base58"
+
+	"github.com/stretchr/testify/require"
+)
+
+func uvarint(v uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)]
+}
+
+func pbBytes(num uint64, data ...[]byte) []byte {
+	b := join(data...)
+	return join(uvarint(key(num, wireBytes)), uvarint(uint64(len(b))), b)
+}
+
+func pbString(num uint64, s string) []byte {
+	return pbBytes(num, []byte(s))
+}
+
+func pbVarint(num, v uint64) []byte {
+	return join(uvarint(key(num, wireVarint)), uvarint(v))
+}
+
+func pbFloat(num uint64, v float32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(v))
+	return join(uvarint(key(num, wireFixed32)), b[:])
+}
+
+func join(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// channelKey returns a private key with the DER encoding of its public key, as the channels store it.
+func channelKey(t *testing.T) (*btcec.PrivateKey, []byte) {
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+
+	var info struct {
+		Algorithm struct {
+			Algorithm asn1.ObjectIdentifier
+			Curve     asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	info.Algorithm.Algorithm = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	info.Algorithm.Curve = oidSecp256k1
+	pub := priv.PubKey().SerializeUncompressed()
+	info.PublicKey = asn1.BitString{Bytes: pub, BitLength: len(pub) * 8}
+	der, err := asn1.Marshal(info)
+	require.NoError(t, err)
+	return priv, der
+}
+
+func sign(t *testing.T, priv *btcec.PrivateKey, message []byte) []byte {
+	digest := sha256.Sum256(message)
+	sig, err := priv.Sign(digest[:])
+	require.NoError(t, err)
+	out := make([]byte, 64)
+	sig.R.FillBytes(out[:32])
+	sig.S.FillBytes(out[32:])
+	return out
+}
+
+func TestDecodeStream(t *testing.T) {
+	r := require.New(t)
+
+	address := []byte{0x55, 1, 2, 3}
+	value := join([]byte{0}, pbBytes(1,
+		pbBytes(1, pbString(2, "movie.mp4"), pbVarint(3, 1234), pbString(4, "video/mp4"), pbBytes(6, []byte{0xab})),
+		pbString(2, "author"),
+		pbString(3, "license"),
+		pbVarint(5, 1600000000),
+		pbBytes(6, pbVarint(1, 3), pbBytes(2, address), pbVarint(3, 150)),
+		pbBytes(11)),
+		pbString(8, "title"),
+		pbString(9, "description"),
+		pbBytes(10, pbString(5, "https://thumbnail")),
+		pbString(11, "first"),
+		pbString(11, "second"),
+		pbVarint(99, 1)) // unknown fields are skipped
+
+	c, err := Decode(value)
+	r.NoError(err)
+	r.Equal(FormatCurrent, c.Format)
+	r.Equal(TypeStream, c.Type)
+	r.Equal("stream", c.Type.String())
+	r.Equal("title", c.Title)
+	r.Equal("description", c.Description)
+	r.Equal("https://thumbnail", c.Thumbnail)
+	r.Equal([]string{"first", "second"}, c.Tags)
+	r.False(c.Signed())
+	r.Equal(&Stream{
+		MediaType:   "video/mp4",
+		SourceName:  "movie.mp4",
+		SDHash:      []byte{0xab},
+		Size:        1234,
+		Author:      "author",
+		License:     "license",
+		ReleaseTime: 1600000000,
+		Kind:        "video",
+		Fee:         &Fee{Currency: "USD", Address: base58.Encode(address), Amount: 150},
+	}, c.Stream)
+}
+
+func TestDecodeRepostAndCollection(t *testing.T) {
+	r := require.New(t)
+
+	id1, id2 := change.ClaimID{1}, change.ClaimID{2}
+	c, err := Decode(join([]byte{0}, pbBytes(4, pbBytes(1, id1[:]))))
+	r.NoError(err)
+	r.Equal(TypeRepost, c.Type)
+	r.Equal([]change.ClaimID{id1}, c.Claims)
+
+	c, err = Decode(join([]byte{0}, pbBytes(3, pbVarint(1, 0), pbBytes(2, pbBytes(1, id1[:])), pbBytes(2, pbBytes(1, id2[:])))))
+	r.NoError(err)
+	r.Equal(TypeCollection, c.Type)
+	r.Equal([]change.ClaimID{id1, id2}, c.Claims)
+
+	_, err = Decode(join([]byte{0}, pbBytes(4, pbBytes(1, id1[:5]))))
+	r.Error(err)
+}
+
+func TestSignedClaim(t *testing.T) {
+	r := require.New(t)
+
+	priv, der := channelKey(t)
+	channel, err := Decode(join([]byte{0}, pbBytes(2, pbBytes(1, der), pbString(2, "me@example.com"))))
+	r.NoError(err)
+	r.Equal(TypeChannel, channel.Type)
+	r.Equal(der, channel.Channel.PublicKey)
+	r.Equal("me@example.com", channel.Channel.Email)
+
+	prevHash := chainhash.Hash{9}
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 3), nil, nil))
+
+	channelID := change.ClaimID{7, 7, 7}
+	payload := join(pbBytes(1, pbString(2, "author")), pbString(8, "signed"))
+	var index [4]byte
+	binary.LittleEndian.PutUint32(index[:], 3)
+	signature := sign(t, priv, join(prevHash[:], index[:], channelID[:], payload))
+
+	c, err := Decode(join([]byte{1}, channelID[:], signature, payload))
+	r.NoError(err)
+	r.True(c.Signed())
+	r.Equal(channelID, c.SigningChannel)
+	r.Equal("signed", c.Title)
+
+	valid, err := c.VerifySignature(channel.Channel.PublicKey, tx, nil)
+	r.NoError(err)
+	r.True(valid)
+
+	// the bare public key works too
+	valid, err = c.VerifySignature(priv.PubKey().SerializeCompressed(), tx, nil)
+	r.NoError(err)
+	r.True(valid)
+
+	// the signature is bound to the transaction
+	tx.TxIn[0].PreviousOutPoint.Index = 4
+	valid, err = c.VerifySignature(der, tx, nil)
+	r.NoError(err)
+	r.False(valid)
+
+	_, err = c.VerifySignature(der, nil, nil)
+	r.Error(err)
+
+	other, _ := channelKey(t)
+	tx.TxIn[0].PreviousOutPoint.Index = 3
+	valid, err = c.VerifySignature(other.PubKey().SerializeCompressed(), tx, nil)
+	r.NoError(err)
+	r.False(valid)
+
+	_, err = Decode(join([]byte{1}, channelID[:], signature[:10]))
+	r.Error(err)
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	r := require.New(t)
+
+	priv, der := channelKey(t)
+	channel, err := Decode(join(pbVarint(1, 1), pbVarint(2, legacyCertificateType), pbBytes(4, pbVarint(1, 1), pbVarint(2, 3), pbBytes(4, der))))
+	r.NoError(err)
+	r.Equal(FormatLegacy, channel.Format)
+	r.Equal(TypeChannel, channel.Type)
+	r.Equal(der, channel.Channel.PublicKey)
+
+	address, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), &chaincfg.MainNetParams)
+	r.NoError(err)
+	feeAddress := base58.Decode(address.EncodeAddress())
+
+	unsigned := join(pbVarint(1, 1), pbVarint(2, legacyStreamType), pbBytes(3,
+		pbVarint(1, 1),
+		pbBytes(2, pbVarint(1, 1), pbVarint(2, 1), pbString(3, "old title"), pbString(4, "old description"),
+			pbString(5, "author"), pbString(6, "license"), pbVarint(7, 1),
+			pbBytes(8, pbVarint(1, 1), pbVarint(2, 1), pbBytes(3, feeAddress), pbFloat(4, 1.5)),
+			pbString(9, "https://thumbnail")),
+		pbBytes(3, pbVarint(1, 1), pbVarint(2, 1), pbBytes(3, []byte{0xcd}), pbString(4, "video/mp4"))))
+
+	certificateID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	signature := sign(t, priv, join(base58.Decode(address.EncodeAddress()), unsigned, certificateID))
+	value := join(unsigned, pbBytes(5, pbVarint(1, 1), pbVarint(2, 3), pbBytes(3, signature), pbBytes(4, certificateID)))
+
+	c, err := Decode(value)
+	r.NoError(err)
+	r.Equal(FormatLegacy, c.Format)
+	r.Equal(TypeStream, c.Type)
+	r.Equal("old title", c.Title)
+	r.Equal("old description", c.Description)
+	r.Equal("https://thumbnail", c.Thumbnail)
+	r.Equal([]string{"mature"}, c.Tags)
+	r.Equal(&Stream{
+		MediaType: "video/mp4",
+		SDHash:    []byte{0xcd},
+		Author:    "author",
+		License:   "license",
+		Fee:       &Fee{Currency: "LBC", Address: address.EncodeAddress(), Amount: 150000000},
+	}, c.Stream)
+
+	r.True(c.Signed())
+	r.Equal("0102030405060708090a0b0c0d0e0f1011121314", c.SigningChannel.String())
+	valid, err := c.VerifySignature(der, nil, address)
+	r.NoError(err)
+	r.True(valid)
+
+	// the signature is bound to the address
+	other, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), &chaincfg.TestNet3Params)
+	r.NoError(err)
+	valid, err = c.VerifySignature(der, nil, other)
+	r.NoError(err)
+	r.False(valid)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	r := require.New(t)
+
+	c, err := Decode([]byte(`{"ver": "0.0.3", "title": "json", "description": "old", "author": "a", "nsfw": true,
+		"content_type": "video/mp4", "sources": {"lbry_sd_hash": "abcd"},
+		"fee": {"USD": {"amount": 0.5, "address": "bXYZ"}}}`))
+	r.NoError(err)
+	r.Equal(FormatJSON, c.Format)
+	r.Equal(TypeStream, c.Type)
+	r.Equal("json", c.Title)
+	r.Equal([]string{"mature"}, c.Tags)
+	r.Equal(&Stream{
+		MediaType: "video/mp4",
+		SDHash:    []byte{0xab, 0xcd},
+		Author:    "a",
+		Fee:       &Fee{Currency: "USD", Address: "bXYZ", Amount: 50},
+	}, c.Stream)
+
+	_, err = c.VerifySignature(nil, nil, nil)
+	r.Error(err)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	r := require.New(t)
+
+	for _, value := range [][]byte{
+		nil,
+		{0, 0x0a},            // missing length
+		{0, 0x0a, 5, 1},      // truncated bytes
+		{0, 0x0b},            // group wire type
+		{0, 0x08, 0x80},      // truncated varint
+		{'{', 'x'},           // bad JSON
+		{0x08, 1, 0x10, 9},   // unknown legacy type
+		{1, 1, 2, 3},         // truncated signature
+		append([]byte{0}, 0), // field number 0
+	} {
+		_, err := Decode(value)
+		r.Error(err, "%x", value)
+	}
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// The protobuf wire types used by the claim schemas; the deprecated groups aren't.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// field is a field of a serialized protobuf message.
+type field struct {
+	tag   uint64 // the field number and wire type, as made by key
+	value uint64 // the value of the varint and fixed-size fields
+	data  []byte // the value of the length-delimited fields
+	raw   []byte // the whole field as serialized, tag included
+}
+
+// key returns the tag of the field with number num serialized as the wire type typ. Fields with an unexpected
+// wire type don't match their key, so they're skipped like the unknown ones.
+func key(num, typ uint64) uint64 {
+	return num<<3 | typ
+}
+
+func (f field) str() string {
+	return string(f.data)
+}
+
+func (f field) float32() float32 {
+	return math.Float32frombits(uint32(f.value))
+}
+
+// parseFields splits a serialized protobuf message into its fields, in the order they're serialized.
+func parseFields(b []byte) ([]field, error) {
+
+	var fields []field
+	for start := 0; start < len(b); {
+		tag, n := binary.Uvarint(b[start:])
+		if n <= 0 || tag>>3 == 0 {
+			return nil, errors.Errorf("malformed field tag at offset %d", start)
+		}
+		f := field{tag: tag}
+		pos := start + n
+
+		switch tag & 7 {
+		case wireVarint:
+			f.value, n = binary.Uvarint(b[pos:])
+			if n <= 0 {
+				return nil, errors.Errorf("malformed varint at offset %d", pos)
+			}
+			pos += n
+		case wireFixed64:
+			if len(b)-pos < 8 {
+				return nil, errors.Errorf("truncated fixed64 at offset %d", pos)
+			}
+			f.value = binary.LittleEndian.Uint64(b[pos:])
+			pos += 8
+		case wireFixed32:
+			if len(b)-pos < 4 {
+				return nil, errors.Errorf("truncated fixed32 at offset %d", pos)
+			}
+			f.value = uint64(binary.LittleEndian.Uint32(b[pos:]))
+			pos += 4
+		case wireBytes:
+			size, n := binary.Uvarint(b[pos:])
+			if n <= 0 || size > uint64(len(b)-pos-n) {
+				return nil, errors.Errorf("malformed length at offset %d", pos)
+			}
+			pos += n
+			f.data = b[pos : pos+int(size)]
+			pos += int(size)
+		default:
+			return nil, errors.Errorf("unsupported wire type %d at offset %d", tag&7, start)
+		}
+
+		f.raw = b[start:pos]
+		fields = append(fields, f)
+		start = pos
+	}
+	return fields, nil
+}
@@ -0,0 +1,98 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/lbryio/lbcd/btcec"
+	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
+	"github.com/lbryio/lbcutil/base58"
+
+	"github.com/pkg/errors"
+)
+
+// oidSecp256k1 identifies the curve of the channel keys in their DER encoding.
+var oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// VerifySignature returns whether the claim is signed by the channel with the public key. The signature also
+// covers where the claim is: the current format signs the outpoint spent by the first input of the claim's
+// transaction, and the legacy one the address the claim pays to.
+func (c *Claim) VerifySignature(publicKey []byte, tx *wire.MsgTx, address btcutil.Address) (bool, error) {
+
+	if !c.Signed() {
+		return false, errors.New("the claim is not signed")
+	}
+	if len(c.Signature) != 64 {
+		return false, errors.Errorf("signature of %d bytes", len(c.Signature))
+	}
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	var message []byte
+	switch c.Format {
+	case FormatCurrent:
+		if tx == nil || len(tx.TxIn) == 0 {
+			return false, errors.New("the transaction of the claim is needed to verify its signature")
+		}
+		prev := tx.TxIn[0].PreviousOutPoint
+		var index [4]byte
+		binary.LittleEndian.PutUint32(index[:], prev.Index)
+		message = append(message, prev.Hash[:]...)
+		message = append(message, index[:]...)
+		message = append(message, c.SigningChannel[:]...)
+	case FormatLegacy:
+		if address == nil {
+			return false, errors.New("the address of the claim is needed to verify its signature")
+		}
+		message = append(message, base58.Decode(address.EncodeAddress())...)
+	default:
+		return false, errors.Errorf("claims of format %d aren't signed", c.Format)
+	}
+	message = append(message, c.signed...)
+	if c.Format == FormatLegacy {
+		// the legacy claims end with the certificate ID, in its reversed byte order
+		for i := len(c.SigningChannel) - 1; i >= 0; i-- {
+			message = append(message, c.SigningChannel[i])
+		}
+	}
+
+	digest := sha256.Sum256(message)
+	signature := btcec.Signature{
+		R: new(big.Int).SetBytes(c.Signature[:32]),
+		S: new(big.Int).SetBytes(c.Signature[32:]),
+	}
+	return signature.Verify(digest[:], key), nil
+}
+
+// parsePublicKey parses the public key of a channel. It's usually in the DER encoding of a SubjectPublicKeyInfo,
+// but a bare compressed or uncompressed key is accepted too.
+func parsePublicKey(b []byte) (*btcec.PublicKey, error) {
+
+	if key, err := btcec.ParsePubKey(b, btcec.S256()); err == nil {
+		return key, nil
+	}
+
+	var info struct {
+		Algorithm struct {
+			Algorithm asn1.ObjectIdentifier
+			Curve     asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	rest, err := asn1.Unmarshal(b, &info)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse public key")
+	}
+	if len(rest) > 0 {
+		return nil, errors.Errorf("%d trailing bytes after the public key", len(rest))
+	}
+	if !info.Algorithm.Curve.Equal(oidSecp256k1) {
+		return nil, errors.Errorf("unsupported curve %s", info.Algorithm.Curve)
+	}
+	return btcec.ParsePubKey(info.PublicKey.Bytes, btcec.S256())
+}
@@ -0,0 +1,108 @@
+package node
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache is a bounded LRU cache of the nodes computed by the BaseManager, keyed by name and height.
+// It is safe for concurrent access. The nodes it hands out are copies that the caller may modify.
+type Cache struct {
+	mtx     sync.Mutex
+	maxSize int
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]map[int32]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type cacheEntry struct {
+	name   string
+	height int32
+	node   *Node // nil for names without changes at the height
+}
+
+// NewCache returns a cache that holds up to maxSize nodes.
+func NewCache(maxSize int) *Cache {
+	return &Cache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: map[string]map[int32]*list.Element{},
+	}
+}
+
+// Get returns a copy of the node of name at height, and whether it was in the cache.
+func (c *Cache) Get(name []byte, height int32) (*Node, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[string(name)][height]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	c.order.MoveToFront(e)
+	n := e.Value.(*cacheEntry).node
+	if n == nil {
+		return nil, true
+	}
+	return n.Clone(), true
+}
+
+// Put keeps a copy of the node of name at height, evicting the least recently used nodes past the size limit.
+func (c *Cache) Put(name []byte, height int32, n *Node) {
+	if c.maxSize <= 0 {
+		return
+	}
+	if n != nil {
+		n = n.Clone()
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	heights := c.entries[string(name)]
+	if heights == nil {
+		heights = map[int32]*list.Element{}
+		c.entries[string(name)] = heights
+	}
+	if e, ok := heights[height]; ok {
+		e.Value.(*cacheEntry).node = n
+		c.order.MoveToFront(e)
+		return
+	}
+	heights[height] = c.order.PushFront(&cacheEntry{name: string(name), height: height, node: n})
+
+	for c.order.Len() > c.maxSize {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate drops the nodes of name at or above the height.
+func (c *Cache) Invalidate(name []byte, height int32) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for h, e := range c.entries[string(name)] {
+		if h >= height {
+			c.remove(e)
+		}
+	}
+}
+
+// Stats returns the number of lookups that were served from the cache and the number that weren't.
+func (c *Cache) Stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
+}
+
+func (c *Cache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*cacheEntry)
+	heights := c.entries[entry.name]
+	delete(heights, entry.height)
+	if len(heights) == 0 {
+		delete(c.entries, entry.name)
+	}
+}
@@ -31,3 +31,20 @@ func (l ClaimList) find(cmp comparator) *Claim {
 
 	return nil
 }
+
+// clone returns a copy of the list with copies of its claims. When the list holds the claim pointed to
+// by keep, the pointer at kept is set to its copy.
+func (l ClaimList) clone(keep *Claim, kept **Claim) ClaimList {
+	if l == nil {
+		return nil
+	}
+	clone := make(ClaimList, len(l))
+	for i, c := range l {
+		cc := *c
+		clone[i] = &cc
+		if c == keep && keep != nil {
+			*kept = &cc
+		}
+	}
+	return clone
+}
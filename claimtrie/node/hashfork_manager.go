@@ -17,6 +17,12 @@ func (nm *HashV2Manager) computeClaimHashes(name []byte) (*chainhash.Hash, int32
 	}
 
 	n.SortClaimsByBid()
+	return claimsHash(n), n.NextUpdate()
+}
+
+// claimsHash returns the merkle root of the activated claims of the node, which must be sorted by bid.
+func claimsHash(n *Node) *chainhash.Hash {
+
 	claimHashes := make([]*chainhash.Hash, 0, len(n.Claims))
 	for _, c := range n.Claims {
 		if c.Status == Activated { // TODO: unit test this line
@@ -24,9 +30,9 @@ func (nm *HashV2Manager) computeClaimHashes(name []byte) (*chainhash.Hash, int32
 		}
 	}
 	if len(claimHashes) > 0 {
-		return ComputeMerkleRoot(claimHashes), n.NextUpdate()
+		return ComputeMerkleRoot(claimHashes)
 	}
-	return nil, n.NextUpdate()
+	return nil
 }
 
 func (nm *HashV2Manager) Hash(name []byte) (*chainhash.Hash, int32) {
@@ -37,3 +43,18 @@ func (nm *HashV2Manager) Hash(name []byte) (*chainhash.Hash, int32) {
 
 	return nm.Manager.Hash(name)
 }
+
+// HashAt returns the hash that the node, as of height, has in the trie at that height; nil means it isn't in it.
+// Unlike Hash, it leaves the order of the claims of the node alone.
+func HashAt(n *Node, height int32) *chainhash.Hash {
+
+	if n == nil {
+		return nil
+	}
+	if height >= param.ActiveParams.AllClaimsInMerkleForkHeight {
+		sorted := &Node{Claims: append(ClaimList(nil), n.Claims...), SupportSums: n.SupportSums, TakenOverAt: n.TakenOverAt}
+		sorted.SortClaimsByBid()
+		return claimsHash(sorted)
+	}
+	return bestClaimHash(n)
+}
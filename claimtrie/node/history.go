@@ -0,0 +1,105 @@
+package node
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+)
+
+// HistoryEntry is a change to a claim or support along with the state computed for it.
+// Spends carry the state of the stake just before it was spent; everything else carries
+// the state at the end of the block that contained the change.
+type HistoryEntry struct {
+	Change   change.Change
+	ActiveAt int32
+	ExpireAt int32
+	Status   Status
+
+	// Controlling is true when the claim, or the claim being supported, owned the name at the end of the block.
+	Controlling bool
+	TakenOverAt int32
+}
+
+// History replays the changes of a node up to (includes) the specified height and
+// returns one entry per change, in the order they were applied. Pruned changes are left out.
+func (nm *BaseManager) History(height int32, name []byte) ([]HistoryEntry, error) {
+
+	if height < nm.prunedHeight {
+		return nil, errors.Wrapf(ErrPruned, "height %d is below %d", height, nm.prunedHeight)
+	}
+
+	changes, err := nm.repo.LoadChanges(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "in load changes")
+	}
+
+	n, base, changes, err := nm.prunedBase(name, changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "in pruned base")
+	}
+
+	var entries []HistoryEntry
+	var stakes []*Claim // the stake each entry refers to
+	pending := 0        // entries still waiting for the end of their block
+
+	endBlock := func(height int32, name []byte) {
+		n.AdjustTo(height, height, name)
+		for ; pending < len(entries); pending++ {
+			e := &entries[pending]
+			if s := stakes[pending]; s != nil && e.Change.Type != change.SpendClaim && e.Change.Type != change.SpendSupport {
+				e.ActiveAt, e.ExpireAt, e.Status = s.ActiveAt, s.ExpireAt(), s.Status
+			}
+			e.Controlling = n.BestClaim != nil && n.BestClaim.ClaimID == e.Change.ClaimID && n.BestClaim.Status == Activated
+			e.TakenOverAt = n.TakenOverAt
+		}
+	}
+
+	for i, chg := range changes {
+		if chg.Height > height {
+			break
+		}
+		if i == 0 && base > 0 {
+			n.AdjustTo(base, chg.Height-1, chg.Name)
+		}
+		if i > 0 && changes[i-1].Height < chg.Height {
+			endBlock(changes[i-1].Height, chg.Name)
+			if next := n.NextUpdate(); next < chg.Height {
+				n.AdjustTo(next, chg.Height-1, chg.Name) // same as AdjustTo(previous, chg.Height-1) in NodeAt
+			}
+		}
+
+		var stake *Claim
+		switch chg.Type {
+		case change.SpendClaim:
+			stake = n.Claims.find(byOut(chg.OutPoint))
+		case change.SpendSupport:
+			stake = n.Supports.find(byOut(chg.OutPoint))
+		}
+		e := HistoryEntry{Change: chg}
+		if stake != nil { // record it before it gets deactivated
+			e.ActiveAt, e.ExpireAt, e.Status = stake.ActiveAt, stake.ExpireAt(), stake.Status
+		}
+
+		delay := nm.getDelayForName(n, chg)
+		if err = n.ApplyChange(chg, delay); err != nil {
+			return nil, errors.Wrap(err, "in apply change")
+		}
+
+		switch chg.Type {
+		case change.AddClaim:
+			stake = n.Claims[len(n.Claims)-1]
+		case change.UpdateClaim:
+			stake = n.Claims.find(byID(chg.ClaimID))
+		case change.AddSupport:
+			stake = n.Supports[len(n.Supports)-1]
+		}
+		entries = append(entries, e)
+		stakes = append(stakes, stake)
+	}
+
+	if len(entries) > 0 {
+		last := entries[len(entries)-1].Change
+		endBlock(last.Height, last.Name)
+	}
+	return entries, nil
+}
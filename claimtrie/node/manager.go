@@ -18,22 +18,43 @@ type Manager interface {
 	Height() int32
 	Close() error
 	NodeAt(height int32, name []byte) (*Node, error)
+	NodeWithChanges(height int32, name []byte, pending []change.Change) (*Node, error)
+	History(height int32, name []byte) ([]HistoryEntry, error)
+	Takeovers(height int32, name []byte) ([]Takeover, error)
 	IterateNames(predicate func(name []byte) bool)
+	IterateNamesFrom(prefix, start []byte, predicate func(name []byte) bool)
 	Hash(name []byte) (*chainhash.Hash, int32)
+	CacheStats() (hits, misses uint64)
+	Prune(height int32, names [][]byte) error
+	PrunedHeight() int32
 	Flush() error
 }
 
+// ErrPruned is returned for nodes below the height the changes have been pruned at.
+var ErrPruned = errors.New("the claimtrie history is pruned")
+
 type BaseManager struct {
 	repo Repo
 
 	height  int32
 	changes []change.Change
+
+	cache *Cache
+
+	prunedHeight int32
 }
 
 func NewBaseManager(repo Repo) (*BaseManager, error) {
 
+	prunedHeight, err := repo.PrunedHeight()
+	if err != nil {
+		return nil, errors.Wrap(err, "in pruned height")
+	}
+
 	nm := &BaseManager{
-		repo: repo,
+		repo:         repo,
+		cache:        NewCache(param.ActiveParams.MaxNodeManagerCacheSize),
+		prunedHeight: prunedHeight,
 	}
 
 	return nm, nil
@@ -41,12 +62,76 @@ func NewBaseManager(repo Repo) (*BaseManager, error) {
 
 func (nm *BaseManager) NodeAt(height int32, name []byte) (*Node, error) {
 
+	if height < nm.prunedHeight {
+		return nil, errors.Wrapf(ErrPruned, "height %d is below %d", height, nm.prunedHeight)
+	}
+
+	if n, ok := nm.cache.Get(name, height); ok {
+		return n, nil
+	}
+
 	changes, err := nm.repo.LoadChanges(name)
 	if err != nil {
 		return nil, errors.Wrap(err, "in load changes")
 	}
 
-	n, err := nm.newNodeFromChanges(changes, height)
+	n, previous, changes, err := nm.baseNode(name, changes, height)
+	if err != nil {
+		return nil, errors.Wrap(err, "in base node")
+	}
+	if n != nil {
+		n, err = nm.applyChanges(n, previous, name, changes, height, nm.snapshotter(name))
+		if err != nil {
+			return nil, errors.Wrap(err, "in new node")
+		}
+	}
+
+	nm.cache.Put(name, height, n)
+	return n, nil
+}
+
+// CacheStats returns the number of NodeAt calls served from the node cache and the number that weren't.
+func (nm *BaseManager) CacheStats() (hits, misses uint64) {
+	return nm.cache.Stats()
+}
+
+// NodeWithChanges returns the node at the specified height with the pending changes applied on top
+// of it, as they would be if they got into the blocks they are marked with. The pending changes must
+// be in order and above the height. The node is adjusted to the height of the last pending change.
+func (nm *BaseManager) NodeWithChanges(height int32, name []byte, pending []change.Change) (*Node, error) {
+
+	if height < nm.prunedHeight {
+		return nil, errors.Wrapf(ErrPruned, "height %d is below %d", height, nm.prunedHeight)
+	}
+
+	changes, err := nm.repo.LoadChanges(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "in load changes")
+	}
+
+	for i := range changes {
+		if changes[i].Height > height {
+			changes = changes[:i]
+			break
+		}
+	}
+
+	n, previous, changes, err := nm.baseNode(name, changes, height)
+	if err != nil {
+		return nil, errors.Wrap(err, "in base node")
+	}
+	if len(pending) > 0 {
+		if n == nil {
+			n, previous = New(), pending[0].Height
+		}
+		changes = append(changes, pending...)
+		height = pending[len(pending)-1].Height
+	}
+	if n == nil {
+		return nil, nil
+	}
+
+	n, err = nm.applyChanges(n, previous, name, changes, height, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "in new node")
 	}
@@ -64,45 +149,112 @@ func (nm *BaseManager) node(name []byte) (*Node, error) {
 // The changes must preserve their order received.
 func (nm *BaseManager) newNodeFromChanges(changes []change.Change, height int32) (*Node, error) {
 
-	if len(changes) == 0 {
+	if len(changes) == 0 || changes[0].Height > height {
 		return nil, nil
 	}
 
-	n := New()
-	previous := changes[0].Height
-	count := len(changes)
+	return nm.applyChanges(New(), changes[0].Height, changes[0].Name, changes, height, nil)
+}
 
-	for i, chg := range changes {
+// baseNode returns the node to apply the changes of a node in the repo on to get to the height, along with the
+// height it's at and the changes left to apply. That is the latest snapshot of the node at or below the height,
+// or a new node when there's none. The returned node is nil when the node doesn't exist at the height.
+func (nm *BaseManager) baseNode(name []byte, changes []change.Change, height int32) (*Node, int32, []change.Change, error) {
+
+	snapshotHeight, data, err := nm.repo.LoadSnapshot(name, height)
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "in load snapshot")
+	}
+	if data == nil {
+		if len(changes) == 0 || changes[0].Height > height {
+			return nil, 0, nil, nil
+		}
+		return New(), changes[0].Height, changes, nil
+	}
+
+	n, err := unmarshalSnapshot(data)
+	if err != nil {
+		return nil, 0, nil, errors.Wrapf(err, "in snapshot of %s at %d", name, snapshotHeight)
+	}
+	i := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Height > snapshotHeight
+	})
+	return n, snapshotHeight, changes[i:], nil
+}
+
+// snapshotter returns a function that stores a snapshot of the node after every snapshotInterval
+// replayed changes. Only the committed heights get snapshots; DropChanges takes care of those on rollback.
+func (nm *BaseManager) snapshotter(name []byte) func(n *Node, height int32, replayed int) bool {
+
+	return func(n *Node, height int32, replayed int) bool {
+		if replayed < snapshotInterval || height > nm.height {
+			return false
+		}
+		data, err := marshalSnapshot(n)
+		if err == nil {
+			err = nm.repo.SetSnapshot(name, height, data)
+		}
+		if err != nil {
+			// a missing snapshot only costs time
+			log.Warnf("Unable to store a snapshot of %s at %d: %s", name, height, err)
+		}
+		return true
+	}
+}
+
+// applyChanges applies the changes up to the height on top of n, which has every change up to (includes)
+// the previous height applied and hasn't been adjusted past it, and adjusts it to the height.
+func (nm *BaseManager) applyChanges(n *Node, previous int32, name []byte, changes []change.Change, height int32,
+	checkpoint func(n *Node, height int32, replayed int) bool) (*Node, error) {
+
+	previous, err := nm.replayChanges(n, previous, name, changes, height, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	return n.AdjustTo(previous, height, name), nil
+}
+
+// replayChanges is applyChanges without the final adjustment; it returns the height of the last applied change.
+// The checkpoint, when given, is offered the node in the state it starts in at each height along the way,
+// including the last, along with the number of changes applied since it last returned true.
+func (nm *BaseManager) replayChanges(n *Node, previous int32, name []byte, changes []change.Change, height int32,
+	checkpoint func(n *Node, height int32, replayed int) bool) (int32, error) {
+
+	replayed := 0
+	for _, chg := range changes {
 		if chg.Height < previous {
 			panic("expected the changes to be in order by height")
 		}
 		if chg.Height > height {
-			count = i
 			break
 		}
 
 		if previous < chg.Height {
-			n.AdjustTo(previous, chg.Height-1, chg.Name) // update bids and activation
+			if checkpoint != nil && checkpoint(n, previous, replayed) {
+				replayed = 0
+			}
+			n.AdjustTo(previous, chg.Height-1, name) // update bids and activation
 			previous = chg.Height
 		}
 
 		delay := nm.getDelayForName(n, chg)
 		err := n.ApplyChange(chg, delay)
 		if err != nil {
-			return nil, errors.Wrap(err, "in apply change")
+			return 0, errors.Wrap(err, "in apply change")
 		}
+		replayed++
 	}
 
-	if count <= 0 {
-		return nil, nil
+	if checkpoint != nil {
+		checkpoint(n, previous, replayed)
 	}
-	lastChange := changes[count-1]
-	return n.AdjustTo(lastChange.Height, height, lastChange.Name), nil
+	return previous, nil
 }
 
 func (nm *BaseManager) AppendChange(chg change.Change) {
 
 	nm.changes = append(nm.changes, chg)
+	nm.cache.Invalidate(chg.Name, chg.Height)
 
 	// worth putting in this kind of thing pre-emptively?
 	// log.Debugf("CHG: %d, %s, %v, %s, %d", chg.Height, chg.Name, chg.Type, chg.ClaimID, chg.Amount)
@@ -201,6 +353,13 @@ func (nm *BaseManager) IncrementHeightTo(height int32) ([][]byte, error) {
 	names := make([][]byte, 0, len(nm.changes))
 	for i := range nm.changes {
 		names = append(names, nm.changes[i].Name)
+		// drop anything computed for the heights of the changes since they were appended
+		nm.cache.Invalidate(nm.changes[i].Name, nm.changes[i].Height)
+		if nm.changes[i].Height <= nm.height { // back-dated, as at the normalization fork
+			if err := nm.repo.DropSnapshots(nm.changes[i].Name, nm.changes[i].Height); err != nil {
+				return nil, errors.Wrap(err, "in drop snapshots")
+			}
+		}
 	}
 
 	if err := nm.repo.AppendChanges(nm.changes); err != nil { // destroys names
@@ -227,6 +386,7 @@ func (nm *BaseManager) DecrementHeightTo(affectedNames [][]byte, height int32) e
 		if err := nm.repo.DropChanges(name, height); err != nil {
 			return errors.Wrap(err, "in drop changes")
 		}
+		nm.cache.Invalidate(name, height+1)
 	}
 
 	nm.height = height
@@ -234,6 +394,93 @@ func (nm *BaseManager) DecrementHeightTo(affectedNames [][]byte, height int32) e
 	return nil
 }
 
+// Prune folds the changes of the named nodes up to (includes) the height into snapshots of the nodes,
+// and removes the nodes that are left with nothing in them. Nodes below the height are unavailable afterwards.
+func (nm *BaseManager) Prune(height int32, names [][]byte) error {
+
+	if height < nm.prunedHeight || height > nm.height {
+		return errors.Errorf("invalid height of %d to prune at for %d pruned at %d", height, nm.height, nm.prunedHeight)
+	}
+	if height > nm.prunedHeight {
+		if err := nm.repo.SetPrunedHeight(height); err != nil {
+			return errors.Wrap(err, "in set pruned height")
+		}
+		nm.prunedHeight = height
+	}
+
+	for _, name := range names {
+		changes, err := nm.repo.LoadChanges(name)
+		if err != nil {
+			return errors.Wrap(err, "in load changes")
+		}
+		n, previous, changes, err := nm.baseNode(name, changes, height)
+		if err != nil {
+			return errors.Wrap(err, "in base node")
+		}
+		if n == nil {
+			continue
+		}
+		previous, err = nm.replayChanges(n, previous, name, changes, height, nil)
+		if err != nil {
+			return errors.Wrap(err, "in replay changes")
+		}
+
+		i := sort.Search(len(changes), func(i int) bool {
+			return changes[i].Height > height
+		})
+		if i == len(changes) {
+			// expired and spent claims are gone by the height; there's nothing left to keep
+			adjusted := n.Clone().AdjustTo(previous, height, name)
+			if adjusted.BestClaim == nil && len(adjusted.Claims) == 0 && len(adjusted.Supports) == 0 {
+				if err = nm.repo.PruneChanges(name, height, nil); err != nil {
+					return errors.Wrap(err, "in prune changes")
+				}
+				nm.cache.Invalidate(name, 0)
+				continue
+			}
+		}
+		if i == 0 {
+			continue // nothing to fold into the snapshot it started from
+		}
+
+		data, err := marshalSnapshot(n)
+		if err != nil {
+			return errors.Wrap(err, "in marshal snapshot")
+		}
+		if err = nm.repo.PruneChanges(name, previous, data); err != nil {
+			return errors.Wrap(err, "in prune changes")
+		}
+	}
+
+	return nil
+}
+
+// prunedBase returns the snapshot the pruned changes of a node were folded into, along with its height and the
+// changes after it. It returns a new node at height 0 along with all the changes when they aren't pruned.
+func (nm *BaseManager) prunedBase(name []byte, changes []change.Change) (*Node, int32, []change.Change, error) {
+
+	if nm.prunedHeight <= 0 {
+		return New(), 0, changes, nil
+	}
+	snapshotHeight, data, err := nm.repo.LoadSnapshot(name, nm.prunedHeight)
+	if err != nil || data == nil {
+		return New(), 0, changes, errors.Wrap(err, "in load snapshot")
+	}
+	n, err := unmarshalSnapshot(data)
+	if err != nil {
+		return nil, 0, nil, errors.Wrapf(err, "in snapshot of %s at %d", name, snapshotHeight)
+	}
+	i := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Height > snapshotHeight
+	})
+	return n, snapshotHeight, changes[i:], nil
+}
+
+// PrunedHeight returns the height below which the nodes are unavailable, or 0 when nothing is pruned.
+func (nm *BaseManager) PrunedHeight() int32 {
+	return nm.prunedHeight
+}
+
 func (nm *BaseManager) getDelayForName(n *Node, chg change.Change) int32 {
 	// Note: we don't consider the active status of BestClaim here on purpose.
 	// That's because we deactivate and reactivate as part of claim updates.
@@ -338,7 +585,11 @@ func (nm *BaseManager) hasChildren(name []byte, height int32, spentChildren map[
 		if spentChildren[string(changes[0].Name)] {
 			return true // children that are spent in the same block cannot count as active children
 		}
-		n, _ := nm.newNodeFromChanges(changes, height)
+		child := changes[0].Name
+		n, previous, changes, _ := nm.baseNode(child, changes, height)
+		if n != nil {
+			n, _ = nm.applyChanges(n, previous, child, changes, height, nil)
+		}
 		if n != nil && n.HasActiveBestClaim() {
 			c[changes[0].Name[len(name)]] = true
 			if len(c) >= required {
@@ -354,19 +605,27 @@ func (nm *BaseManager) IterateNames(predicate func(name []byte) bool) {
 	nm.repo.IterateAll(predicate)
 }
 
+func (nm *BaseManager) IterateNamesFrom(prefix, start []byte, predicate func(name []byte) bool) {
+	nm.repo.IterateFrom(prefix, start, predicate)
+}
+
 func (nm *BaseManager) Hash(name []byte) (*chainhash.Hash, int32) {
 
 	n, err := nm.node(name)
 	if err != nil || n == nil {
 		return nil, 0
 	}
+	return bestClaimHash(n), n.NextUpdate()
+}
+
+// bestClaimHash returns the hash of the node from before the hash fork, which only covers its best claim.
+func bestClaimHash(n *Node) *chainhash.Hash {
 	if len(n.Claims) > 0 {
 		if n.BestClaim != nil && n.BestClaim.Status == Activated {
-			h := calculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
-			return h, n.NextUpdate()
+			return calculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
 		}
 	}
-	return nil, n.NextUpdate()
+	return nil
 }
 
 func (nm *BaseManager) Flush() error {
@@ -47,7 +47,7 @@ func TestSimpleAddClaim(t *testing.T) {
 	r := require.New(t)
 
 	param.SetNetwork(wire.TestNet)
-	repo, err := noderepo.NewPebble(t.TempDir())
+	repo, err := noderepo.NewPebble(t.TempDir(), t.TempDir())
 	r.NoError(err)
 
 	m, err := NewBaseManager(repo)
@@ -95,7 +95,7 @@ func TestSupportAmounts(t *testing.T) {
 	r := require.New(t)
 
 	param.SetNetwork(wire.TestNet)
-	repo, err := noderepo.NewPebble(t.TempDir())
+	repo, err := noderepo.NewPebble(t.TempDir(), t.TempDir())
 	r.NoError(err)
 
 	m, err := NewBaseManager(repo)
@@ -137,6 +137,71 @@ func TestSupportAmounts(t *testing.T) {
 	r.Equal(int64(5), n1.BestClaim.Amount+n1.SupportSums[n1.BestClaim.ClaimID.Key()])
 }
 
+func TestHistory(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+	repo, err := noderepo.NewPebble(t.TempDir(), t.TempDir())
+	r.NoError(err)
+
+	m, err := NewBaseManager(repo)
+	r.NoError(err)
+	defer m.Close()
+
+	_, err = m.IncrementHeightTo(10)
+	r.NoError(err)
+
+	chg := change.NewChange(change.AddClaim).SetName(name1).SetOutPoint(out1).SetHeight(11).SetAmount(3)
+	chg.ClaimID = change.NewClaimID(*out1)
+	m.AppendChange(chg)
+
+	chg = change.NewChange(change.AddClaim).SetName(name1).SetOutPoint(out2).SetHeight(11).SetAmount(4)
+	chg.ClaimID = change.NewClaimID(*out2)
+	m.AppendChange(chg)
+
+	_, err = m.IncrementHeightTo(11)
+	r.NoError(err)
+
+	chg = change.NewChange(change.AddSupport).SetName(name1).SetOutPoint(out3).SetHeight(12).SetAmount(2)
+	chg.ClaimID = change.NewClaimID(*out1)
+	m.AppendChange(chg)
+
+	chg = change.NewChange(change.AddSupport).SetName(name1).SetOutPoint(out4).SetHeight(12).SetAmount(2)
+	chg.ClaimID = change.NewClaimID(*out2)
+	m.AppendChange(chg)
+
+	chg = change.NewChange(change.SpendSupport).SetName(name1).SetOutPoint(out4).SetHeight(12).SetAmount(2)
+	chg.ClaimID = change.NewClaimID(*out2)
+	m.AppendChange(chg)
+
+	_, err = m.IncrementHeightTo(20)
+	r.NoError(err)
+
+	entries, err := m.History(11, name1)
+	r.NoError(err)
+	r.Len(entries, 2)
+	r.False(entries[0].Controlling)
+	r.True(entries[1].Controlling)
+	r.Equal(int32(11), entries[1].TakenOverAt)
+
+	entries, err = m.History(20, name1)
+	r.NoError(err)
+	r.Len(entries, 5)
+	r.Equal(Activated, entries[0].Status)
+	r.Equal(int32(11), entries[0].ActiveAt)
+	r.Equal(int32(11)+param.ActiveParams.OriginalClaimExpirationTime, entries[0].ExpireAt)
+	r.True(entries[1].Controlling) // as of the end of its block
+	r.True(entries[2].Controlling) // the support caused the takeover
+	r.Equal(int32(12), entries[2].TakenOverAt)
+	r.Equal(change.SpendSupport, entries[4].Change.Type)
+	r.Equal(Accepted, entries[4].Status)
+
+	n, err := m.NodeAt(20, name1)
+	r.NoError(err)
+	r.Equal(n.TakenOverAt, entries[4].TakenOverAt)
+}
+
 func TestNodeSort(t *testing.T) {
 
 	r := require.New(t)
@@ -183,7 +248,7 @@ func TestHasChildren(t *testing.T) {
 	r := require.New(t)
 
 	param.SetNetwork(wire.TestNet)
-	repo, err := noderepo.NewPebble(t.TempDir())
+	repo, err := noderepo.NewPebble(t.TempDir(), t.TempDir())
 	r.NoError(err)
 
 	m, err := NewBaseManager(repo)
@@ -247,3 +312,118 @@ func TestCollectChildren(t *testing.T) {
 
 	r.Len(c[7].SpentChildren, 0)
 }
+
+func TestNodeCache(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+	repo, err := noderepo.NewPebble(t.TempDir(), t.TempDir())
+	r.NoError(err)
+
+	m, err := NewBaseManager(repo)
+	r.NoError(err)
+	defer m.Close()
+
+	chg := change.NewChange(change.AddClaim).SetName(name1).SetOutPoint(out1).SetHeight(1).SetAmount(1)
+	m.AppendChange(chg)
+	_, err = m.IncrementHeightTo(1)
+	r.NoError(err)
+
+	n, err := m.NodeAt(1, name1)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	n.Claims = nil // callers get their own copies
+
+	n, err = m.NodeAt(1, name1)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	r.Same(n.BestClaim, n.Claims[0])
+	hits, misses := m.CacheStats()
+	r.Equal(uint64(1), hits)
+	r.Equal(uint64(1), misses)
+
+	// looking ahead sees the same node until a change is appended for that height
+	n, err = m.NodeAt(2, name1)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	chg = chg.SetOutPoint(out2).SetHeight(2).SetAmount(2)
+	m.AppendChange(chg)
+	_, err = m.IncrementHeightTo(2)
+	r.NoError(err)
+	n, err = m.NodeAt(2, name1)
+	r.NoError(err)
+	r.Len(n.Claims, 2)
+
+	n, err = m.NodeAt(1, name1)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+
+	// rolling back drops the nodes above the height
+	r.NoError(m.DecrementHeightTo([][]byte{name1}, 1))
+	n, err = m.NodeAt(2, name1)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+}
+
+func TestNodeSnapshots(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+	repo, err := noderepo.NewPebble(t.TempDir(), t.TempDir())
+	r.NoError(err)
+
+	m, err := NewBaseManager(repo)
+	r.NoError(err)
+	defer m.Close()
+
+	chg := change.NewChange(change.AddClaim).SetName(name1).SetOutPoint(out1).SetHeight(1).SetAmount(1)
+	chg.ClaimID = change.NewClaimID(*out1)
+	m.AppendChange(chg)
+	_, err = m.IncrementHeightTo(1)
+	r.NoError(err)
+
+	chg.Type = change.AddSupport
+	for h := int32(2); h <= 3*snapshotInterval; h++ {
+		m.AppendChange(chg.SetOutPoint(&wire.OutPoint{Index: uint32(h)}).SetHeight(h).SetAmount(int64(h)))
+		_, err = m.IncrementHeightTo(h)
+		r.NoError(err)
+	}
+
+	changes, err := repo.LoadChanges(name1)
+	r.NoError(err)
+
+	n, err := m.NodeAt(m.Height(), name1)
+	r.NoError(err)
+	snapshotHeight, data, err := repo.LoadSnapshot(name1, m.Height())
+	r.NoError(err)
+	r.NotNil(data)
+	r.Equal(m.Height(), snapshotHeight)
+
+	// nodes built on top of snapshots match the ones replayed from scratch
+	for _, h := range []int32{snapshotInterval - 1, snapshotInterval + 1, 2*snapshotInterval + 5, m.Height() + 10} {
+		base, previous, rest, err := m.baseNode(name1, changes, h)
+		r.NoError(err)
+		n, err = m.applyChanges(base, previous, name1, rest, h, nil)
+		r.NoError(err)
+		expected, err := m.newNodeFromChanges(changes, h)
+		r.NoError(err)
+		r.Equal(expected, n, "at %d", h)
+	}
+
+	// rolling back drops the snapshots above the height
+	r.NoError(m.DecrementHeightTo([][]byte{name1}, snapshotInterval+1))
+	snapshotHeight, _, err = repo.LoadSnapshot(name1, m.Height()+10)
+	r.NoError(err)
+	r.LessOrEqual(snapshotHeight, m.Height())
+
+	changes, err = repo.LoadChanges(name1)
+	r.NoError(err)
+	n, err = m.NodeAt(m.Height(), name1)
+	r.NoError(err)
+	expected, err := m.newNodeFromChanges(changes, m.Height())
+	r.NoError(err)
+	r.Equal(expected, n)
+	r.Len(n.Supports, snapshotInterval)
+}
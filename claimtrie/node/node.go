@@ -22,6 +22,24 @@ func New() *Node {
 	return &Node{SupportSums: map[string]int64{}}
 }
 
+// Clone returns a deep copy of the node.
+func (n *Node) Clone() *Node {
+	clone := &Node{
+		TakenOverAt: n.TakenOverAt,
+		SupportSums: make(map[string]int64, len(n.SupportSums)),
+	}
+	for k, v := range n.SupportSums {
+		clone.SupportSums[k] = v
+	}
+	clone.Claims = n.Claims.clone(n.BestClaim, &clone.BestClaim)
+	clone.Supports = n.Supports.clone(nil, nil)
+	if n.BestClaim != nil && clone.BestClaim == nil {
+		best := *n.BestClaim
+		clone.BestClaim = &best
+	}
+	return clone
+}
+
 func (n *Node) HasActiveBestClaim() bool {
 	return n.BestClaim != nil && n.BestClaim.Status == Activated
 }
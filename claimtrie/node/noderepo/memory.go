@@ -0,0 +1,222 @@
+package noderepo
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/pkg/errors"
+)
+
+// Memory keeps the changes serialized the way Pebble does so that loading them hands out fresh copies.
+type Memory struct {
+	changes map[string][]byte
+	names   []string // sorted, for the iterations
+
+	snapshots    map[string]map[int32][]byte
+	prunedHeight int32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		changes:   map[string][]byte{},
+		snapshots: map[string]map[int32][]byte{},
+	}
+}
+
+func (repo *Memory) set(name string, data []byte) {
+	if _, ok := repo.changes[name]; !ok {
+		i := sort.SearchStrings(repo.names, name)
+		repo.names = append(repo.names, "")
+		copy(repo.names[i+1:], repo.names[i:])
+		repo.names[i] = name
+	}
+	repo.changes[name] = data
+}
+
+func (repo *Memory) delete(name string) {
+	if _, ok := repo.changes[name]; ok {
+		i := sort.SearchStrings(repo.names, name)
+		repo.names = append(repo.names[:i], repo.names[i+1:]...)
+		delete(repo.changes, name)
+	}
+}
+
+// AppendChanges makes an assumption that anything you pass to it is newer than what was saved before.
+func (repo *Memory) AppendChanges(changes []change.Change) error {
+
+	buffer := bytes.NewBuffer(nil)
+
+	for _, chg := range changes {
+		buffer.Reset()
+		err := chg.Marshal(buffer)
+		if err != nil {
+			return errors.Wrap(err, "in marshaller")
+		}
+
+		name := string(chg.Name)
+		repo.set(name, append(repo.changes[name], buffer.Bytes()...))
+	}
+	return nil
+}
+
+func (repo *Memory) LoadChanges(name []byte) ([]change.Change, error) {
+	return unmarshalChanges(name, repo.changes[string(name)])
+}
+
+func (repo *Memory) DropChanges(name []byte, finalHeight int32) error {
+	changes, err := repo.LoadChanges(name)
+	if err != nil {
+		return errors.Wrapf(err, "in load changes for %s", name)
+	}
+	dropped := finalHeight + 1
+	i := 0
+	for ; i < len(changes); i++ { // assuming changes are ordered by height
+		if changes[i].Height > finalHeight {
+			break
+		}
+		if changes[i].VisibleHeight > finalHeight { // created after this height has to be deleted
+			if changes[i].Height < dropped {
+				dropped = changes[i].Height
+			}
+			changes = append(changes[:i], changes[i+1:]...)
+			i--
+		}
+	}
+	err = repo.DropSnapshots(name, dropped)
+	if err != nil {
+		return errors.Wrapf(err, "in drop snapshots for %s", name)
+	}
+	repo.set(string(name), []byte{})
+	return repo.AppendChanges(changes[:i])
+}
+
+func (repo *Memory) SetSnapshot(name []byte, height int32, data []byte) error {
+
+	snapshots, ok := repo.snapshots[string(name)]
+	if !ok {
+		snapshots = map[int32][]byte{}
+		repo.snapshots[string(name)] = snapshots
+	}
+	snapshots[height] = append([]byte{}, data...)
+	return nil
+}
+
+func (repo *Memory) LoadSnapshot(name []byte, height int32) (int32, []byte, error) {
+
+	found := int32(-1)
+	for h := range repo.snapshots[string(name)] {
+		if h <= height && h > found {
+			found = h
+		}
+	}
+	if found < 0 {
+		return 0, nil, nil
+	}
+	return found, append([]byte{}, repo.snapshots[string(name)][found]...), nil
+}
+
+func (repo *Memory) DropSnapshots(name []byte, height int32) error {
+
+	snapshots := repo.snapshots[string(name)]
+	for h := range snapshots {
+		if h >= height {
+			delete(snapshots, h)
+		}
+	}
+	if len(snapshots) == 0 {
+		delete(repo.snapshots, string(name))
+	}
+	return nil
+}
+
+func (repo *Memory) PruneChanges(name []byte, height int32, snapshot []byte) error {
+
+	if snapshot == nil {
+		repo.delete(string(name))
+		return repo.DropSnapshots(name, 0)
+	}
+
+	changes, err := repo.LoadChanges(name)
+	if err != nil {
+		return errors.Wrapf(err, "in load changes for %s", name)
+	}
+	i := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Height > height
+	})
+	if i == 0 || changes[i-1].Height != height {
+		return errors.Errorf("the last change of %s at or below %d is not at %d", name, height, height)
+	}
+
+	err = repo.SetSnapshot(name, height, snapshot)
+	if err != nil {
+		return errors.Wrapf(err, "in set snapshot for %s", name)
+	}
+	for h := range repo.snapshots[string(name)] {
+		if h < height {
+			delete(repo.snapshots[string(name)], h)
+		}
+	}
+	repo.set(string(name), []byte{})
+	return repo.AppendChanges(changes[i-1:])
+}
+
+func (repo *Memory) PrunedHeight() (int32, error) {
+	return repo.prunedHeight, nil
+}
+
+func (repo *Memory) SetPrunedHeight(height int32) error {
+	repo.prunedHeight = height
+	return nil
+}
+
+func (repo *Memory) IterateChildren(name []byte, f func(changes []change.Change) bool) error {
+
+	prefix := string(name)
+	start := sort.SearchStrings(repo.names, prefix)
+	end := start
+	for end < len(repo.names) && strings.HasPrefix(repo.names[end], prefix) {
+		end++
+	}
+
+	// f can change the repo, which Pebble's iterators don't see either
+	for _, child := range append([]string(nil), repo.names[start:end]...) {
+		changes, err := unmarshalChanges([]byte(child), repo.changes[child])
+		if err != nil {
+			return errors.Wrapf(err, "from unmarshaller at %s", child)
+		}
+		if !f(changes) {
+			break
+		}
+	}
+	return nil
+}
+
+func (repo *Memory) IterateAll(predicate func(name []byte) bool) {
+	for _, name := range append([]string(nil), repo.names...) {
+		if !predicate([]byte(name)) {
+			break
+		}
+	}
+}
+
+func (repo *Memory) IterateFrom(prefix, start []byte, predicate func(name []byte) bool) {
+	if bytes.Compare(start, prefix) < 0 {
+		start = prefix
+	}
+	names := repo.names[sort.SearchStrings(repo.names, string(start)):]
+	for _, name := range append([]string(nil), names...) {
+		if !strings.HasPrefix(name, string(prefix)) || !predicate([]byte(name)) {
+			break
+		}
+	}
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
@@ -18,7 +18,7 @@ func TestPebble(t *testing.T) {
 
 	r := require.New(t)
 
-	repo, err := NewPebble(t.TempDir())
+	repo, err := NewPebble(t.TempDir(), t.TempDir())
 	r.NoError(err)
 	defer func() {
 		err := repo.Close()
@@ -35,6 +35,16 @@ func TestPebble(t *testing.T) {
 	testNodeRepo(t, repo, func() {}, cleanup)
 }
 
+func TestMemory(t *testing.T) {
+
+	repo := NewMemory()
+	cleanup := func() {
+		repo.delete(string(testNodeName1))
+	}
+
+	testNodeRepo(t, repo, func() {}, cleanup)
+}
+
 func testNodeRepo(t *testing.T, repo node.Repo, setup, cleanup func()) {
 
 	r := require.New(t)
@@ -162,7 +172,7 @@ func TestIterator(t *testing.T) {
 
 	r := require.New(t)
 
-	repo, err := NewPebble(t.TempDir())
+	repo, err := NewPebble(t.TempDir(), t.TempDir())
 	r.NoError(err)
 	defer func() {
 		err := repo.Close()
@@ -185,4 +195,127 @@ func TestIterator(t *testing.T) {
 		i++
 		return true
 	})
+
+	memory := NewMemory()
+	r.NoError(memory.AppendChanges(creation))
+	for _, repo := range []node.Repo{repo, memory} {
+		var names []string
+		repo.IterateFrom([]byte("test\x00"), []byte("test\x00a"), func(name []byte) bool {
+			names = append(names, string(name))
+			return true
+		})
+		r.Equal([]string{"test\x00b", "test\x00\xFF"}, names)
+
+		names = nil
+		repo.IterateFrom([]byte("test\x00\xFF"), nil, func(name []byte) bool {
+			names = append(names, string(name))
+			return true
+		})
+		r.Equal([]string{"test\x00\xFF"}, names)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir(), t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	_, data, err := repo.LoadSnapshot(testNodeName1, 10)
+	r.NoError(err)
+	r.Nil(data)
+
+	r.NoError(repo.SetSnapshot(testNodeName1, 3, []byte("3")))
+	r.NoError(repo.SetSnapshot(testNodeName1, 6, []byte("6")))
+	r.NoError(repo.SetSnapshot(append(testNodeName1, 'a'), 4, []byte("other")))
+
+	height, data, err := repo.LoadSnapshot(testNodeName1, 5)
+	r.NoError(err)
+	r.Equal(int32(3), height)
+	r.Equal([]byte("3"), data)
+
+	height, data, err = repo.LoadSnapshot(testNodeName1, 100)
+	r.NoError(err)
+	r.Equal(int32(6), height)
+	r.Equal([]byte("6"), data)
+
+	_, data, err = repo.LoadSnapshot(testNodeName1, 2)
+	r.NoError(err)
+	r.Nil(data)
+
+	// dropping changes above 4 drops the snapshot at 6 but not those of other names
+	chg := change.NewChange(change.AddClaim).SetName(testNodeName1).SetOutPoint(out1)
+	r.NoError(repo.AppendChanges([]change.Change{chg.SetHeight(3), chg.SetHeight(6)}))
+	r.NoError(repo.DropChanges(testNodeName1, 4))
+
+	height, data, err = repo.LoadSnapshot(testNodeName1, 100)
+	r.NoError(err)
+	r.Equal(int32(3), height)
+	r.Equal([]byte("3"), data)
+
+	height, data, err = repo.LoadSnapshot(append(testNodeName1, 'a'), 100)
+	r.NoError(err)
+	r.Equal(int32(4), height)
+	r.Equal([]byte("other"), data)
+
+	r.NoError(repo.DropSnapshots(testNodeName1, 0))
+	_, data, err = repo.LoadSnapshot(testNodeName1, 100)
+	r.NoError(err)
+	r.Nil(data)
+}
+
+func TestPruneChanges(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir(), t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	height, err := repo.PrunedHeight()
+	r.NoError(err)
+	r.Equal(int32(0), height)
+	r.NoError(repo.SetPrunedHeight(42))
+	height, err = repo.PrunedHeight()
+	r.NoError(err)
+	r.Equal(int32(42), height)
+
+	chg := change.NewChange(change.AddClaim).SetName(testNodeName1).SetOutPoint(out1)
+	r.NoError(repo.AppendChanges([]change.Change{chg.SetHeight(3), chg.SetHeight(6), chg.SetHeight(9)}))
+	r.NoError(repo.SetSnapshot(testNodeName1, 3, []byte("3")))
+
+	// the last change at or below the height has to be at the height
+	r.Error(repo.PruneChanges(testNodeName1, 7, []byte("7")))
+
+	r.NoError(repo.PruneChanges(testNodeName1, 6, []byte("6")))
+	changes, err := repo.LoadChanges(testNodeName1)
+	r.NoError(err)
+	r.Len(changes, 2)
+	r.Equal(int32(6), changes[0].Height)
+	r.Equal(int32(9), changes[1].Height)
+
+	height, data, err := repo.LoadSnapshot(testNodeName1, 100)
+	r.NoError(err)
+	r.Equal(int32(6), height)
+	r.Equal([]byte("6"), data)
+	_, data, err = repo.LoadSnapshot(testNodeName1, 5)
+	r.NoError(err)
+	r.Nil(data)
+
+	// a nil snapshot removes the node altogether
+	r.NoError(repo.PruneChanges(testNodeName1, 9, nil))
+	changes, err = repo.LoadChanges(testNodeName1)
+	r.NoError(err)
+	r.Empty(changes)
+	_, data, err = repo.LoadSnapshot(testNodeName1, 100)
+	r.NoError(err)
+	r.Nil(data)
 }
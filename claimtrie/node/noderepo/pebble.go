@@ -2,6 +2,8 @@ package noderepo
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math"
 	"sort"
 
 	"github.com/cockroachdb/pebble"
@@ -11,14 +13,26 @@ import (
 
 type Pebble struct {
 	db *pebble.DB
+
+	// The names of nodes can hold any bytes so their snapshots get a database of their own.
+	snapshots *pebble.DB
 }
 
-func NewPebble(path string) (*Pebble, error) {
+func NewPebble(path, snapshotPath string) (*Pebble, error) {
 
 	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(64 << 20), BytesPerSync: 8 << 20, MaxOpenFiles: 2000})
-	repo := &Pebble{db: db}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open %s", path)
+	}
+
+	snapshots, err := pebble.Open(snapshotPath, &pebble.Options{Cache: pebble.NewCache(16 << 20), MaxOpenFiles: 2000})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrapf(err, "unable to open %s", snapshotPath)
+	}
 
-	return repo, errors.Wrapf(err, "unable to open %s", path)
+	repo := &Pebble{db: db, snapshots: snapshots}
+	return repo, nil
 }
 
 // AppendChanges makes an assumption that anything you pass to it is newer than what was saved before.
@@ -85,16 +99,25 @@ func (repo *Pebble) DropChanges(name []byte, finalHeight int32) error {
 	if err != nil {
 		return errors.Wrapf(err, "in load changes for %s", name)
 	}
+	// the snapshots from the lowest height of a dropped change on go with them
+	dropped := finalHeight + 1
 	i := 0
 	for ; i < len(changes); i++ { // assuming changes are ordered by height
 		if changes[i].Height > finalHeight {
 			break
 		}
 		if changes[i].VisibleHeight > finalHeight { // created after this height has to be deleted
+			if changes[i].Height < dropped {
+				dropped = changes[i].Height
+			}
 			changes = append(changes[:i], changes[i+1:]...)
 			i--
 		}
 	}
+	err = repo.DropSnapshots(name, dropped)
+	if err != nil {
+		return errors.Wrapf(err, "in drop snapshots for %s", name)
+	}
 	// making a performance assumption that DropChanges won't happen often:
 	err = repo.db.Set(name, []byte{}, pebble.NoSync)
 	if err != nil {
@@ -103,27 +126,138 @@ func (repo *Pebble) DropChanges(name []byte, finalHeight int32) error {
 	return repo.AppendChanges(changes[:i])
 }
 
-func (repo *Pebble) IterateChildren(name []byte, f func(changes []change.Change) bool) error {
-	start := make([]byte, len(name)+1) // zeros that last byte; need a constant len for stack alloc?
-	copy(start, name)
+// snapshotKey format: len(name)(2B) + name + height(4B)
+func snapshotKey(name []byte, height uint32) []byte {
+	key := make([]byte, 2+len(name)+4)
+	binary.BigEndian.PutUint16(key, uint16(len(name)))
+	copy(key[2:], name)
+	binary.BigEndian.PutUint32(key[2+len(name):], height)
+	return key
+}
+
+func (repo *Pebble) SetSnapshot(name []byte, height int32, data []byte) error {
+	return errors.Wrap(repo.snapshots.Set(snapshotKey(name, uint32(height)), data, pebble.NoSync), "in set")
+}
+
+func (repo *Pebble) LoadSnapshot(name []byte, height int32) (int32, []byte, error) {
+
+	if height < 0 {
+		return 0, nil, nil
+	}
+	iter := repo.snapshots.NewIter(&pebble.IterOptions{
+		LowerBound: snapshotKey(name, 0),
+		UpperBound: snapshotKey(name, uint32(height)+1),
+	})
+	defer iter.Close()
+
+	if !iter.Last() {
+		return 0, nil, errors.Wrap(iter.Error(), "in iterate")
+	}
+	key := iter.Key()
+	data := make([]byte, len(iter.Value())) // the value is only valid until the iterator moves
+	copy(data, iter.Value())
+	return int32(binary.BigEndian.Uint32(key[len(key)-4:])), data, nil
+}
+
+func (repo *Pebble) DropSnapshots(name []byte, height int32) error {
+
+	if height < 0 {
+		height = 0
+	}
+	start := snapshotKey(name, uint32(height))
+	end := append(snapshotKey(name, math.MaxUint32), 0) // just past the highest key of the name
+	return errors.Wrap(repo.snapshots.DeleteRange(start, end, pebble.NoSync), "in delete range")
+}
+
+// PruneChanges writes the snapshots first, so that the changes are all there until the snapshot that replaces
+// some of them is, whatever point a crash stops it at.
+func (repo *Pebble) PruneChanges(name []byte, height int32, snapshot []byte) error {
+
+	if snapshot == nil {
+		if err := repo.DropSnapshots(name, 0); err != nil {
+			return errors.Wrapf(err, "in drop snapshots for %s", name)
+		}
+		return errors.Wrapf(repo.db.Delete(name, pebble.NoSync), "in delete %s", name)
+	}
+
+	changes, err := repo.LoadChanges(name)
+	if err != nil {
+		return errors.Wrapf(err, "in load changes for %s", name)
+	}
+	i := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Height > height
+	})
+	if i == 0 || changes[i-1].Height != height {
+		return errors.Errorf("the last change of %s at or below %d is not at %d", name, height, height)
+	}
+
+	snapshots := repo.snapshots.NewBatch()
+	defer snapshots.Close()
+	err = snapshots.Set(snapshotKey(name, uint32(height)), snapshot, pebble.NoSync)
+	if err != nil {
+		return errors.Wrapf(err, "in set snapshot for %s", name)
+	}
+	err = snapshots.DeleteRange(snapshotKey(name, 0), snapshotKey(name, uint32(height)), pebble.NoSync)
+	if err != nil {
+		return errors.Wrapf(err, "in drop older snapshots for %s", name)
+	}
+	if err = snapshots.Commit(pebble.NoSync); err != nil {
+		return errors.Wrapf(err, "in commit snapshots for %s", name)
+	}
 
+	// the stored changes are their marshaled forms one after the other, as merged by AppendChanges
+	buffer := bytes.NewBuffer(nil)
+	for _, chg := range changes[i-1:] {
+		if err = chg.Marshal(buffer); err != nil {
+			return errors.Wrap(err, "in marshaller")
+		}
+	}
+	return errors.Wrapf(repo.db.Set(name, buffer.Bytes(), pebble.NoSync), "in set %s", name)
+}
+
+// prunedHeightKey can't collide with the snapshot keys as names are shorter than 255 bytes.
+var prunedHeightKey = []byte{0xff, 0xff}
+
+func (repo *Pebble) PrunedHeight() (int32, error) {
+
+	data, closer, err := repo.snapshots.Get(prunedHeightKey)
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
+func (repo *Pebble) SetPrunedHeight(height int32) error {
+
+	var data [4]byte
+	binary.BigEndian.PutUint32(data[:], uint32(height))
+	return errors.Wrap(repo.snapshots.Set(prunedHeightKey, data[:], pebble.NoSync), "in set")
+}
+
+// prefixEnd returns the first key after the keys that start with name, or nil when there's none.
+func prefixEnd(name []byte) []byte {
 	end := make([]byte, len(name)) // max name length is 255
 	copy(end, name)
-	validEnd := false
 	for i := len(name) - 1; i >= 0; i-- {
 		end[i]++
 		if end[i] != 0 {
-			validEnd = true
-			break
+			return end[:i+1]
 		}
 	}
-	if !validEnd {
-		end = nil // uh, we think this means run to the end of the table
-	}
+	return nil // uh, we think this means run to the end of the table
+}
+
+func (repo *Pebble) IterateChildren(name []byte, f func(changes []change.Change) bool) error {
+	start := make([]byte, len(name)+1) // zeros that last byte; need a constant len for stack alloc?
+	copy(start, name)
 
 	prefixIterOptions := &pebble.IterOptions{
 		LowerBound: start,
-		UpperBound: end,
+		UpperBound: prefixEnd(name),
 	}
 
 	iter := repo.db.NewIter(prefixIterOptions)
@@ -153,6 +287,20 @@ func (repo *Pebble) IterateAll(predicate func(name []byte) bool) {
 	}
 }
 
+func (repo *Pebble) IterateFrom(prefix, start []byte, predicate func(name []byte) bool) {
+	if bytes.Compare(start, prefix) < 0 {
+		start = prefix
+	}
+	iter := repo.db.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: prefixEnd(prefix)})
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		if !predicate(iter.Key()) {
+			break
+		}
+	}
+}
+
 func (repo *Pebble) Close() error {
 
 	err := repo.db.Flush()
@@ -160,12 +308,24 @@ func (repo *Pebble) Close() error {
 		// if we fail to close are we going to try again later?
 		return errors.Wrap(err, "on flush")
 	}
+	err = repo.snapshots.Flush()
+	if err != nil {
+		return errors.Wrap(err, "on snapshots flush")
+	}
 
+	err = repo.snapshots.Close()
+	if err != nil {
+		return errors.Wrap(err, "on snapshots close")
+	}
 	err = repo.db.Close()
 	return errors.Wrap(err, "on close")
 }
 
 func (repo *Pebble) Flush() error {
 	_, err := repo.db.AsyncFlush()
+	if err != nil {
+		return err
+	}
+	_, err = repo.snapshots.AsyncFlush()
 	return err
 }
@@ -15,8 +15,33 @@ type Repo interface {
 	// If no changes found, both returned slice and error will be nil.
 	LoadChanges(name []byte) ([]change.Change, error)
 
+	// DropChanges removes the changes of a node above finalHeight, along with the
+	// snapshots that include any of them.
 	DropChanges(name []byte, finalHeight int32) error
 
+	// SetSnapshot stores the serialized state of a node with all its changes up to
+	// (includes) the specified height applied.
+	SetSnapshot(name []byte, height int32, data []byte) error
+
+	// LoadSnapshot returns the latest snapshot of a node at or below the specified
+	// height along with its height. If none is found, the returned data and error will be nil.
+	LoadSnapshot(name []byte, height int32) (int32, []byte, error)
+
+	// DropSnapshots removes the snapshots of a node at or above the specified height.
+	DropSnapshots(name []byte, height int32) error
+
+	// PruneChanges replaces the changes of a node up to (includes) the specified height with
+	// a snapshot of the node at that height, which must be the height of the last of them.
+	// That change is kept as the node needs one to show up in iterations.
+	// A nil snapshot removes the node along with its snapshots.
+	PruneChanges(name []byte, height int32, snapshot []byte) error
+
+	// PrunedHeight returns the height below which the changes have been pruned, or 0.
+	PrunedHeight() (int32, error)
+
+	// SetPrunedHeight records the height below which the changes have been pruned.
+	SetPrunedHeight(height int32) error
+
 	// Close closes the repo.
 	Close() error
 
@@ -27,5 +52,8 @@ type Repo interface {
 	// IterateAll iterates keys until the predicate function returns false
 	IterateAll(predicate func(name []byte) bool)
 
+	// IterateFrom iterates the keys that start with prefix, from start on, until the predicate function returns false
+	IterateFrom(prefix, start []byte, predicate func(name []byte) bool)
+
 	Flush() error
 }
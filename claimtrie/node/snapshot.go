@@ -0,0 +1,64 @@
+package node
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// snapshotInterval is the number of changes replayed for a name before the manager stores a snapshot of it.
+const snapshotInterval = 100
+
+// snapshot is the stored form of a Node; the best claim is stored by its index in the claims.
+type snapshot struct {
+	BestIndex   int    `msgpack:"b"`
+	BestClaim   *Claim `msgpack:"c,omitempty"` // only when the best claim isn't one of the claims
+	TakenOverAt int32  `msgpack:"t"`
+	Claims      ClaimList
+	Supports    ClaimList
+	SupportSums map[string]int64
+}
+
+func marshalSnapshot(n *Node) ([]byte, error) {
+	s := snapshot{
+		BestIndex:   -1,
+		TakenOverAt: n.TakenOverAt,
+		Claims:      n.Claims,
+		Supports:    n.Supports,
+		SupportSums: n.SupportSums,
+	}
+	for i, c := range n.Claims {
+		if c == n.BestClaim {
+			s.BestIndex = i
+			break
+		}
+	}
+	if s.BestIndex < 0 {
+		s.BestClaim = n.BestClaim
+	}
+	data, err := msgpack.Marshal(&s)
+	return data, errors.Wrap(err, "in marshal")
+}
+
+func unmarshalSnapshot(data []byte) (*Node, error) {
+	var s snapshot
+	if err := msgpack.Unmarshal(data, &s); err != nil {
+		return nil, errors.Wrap(err, "in unmarshal")
+	}
+	n := &Node{
+		BestClaim:   s.BestClaim,
+		TakenOverAt: s.TakenOverAt,
+		Claims:      s.Claims,
+		Supports:    s.Supports,
+		SupportSums: s.SupportSums,
+	}
+	if s.BestIndex >= 0 {
+		if s.BestIndex >= len(n.Claims) {
+			return nil, errors.Errorf("best claim index %d is out of range", s.BestIndex)
+		}
+		n.BestClaim = n.Claims[s.BestIndex]
+	}
+	if n.SupportSums == nil {
+		n.SupportSums = map[string]int64{}
+	}
+	return n, nil
+}
@@ -0,0 +1,106 @@
+package node
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+)
+
+// Takeover describes a change of the claim that controls a name.
+// A zero ClaimID means that there was, or is, no controlling claim.
+type Takeover struct {
+	Height int32
+
+	PreviousClaimID change.ClaimID
+	ClaimID         change.ClaimID
+
+	// Effective amounts (stake plus active supports) of both claims at Height.
+	PreviousAmount int64
+	Amount         int64
+}
+
+// Takeovers replays the changes of a node up to (includes) the specified height and
+// returns the takeovers in the order they happened. Those of pruned changes are left out.
+func (nm *BaseManager) Takeovers(height int32, name []byte) ([]Takeover, error) {
+
+	if height < nm.prunedHeight {
+		return nil, errors.Wrapf(ErrPruned, "height %d is below %d", height, nm.prunedHeight)
+	}
+
+	changes, err := nm.repo.LoadChanges(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "in load changes")
+	}
+
+	n, base, changes, err := nm.prunedBase(name, changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "in pruned base")
+	}
+
+	var takeovers []Takeover
+	var previous change.ClaimID
+	if n.HasActiveBestClaim() {
+		previous = n.BestClaim.ClaimID
+	}
+
+	adjust := func(from, to int32, name []byte) {
+		// the same steps AdjustTo(from, to) takes, one height at a time
+		for h := from; h <= to; h = n.NextUpdate() {
+			n.AdjustTo(h, h, name)
+			if t := TakeoverAt(h, previous, n); t != nil {
+				takeovers = append(takeovers, *t)
+				previous = t.ClaimID
+			}
+		}
+	}
+
+	for i, chg := range changes {
+		if chg.Height > height {
+			break
+		}
+		if i == 0 && base > 0 {
+			adjust(base, chg.Height-1, chg.Name)
+		}
+		if i > 0 && changes[i-1].Height < chg.Height {
+			adjust(changes[i-1].Height, chg.Height-1, chg.Name)
+		}
+
+		delay := nm.getDelayForName(n, chg)
+		if err = n.ApplyChange(chg, delay); err != nil {
+			return nil, errors.Wrap(err, "in apply change")
+		}
+	}
+
+	last := base
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].Height <= height {
+			last = changes[i].Height
+			break
+		}
+	}
+	if last > 0 {
+		adjust(last, height, name)
+	}
+	return takeovers, nil
+}
+
+// TakeoverAt returns the takeover that happened at height on a node whose controlling claim was previous
+// before the height and that is n at the height, or nil if there was none. A nil n is a node without changes.
+func TakeoverAt(height int32, previous change.ClaimID, n *Node) *Takeover {
+
+	t := &Takeover{Height: height, PreviousClaimID: previous}
+	if n == nil || n.TakenOverAt != height {
+		return nil
+	}
+	if n.BestClaim != nil {
+		t.ClaimID = n.BestClaim.ClaimID
+		t.Amount = n.BestClaim.Amount + n.SupportSums[t.ClaimID.Key()]
+	}
+	if t.ClaimID == previous && previous == (change.ClaimID{}) {
+		return nil // nothing changed on a node without claims
+	}
+	if c := n.Claims.find(byID(previous)); c != nil && c.Status == Activated {
+		t.PreviousAmount = c.Amount + n.SupportSums[previous.Key()]
+	}
+	return t
+}
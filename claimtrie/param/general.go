@@ -62,13 +62,17 @@ var (
 )
 
 func SetNetwork(net wire.BitcoinNet) {
+	ActiveParams = NetworkParams(net)
+}
+
+// NetworkParams returns the default ClaimTrie parameters of a network.
+func NetworkParams(net wire.BitcoinNet) ClaimTrieParams {
 
 	switch net {
-	case wire.MainNet:
-		ActiveParams = MainNet
 	case wire.TestNet3:
-		ActiveParams = TestNet
+		return TestNet
 	case wire.TestNet, wire.SimNet: // "regtest"
-		ActiveParams = Regtest
+		return Regtest
 	}
+	return MainNet
 }
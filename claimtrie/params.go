@@ -0,0 +1,48 @@
+package claimtrie
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/param"
+)
+
+// ErrParamsChanged is returned by New when the repos were built with other ClaimTrie parameters than the active ones.
+var ErrParamsChanged = errors.New("the claimtrie was built with other parameters")
+
+// consensusParams returns the parameters the hashes of the ClaimTrie depend on.
+func consensusParams() param.ClaimTrieParams {
+	p := param.ActiveParams
+	p.MaxNodeManagerCacheSize = 0
+	return p
+}
+
+// checkParams compares the parameters recorded in the file at path with the active ones, and records them
+// when there are none, as for new repos. Repos from before the file was kept are assumed to match.
+func checkParams(path string) error {
+
+	active := consensusParams()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = json.MarshalIndent(active, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshal params")
+		}
+		return errors.Wrap(ioutil.WriteFile(path, data, 0644), "write params")
+	}
+	if err != nil {
+		return errors.Wrap(err, "read params")
+	}
+
+	var recorded param.ClaimTrieParams
+	if err = json.Unmarshal(data, &recorded); err != nil {
+		return errors.Wrapf(err, "unmarshal params in %s", path)
+	}
+	if recorded != active {
+		return errors.Wrapf(ErrParamsChanged, "%+v rather than %+v; remove the claim_dbs to rebuild it", recorded, active)
+	}
+	return nil
+}
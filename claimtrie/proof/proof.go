@@ -0,0 +1,229 @@
+// Package proof verifies that a name, and optionally one of its claims, is
+// (or is not) committed to by the ClaimTrie root in a block header.
+//
+// The package only depends on chainhash and wire so that light clients can
+// use it without pulling in the claimtrie databases.
+package proof
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/wire"
+)
+
+var (
+	// EmptyTrieHash, NoChildrenHash and NoClaimsHash mirror the constants used by the merkletrie package.
+	EmptyTrieHash  = &chainhash.Hash{1}
+	NoChildrenHash = &chainhash.Hash{2}
+	NoClaimsHash   = &chainhash.Hash{3}
+
+	ErrRootMismatch = errors.New("proof does not match the ClaimTrie root")
+	ErrMalformed    = errors.New("malformed proof")
+)
+
+// Child is a (character, hash) link from a node to one of its children in the pre-fork layout.
+type Child struct {
+	Character byte
+	Hash      chainhash.Hash
+}
+
+// Node is one level of the path from the root to a name in the pre-fork layout.
+// The Nth node is the node for the first N bytes of the name. Children holds the
+// links of the node except for the one that continues the path; the last node
+// in the path holds all of its links.
+type Node struct {
+	Children  []Child
+	ValueHash *chainhash.Hash
+}
+
+// Pair is a sibling hash in the post-fork (all claims in merkle) layout.
+// Right is true when the sibling is the right-hand side of the combination.
+type Pair struct {
+	Hash  chainhash.Hash
+	Right bool
+}
+
+// Proof contains everything needed to recompute the ClaimTrie root for a name.
+// When OutPoint is nil the proof shows that the name has no claim in the trie.
+//
+// In the post-fork layout the root commits to the claims but not to the names
+// they are on, so the proof doesn't bind Name and there are no proofs of
+// non-existence. It shows that the claim at OutPoint is in the trie; the name is
+// tied to it by the claim script of that output, which the verifier must check.
+type Proof struct {
+	Name []byte
+
+	OutPoint       *wire.OutPoint
+	TakeoverHeight int32
+
+	// AllClaimsInMerkle selects the layout; when true Pairs is used, otherwise Nodes.
+	AllClaimsInMerkle bool
+
+	Nodes []Node
+	Pairs []Pair
+}
+
+// Root computes the ClaimTrie root committed to by the proof.
+func (p *Proof) Root() (*chainhash.Hash, error) {
+	if p.AllClaimsInMerkle {
+		return p.rootAllClaims()
+	}
+	return p.rootNodes()
+}
+
+// Verify checks the proof against the ClaimTrie root of the header. It only
+// checks Name in the pre-fork layout; see Proof.
+func (p *Proof) Verify(header *wire.BlockHeader) error {
+	root, err := p.Root()
+	if err != nil {
+		return err
+	}
+	if !root.IsEqual(&header.ClaimTrie) {
+		return ErrRootMismatch
+	}
+	return nil
+}
+
+func (p *Proof) rootAllClaims() (*chainhash.Hash, error) {
+	if p.OutPoint == nil {
+		// names are not committed to in this layout so there is nothing to recompute
+		return nil, ErrMalformed
+	}
+
+	h := ClaimHash(*p.OutPoint, p.TakeoverHeight)
+	for _, pair := range p.Pairs {
+		if pair.Right {
+			h = HashMerkleBranches(h, &pair.Hash)
+		} else {
+			h = HashMerkleBranches(&pair.Hash, h)
+		}
+	}
+	return h, nil
+}
+
+func (p *Proof) rootNodes() (*chainhash.Hash, error) {
+	last := len(p.Nodes) - 1
+	if last < 0 || last > len(p.Name) {
+		return nil, ErrMalformed
+	}
+
+	tail := p.Nodes[last]
+	if p.OutPoint != nil {
+		if last != len(p.Name) || tail.ValueHash == nil || !tail.ValueHash.IsEqual(ClaimHash(*p.OutPoint, p.TakeoverHeight)) {
+			return nil, ErrMalformed
+		}
+	} else if last == len(p.Name) {
+		if tail.ValueHash != nil {
+			return nil, ErrMalformed
+		}
+	} else {
+		for _, c := range tail.Children {
+			if c.Character == p.Name[last] {
+				return nil, ErrMalformed
+			}
+		}
+	}
+
+	var h *chainhash.Hash
+	for i := last; i >= 0; i-- {
+		children := p.Nodes[i].Children
+		if i < last {
+			if h == nil {
+				return nil, ErrMalformed
+			}
+			children = append([]Child{{Character: p.Name[i], Hash: *h}}, children...)
+		}
+		var err error
+		h, err = nodeHash(children, p.Nodes[i].ValueHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if h == nil {
+		return EmptyTrieHash, nil
+	}
+	return h, nil
+}
+
+func nodeHash(children []Child, value *chainhash.Hash) (*chainhash.Hash, error) {
+	sorted := make([]Child, len(children))
+	copy(sorted, children)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Character < sorted[j].Character })
+
+	var b bytes.Buffer
+	for i, c := range sorted {
+		if i > 0 && sorted[i-1].Character == c.Character {
+			return nil, ErrMalformed
+		}
+		b.WriteByte(c.Character)
+		b.Write(c.Hash[:])
+	}
+	if value != nil {
+		b.Write(value[:])
+	}
+	if b.Len() == 0 {
+		return nil, nil
+	}
+	h := chainhash.DoubleHashH(b.Bytes())
+	return &h, nil
+}
+
+// HashMerkleBranches combines two hashes the same way the claimtrie does.
+func HashMerkleBranches(left *chainhash.Hash, right *chainhash.Hash) *chainhash.Hash {
+	var hash [chainhash.HashSize * 2]byte
+	copy(hash[:chainhash.HashSize], left[:])
+	copy(hash[chainhash.HashSize:], right[:])
+
+	newHash := chainhash.DoubleHashH(hash[:])
+	return &newHash
+}
+
+// ClaimHash returns the hash committed to the trie for a claim with the given
+// outpoint on a name last taken over at the given height.
+func ClaimHash(op wire.OutPoint, takeover int32) *chainhash.Hash {
+
+	txHash := chainhash.DoubleHashH(op.Hash[:])
+
+	nOut := []byte(strconv.Itoa(int(op.Index)))
+	nOutHash := chainhash.DoubleHashH(nOut)
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(takeover))
+	heightHash := chainhash.DoubleHashH(buf)
+
+	h := make([]byte, 0, sha256.Size*3)
+	h = append(h, txHash[:]...)
+	h = append(h, nOutHash[:]...)
+	h = append(h, heightHash[:]...)
+
+	hh := chainhash.DoubleHashH(h)
+
+	return &hh
+}
+
+// MerklePath returns the pairs that lead from hashes[index] to the merkle root of hashes.
+func MerklePath(hashes []*chainhash.Hash, index int) []Pair {
+	var pairs []Pair
+	level := make([]*chainhash.Hash, len(hashes))
+	copy(level, hashes)
+	for len(level) > 1 {
+		if len(level)&1 > 0 { // odd count
+			level = append(level, level[len(level)-1])
+		}
+		sibling := index ^ 1
+		pairs = append(pairs, Pair{Hash: *level[sibling], Right: sibling > index})
+		for i := 0; i < len(level); i += 2 {
+			level[i>>1] = HashMerkleBranches(level[i], level[i+1])
+		}
+		level = level[:len(level)>>1]
+		index >>= 1
+	}
+	return pairs
+}
@@ -0,0 +1,54 @@
+package proof
+
+import (
+	"testing"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/node"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerklePath(t *testing.T) {
+	r := require.New(t)
+
+	for count := 1; count < 12; count++ {
+		hashes := make([]*chainhash.Hash, count)
+		for i := range hashes {
+			h := chainhash.HashH([]byte{byte(i)})
+			hashes[i] = &h
+		}
+		input := make([]*chainhash.Hash, count)
+		copy(input, hashes)
+		root := node.ComputeMerkleRoot(input)
+
+		for i := range hashes {
+			h := hashes[i]
+			for _, pair := range MerklePath(hashes, i) {
+				if pair.Right {
+					h = HashMerkleBranches(h, &pair.Hash)
+				} else {
+					h = HashMerkleBranches(&pair.Hash, h)
+				}
+			}
+			r.Equal(*root, *h, "count %d, index %d", count, i)
+		}
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	r := require.New(t)
+
+	p := Proof{Name: []byte("a")}
+	_, err := p.Root()
+	r.Equal(ErrMalformed, err)
+
+	p.Nodes = []Node{{Children: []Child{{Character: 'a'}}}}
+	_, err = p.Root()
+	r.Equal(ErrMalformed, err)
+
+	p.Nodes = []Node{{}}
+	root, err := p.Root()
+	r.NoError(err)
+	r.Equal(*EmptyTrieHash, *root)
+}
@@ -0,0 +1,61 @@
+package ranking
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/node"
+)
+
+// Entry is a claim, or a name along with its controlling claim, ranked by its effective amount in dewies:
+// the amount of the claim plus those of its activated supports.
+type Entry struct {
+	Name    []byte
+	ClaimID change.ClaimID
+	Amount  int64
+}
+
+// Ranking is what a name contributes to the rankings.
+type Ranking struct {
+	Name   []byte
+	Best   *Entry  // The controlling claim, if it's active.
+	Claims []Entry // The active claims, best first.
+}
+
+// Of returns the contribution of a node to the rankings; the node may be nil.
+func Of(name []byte, n *node.Node) Ranking {
+	r := Ranking{Name: name}
+	if n == nil {
+		return r
+	}
+	for _, c := range n.Claims {
+		if c.Status != node.Activated {
+			continue
+		}
+		e := Entry{Name: name, ClaimID: c.ClaimID, Amount: c.Amount + n.SupportSums[c.ClaimID.Key()]}
+		r.Claims = append(r.Claims, e)
+		if n.BestClaim != nil && n.BestClaim.ClaimID == c.ClaimID {
+			best := e
+			r.Best = &best
+		}
+	}
+	Sort(r.Claims)
+	return r
+}
+
+// Less returns whether the entry ranks before the other: by decreasing amount, then by claim ID. The claim IDs
+// are unique to the names as well, since a name is ranked by its controlling claim.
+func Less(e, o Entry) bool {
+	if e.Amount != o.Amount {
+		return e.Amount > o.Amount
+	}
+	return bytes.Compare(e.ClaimID[:], o.ClaimID[:]) < 0
+}
+
+// Sort puts the entries in the order they rank.
+func Sort(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return Less(entries[i], entries[j])
+	})
+}
@@ -0,0 +1,97 @@
+package rankingrepo
+
+import (
+	"bytes"
+
+	"github.com/lbryio/lbcd/claimtrie/ranking"
+)
+
+type Memory struct {
+	rankings map[string]ranking.Ranking
+	height   int32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		rankings: map[string]ranking.Ranking{},
+		height:   -1,
+	}
+}
+
+func (repo *Memory) Set(rankings []ranking.Ranking) error {
+
+	for _, r := range rankings {
+		if r.Best == nil && len(r.Claims) == 0 {
+			delete(repo.rankings, string(r.Name))
+			continue
+		}
+		name := append([]byte{}, r.Name...)
+		clone := ranking.Ranking{Name: name}
+		if r.Best != nil {
+			best := *r.Best
+			best.Name = name
+			clone.Best = &best
+		}
+		for _, e := range r.Claims {
+			e.Name = name
+			clone.Claims = append(clone.Claims, e)
+		}
+		repo.rankings[string(name)] = clone
+	}
+	return nil
+}
+
+func (repo *Memory) iterate(entries []ranking.Entry, f func(e ranking.Entry) bool) error {
+
+	ranking.Sort(entries)
+	for _, e := range entries {
+		if !f(e) {
+			break
+		}
+	}
+	return nil
+}
+
+func (repo *Memory) IterateClaims(prefix []byte, f func(e ranking.Entry) bool) error {
+
+	var entries []ranking.Entry
+	for _, r := range repo.rankings {
+		if bytes.HasPrefix(r.Name, prefix) {
+			entries = append(entries, r.Claims...)
+		}
+	}
+	return repo.iterate(entries, f)
+}
+
+func (repo *Memory) IterateNames(prefix []byte, f func(e ranking.Entry) bool) error {
+
+	var entries []ranking.Entry
+	for _, r := range repo.rankings {
+		if r.Best != nil && bytes.HasPrefix(r.Name, prefix) {
+			entries = append(entries, *r.Best)
+		}
+	}
+	return repo.iterate(entries, f)
+}
+
+func (repo *Memory) Clear() error {
+	repo.rankings = map[string]ranking.Ranking{}
+	return nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+	repo.height = height
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
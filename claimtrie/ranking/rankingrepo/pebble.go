@@ -0,0 +1,280 @@
+package rankingrepo
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/ranking"
+)
+
+// key formats:
+//
+//	claim: 'c'(1B) + ^amount(8B) + claimID(20B) -> name(variable length)
+//	name:  'n'(1B) + ^amount(8B) + claimID(20B) -> name(variable length)
+//	state: 's'(1B) + name(variable length) -> has best(1B) + best and claims (amount(8B) + claimID(20B) each)
+//	tip:   't'(1B) -> height(4B)
+//
+// The amounts are inverted so that the keys come in the order the entries rank.
+const (
+	claimPrefix = 'c'
+	namePrefix  = 'n'
+	statePrefix = 's'
+	tipPrefix   = 't'
+
+	entrySize = 8 + change.ClaimIDSize
+
+	// maxSeekedNames is the number of names with a prefix up to which their entries are read from their
+	// states and sorted, rather than picked out of the whole ranking, where the common prefixes come up soon.
+	maxSeekedNames = 1000
+)
+
+type Pebble struct {
+	db *pebble.DB
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(16 << 20), MaxOpenFiles: 2000})
+	repo := &Pebble{db: db}
+
+	return repo, errors.Wrapf(err, "unable to open %s", path)
+}
+
+func entryKey(prefix byte, e ranking.Entry) []byte {
+	key := make([]byte, 1+8, 1+entrySize)
+	key[0] = prefix
+	binary.BigEndian.PutUint64(key[1:], ^uint64(e.Amount))
+	return append(key, e.ClaimID[:]...)
+}
+
+func appendEntry(b []byte, e ranking.Entry) []byte {
+	var amount [8]byte
+	binary.BigEndian.PutUint64(amount[:], uint64(e.Amount))
+	return append(append(b, amount[:]...), e.ClaimID[:]...)
+}
+
+func parseEntry(b []byte) ranking.Entry {
+	e := ranking.Entry{Amount: int64(binary.BigEndian.Uint64(b))}
+	copy(e.ClaimID[:], b[8:])
+	return e
+}
+
+func (repo *Pebble) Set(rankings []ranking.Ranking) error {
+
+	batch := repo.db.NewIndexedBatch()
+	defer batch.Close()
+
+	// take the names out of the rankings they were in before putting any back, since a claim can move
+	// from one name to another, such as when the names are normalized
+	for _, r := range rankings {
+		if err := removeRanking(batch, r.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range rankings {
+		if r.Best == nil && len(r.Claims) == 0 {
+			continue
+		}
+
+		value := []byte{0}
+		if r.Best != nil {
+			value[0] = 1
+			value = appendEntry(value, *r.Best)
+			if err := batch.Set(entryKey(namePrefix, *r.Best), r.Name, pebble.NoSync); err != nil {
+				return errors.Wrap(err, "in set name")
+			}
+		}
+		for _, e := range r.Claims {
+			value = appendEntry(value, e)
+			if err := batch.Set(entryKey(claimPrefix, e), r.Name, pebble.NoSync); err != nil {
+				return errors.Wrap(err, "in set claim")
+			}
+		}
+		if err := batch.Set(append([]byte{statePrefix}, r.Name...), value, pebble.NoSync); err != nil {
+			return errors.Wrap(err, "in set state")
+		}
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+// removeRanking deletes the entries of a name, and its state.
+func removeRanking(batch *pebble.Batch, name []byte) error {
+
+	stateKey := append([]byte{statePrefix}, name...)
+	state, closer, err := batch.Get(stateKey)
+	if err == pebble.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "in get state")
+	}
+
+	var keys [][]byte
+	if state[0] == 1 {
+		keys = append(keys, entryKey(namePrefix, parseEntry(state[1:])))
+		state = state[1+entrySize:]
+	} else {
+		state = state[1:]
+	}
+	for ; len(state) >= entrySize; state = state[entrySize:] {
+		keys = append(keys, entryKey(claimPrefix, parseEntry(state)))
+	}
+	closer.Close()
+
+	for _, key := range append(keys, stateKey) {
+		if err = batch.Delete(key, pebble.NoSync); err != nil {
+			return errors.Wrap(err, "in delete")
+		}
+	}
+	return nil
+}
+
+func (repo *Pebble) iterate(kind byte, prefix []byte, f func(e ranking.Entry) bool) error {
+
+	if len(prefix) > 0 {
+		entries, ok, err := repo.entriesOf(kind, prefix)
+		if err != nil {
+			return err
+		}
+		if ok {
+			ranking.Sort(entries)
+			for _, e := range entries {
+				if !f(e) {
+					break
+				}
+			}
+			return nil
+		}
+	}
+
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte{kind},
+		UpperBound: []byte{kind + 1},
+	})
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		if !bytes.HasPrefix(iter.Value(), prefix) {
+			continue
+		}
+		key := iter.Key()
+		e := ranking.Entry{
+			Name:   append([]byte{}, iter.Value()...),
+			Amount: int64(^binary.BigEndian.Uint64(key[1:])),
+		}
+		copy(e.ClaimID[:], key[9:])
+		if !f(e) {
+			break
+		}
+	}
+	return errors.Wrap(iter.Error(), "in iterate")
+}
+
+// entriesOf returns the entries of the kind, claimPrefix or namePrefix, of the names that start with prefix,
+// from their states. It returns false when there are more than maxSeekedNames of them.
+func (repo *Pebble) entriesOf(kind byte, prefix []byte) ([]ranking.Entry, bool, error) {
+
+	lower := append([]byte{statePrefix}, prefix...)
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: lower,
+		UpperBound: prefixEnd(lower),
+	})
+	defer iter.Close()
+
+	var entries []ranking.Entry
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		if count++; count > maxSeekedNames {
+			return nil, false, nil
+		}
+		name := append([]byte{}, iter.Key()[1:]...)
+		state := iter.Value()
+		if state[0] == 1 {
+			if kind == namePrefix {
+				e := parseEntry(state[1:])
+				e.Name = name
+				entries = append(entries, e)
+			}
+			state = state[1+entrySize:]
+		} else {
+			state = state[1:]
+		}
+		if kind != claimPrefix {
+			continue
+		}
+		for ; len(state) >= entrySize; state = state[entrySize:] {
+			e := parseEntry(state)
+			e.Name = name
+			entries = append(entries, e)
+		}
+	}
+	return entries, true, errors.Wrap(iter.Error(), "in iterate states")
+}
+
+// prefixEnd returns the first key past the ones that start with prefix, which mustn't be all 0xff.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+func (repo *Pebble) IterateClaims(prefix []byte, f func(e ranking.Entry) bool) error {
+	return repo.iterate(claimPrefix, prefix, f)
+}
+
+func (repo *Pebble) IterateNames(prefix []byte, f func(e ranking.Entry) bool) error {
+	return repo.iterate(namePrefix, prefix, f)
+}
+
+func (repo *Pebble) Clear() error {
+	err := repo.db.DeleteRange([]byte{claimPrefix}, []byte{statePrefix + 1}, pebble.NoSync)
+	return errors.Wrap(err, "in delete range")
+}
+
+func (repo *Pebble) SetHeight(height int32) error {
+
+	var tip [4]byte
+	binary.BigEndian.PutUint32(tip[:], uint32(height))
+	return errors.Wrap(repo.db.Set([]byte{tipPrefix}, tip[:], pebble.NoSync), "in set")
+}
+
+func (repo *Pebble) Height() (int32, error) {
+
+	data, closer, err := repo.db.Get([]byte{tipPrefix})
+	if err == pebble.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
+func (repo *Pebble) Close() error {
+
+	err := repo.db.Flush()
+	if err != nil {
+		// if we fail to close are we going to try again later?
+		return errors.Wrap(err, "on flush")
+	}
+
+	err = repo.db.Close()
+	return errors.Wrap(err, "on close")
+}
+
+func (repo *Pebble) Flush() error {
+	_, err := repo.db.AsyncFlush()
+	return err
+}
@@ -0,0 +1,27 @@
+package ranking
+
+// Repo defines APIs for the rankings to access persistence layer.
+type Repo interface {
+	// Set replaces what the names contribute to the rankings.
+	Set(rankings []Ranking) error
+
+	// IterateClaims calls f with the claims of the names that start with prefix in the order they rank,
+	// until it returns false.
+	IterateClaims(prefix []byte, f func(e Entry) bool) error
+
+	// IterateNames calls f with the names that start with prefix in the order their controlling claims rank,
+	// until it returns false.
+	IterateNames(prefix []byte, f func(e Entry) bool) error
+
+	// Clear removes all the names from the rankings.
+	Clear() error
+
+	// SetHeight records the height the rankings are up to date with.
+	SetHeight(height int32) error
+
+	// Height returns the height the rankings are up to date with, or -1 for new rankings.
+	Height() (int32, error)
+
+	Close() error
+	Flush() error
+}
@@ -0,0 +1,119 @@
+package claimtrie
+
+import (
+	"math"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/normalization"
+	"github.com/lbryio/lbcd/wire"
+
+	"github.com/pkg/errors"
+)
+
+// BidSimulation is the outcome of a bid placed in the next block, as things stand at the current height.
+type BidSimulation struct {
+	Name            []byte         // The normalized name the bid is on.
+	Height          int32          // The height the bid is placed at.
+	ClaimID         change.ClaimID // The claim the bid is for.
+	ActiveAt        int32          // The height the bid activates at.
+	Controlling     bool           // Whether the claim controls the name once the bid is active.
+	TakenOverAt     int32          // The height the claim took, or takes, over the name at, if it's controlling.
+	EffectiveAmount int64          // The effective amount of the claim once the bid is active.
+	WinningAmount   int64          // The effective amount of the strongest other active claim by then.
+	Needed          int64          // How much more the bid needs to beat that claim; 0 if it does.
+}
+
+// SimulateBid returns what a bid of amount on name would do if it were placed in the next block. The bid is a
+// new claim for AddClaim, an update of the claim id for UpdateClaim, or a support for it for AddSupport.
+// It's placed and activated with the same delays as a real one, but only the blocks to come know what else
+// they bring to the name.
+func (ct *ClaimTrie) SimulateBid(name []byte, amount int64, typ change.ChangeType, id change.ClaimID) (*BidSimulation, error) {
+
+	height := ct.height + 1
+	name = normalization.NormalizeIfNecessary(name, height)
+
+	// no real output can have that index, so the bid never collides with an existing one
+	out := wire.OutPoint{Index: math.MaxUint32}
+	bid := change.NewChange(typ).SetHeight(height).SetName(name).SetOutPoint(&out).SetAmount(amount)
+
+	var changes []change.Change
+	switch typ {
+	case change.AddClaim:
+		id = change.NewClaimID(out)
+	case change.UpdateClaim, change.AddSupport:
+		n, err := ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			return nil, errors.Wrap(err, "node manager get")
+		}
+		var existing *node.Claim
+		if n != nil {
+			for _, c := range n.Claims {
+				if c.ClaimID == id && c.Status != node.Deactivated {
+					existing = c
+				}
+			}
+		}
+		if existing == nil {
+			return nil, errors.Errorf("claim %s is not on %s", id, name)
+		}
+		if typ == change.UpdateClaim {
+			// an update spends the claim in the same transaction
+			spend := change.NewChange(change.SpendClaim).SetHeight(height).SetName(name).SetOutPoint(&existing.OutPoint)
+			spend.ClaimID = id
+			changes = append(changes, spend)
+		}
+	default:
+		return nil, errors.Errorf("unsupported bid type %d", typ)
+	}
+	bid.ClaimID = id
+	changes = append(changes, bid)
+
+	n, err := ct.nodeManager.NodeWithChanges(ct.height, name, changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "node manager apply")
+	}
+
+	placed := n.Claims
+	if typ == change.AddSupport {
+		placed = n.Supports
+	}
+	var b *node.Claim
+	for _, c := range placed {
+		if c.OutPoint == out {
+			b = c
+		}
+	}
+	if b == nil {
+		return nil, errors.Errorf("bid on %s was not placed", name)
+	}
+
+	// a takeover can activate the bid earlier than scheduled, but never later
+	for h := n.NextUpdate(); h <= b.ActiveAt; h = n.NextUpdate() {
+		n.AdjustTo(h, h, name)
+	}
+
+	sim := &BidSimulation{
+		Name:        name,
+		Height:      height,
+		ClaimID:     id,
+		ActiveAt:    b.ActiveAt,
+		Controlling: n.HasActiveBestClaim() && n.BestClaim.ClaimID == id,
+	}
+	if sim.Controlling {
+		sim.TakenOverAt = n.TakenOverAt
+	}
+	for _, c := range n.Claims {
+		amt := c.Amount + n.SupportSums[c.ClaimID.Key()]
+		if c.ClaimID == id && c.Status != node.Deactivated {
+			sim.EffectiveAmount = amt
+		} else if c.Status == node.Activated && amt > sim.WinningAmount {
+			sim.WinningAmount = amt
+		}
+	}
+	if sim.EffectiveAmount <= sim.WinningAmount {
+		// a tie only goes to the older claim, so play it safe
+		sim.Needed = sim.WinningAmount - sim.EffectiveAmount + 1
+	}
+	return sim, nil
+}
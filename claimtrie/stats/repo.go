@@ -0,0 +1,21 @@
+package stats
+
+// Repo defines APIs for the statistics to access persistence layer.
+type Repo interface {
+	// Set records the statistics as of height, and adds the changes in expirations to the number of names
+	// whose controlling claim expires at each height.
+	Set(height int32, s Stats, expirations map[int32]int64) error
+
+	// Get returns the statistics along with the height they were recorded at, or -1 when none were.
+	Get() (Stats, int32, error)
+
+	// Expirations returns the number of names whose controlling claim expires from the from height to
+	// the to height, inclusive.
+	Expirations(from, to int32) (int64, error)
+
+	// Clear removes the statistics along with the expirations.
+	Clear() error
+
+	Close() error
+	Flush() error
+}
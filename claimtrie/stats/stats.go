@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/node"
+)
+
+// Stats are aggregate counts over the names of the ClaimTrie. Amounts are in dewies.
+type Stats struct {
+	Names            int64 // names with at least one claim
+	ActiveClaims     int64
+	AcceptedClaims   int64
+	ActiveSupports   int64
+	AcceptedSupports int64
+	ClaimAmount      int64 // staked in active and accepted claims
+	SupportAmount    int64 // staked in active and accepted supports
+}
+
+// Of returns the contribution of a node to the Stats; the node may be nil.
+func Of(n *node.Node) Stats {
+	var s Stats
+	if n == nil {
+		return s
+	}
+	for _, c := range n.Claims {
+		switch c.Status {
+		case node.Activated:
+			s.ActiveClaims++
+		case node.Accepted:
+			s.AcceptedClaims++
+		default:
+			continue
+		}
+		s.ClaimAmount += c.Amount
+	}
+	for _, c := range n.Supports {
+		switch c.Status {
+		case node.Activated:
+			s.ActiveSupports++
+		case node.Accepted:
+			s.AcceptedSupports++
+		default:
+			continue
+		}
+		s.SupportAmount += c.Amount
+	}
+	if s.ActiveClaims+s.AcceptedClaims > 0 {
+		s.Names = 1
+	}
+	return s
+}
+
+// ExpireAt returns the height the controlling claim of a node expires at, or 0 when the node, which may be nil,
+// has none.
+func ExpireAt(n *node.Node) int32 {
+	if n == nil || !n.HasActiveBestClaim() {
+		return 0
+	}
+	return n.BestClaim.ExpireAt()
+}
+
+// Add adds the other Stats to these.
+func (s *Stats) Add(o Stats) {
+	s.Names += o.Names
+	s.ActiveClaims += o.ActiveClaims
+	s.AcceptedClaims += o.AcceptedClaims
+	s.ActiveSupports += o.ActiveSupports
+	s.AcceptedSupports += o.AcceptedSupports
+	s.ClaimAmount += o.ClaimAmount
+	s.SupportAmount += o.SupportAmount
+}
+
+// Sub subtracts the other Stats from these.
+func (s *Stats) Sub(o Stats) {
+	s.Names -= o.Names
+	s.ActiveClaims -= o.ActiveClaims
+	s.AcceptedClaims -= o.AcceptedClaims
+	s.ActiveSupports -= o.ActiveSupports
+	s.AcceptedSupports -= o.AcceptedSupports
+	s.ClaimAmount -= o.ClaimAmount
+	s.SupportAmount -= o.SupportAmount
+}
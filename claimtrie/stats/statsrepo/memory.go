@@ -0,0 +1,52 @@
+package statsrepo
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/stats"
+)
+
+type Memory struct {
+	stats       stats.Stats
+	height      int32
+	expirations map[int32]int64
+}
+
+func NewMemory() *Memory {
+	return &Memory{height: -1, expirations: map[int32]int64{}}
+}
+
+func (repo *Memory) Set(height int32, s stats.Stats, expirations map[int32]int64) error {
+	repo.stats, repo.height = s, height
+	for h, delta := range expirations {
+		repo.expirations[h] += delta
+		if repo.expirations[h] == 0 {
+			delete(repo.expirations, h)
+		}
+	}
+	return nil
+}
+
+func (repo *Memory) Get() (stats.Stats, int32, error) {
+	return repo.stats, repo.height, nil
+}
+
+func (repo *Memory) Expirations(from, to int32) (int64, error) {
+	var count int64
+	for h := from; h <= to; h++ {
+		count += repo.expirations[h]
+	}
+	return count, nil
+}
+
+func (repo *Memory) Clear() error {
+	repo.stats, repo.height = stats.Stats{}, -1
+	repo.expirations = map[int32]int64{}
+	return nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
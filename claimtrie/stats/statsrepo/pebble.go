@@ -0,0 +1,161 @@
+package statsrepo
+
+import (
+	"encoding/binary"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/stats"
+)
+
+// key formats:
+//
+//	stats:       's'(1B) -> height(4B) + 7 counters(8B each)
+//	expirations: 'e'(1B) + height(4B) -> the number of names whose controlling claim expires at the height(8B)
+const (
+	statsPrefix      = 's'
+	expirationPrefix = 'e'
+	valueSize        = 4 + 7*8
+)
+
+type Pebble struct {
+	db *pebble.DB
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{MaxOpenFiles: 2000})
+	repo := &Pebble{db: db}
+
+	return repo, errors.Wrapf(err, "unable to open %s", path)
+}
+
+func expirationKey(height int32) []byte {
+	key := make([]byte, 5)
+	key[0] = expirationPrefix
+	binary.BigEndian.PutUint32(key[1:], uint32(height))
+	return key
+}
+
+func (repo *Pebble) Set(height int32, s stats.Stats, expirations map[int32]int64) error {
+
+	batch := repo.db.NewIndexedBatch()
+	defer batch.Close()
+
+	for h, delta := range expirations {
+		if delta == 0 {
+			continue
+		}
+		key := expirationKey(h)
+		count, err := getCount(batch, key)
+		if err != nil {
+			return err
+		}
+		count += delta
+		if count == 0 {
+			err = batch.Delete(key, pebble.NoSync)
+		} else {
+			var value [8]byte
+			binary.BigEndian.PutUint64(value[:], uint64(count))
+			err = batch.Set(key, value[:], pebble.NoSync)
+		}
+		if err != nil {
+			return errors.Wrap(err, "in set expiration")
+		}
+	}
+
+	value := make([]byte, valueSize)
+	binary.BigEndian.PutUint32(value, uint32(height))
+	for i, c := range counters(&s) {
+		binary.BigEndian.PutUint64(value[4+8*i:], uint64(*c))
+	}
+	if err := batch.Set([]byte{statsPrefix}, value, pebble.NoSync); err != nil {
+		return errors.Wrap(err, "in set")
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+// getCount returns the expiration count stored at key, or 0 when there's none.
+func getCount(r pebble.Reader, key []byte) (int64, error) {
+
+	value, closer, err := r.Get(key)
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
+
+func (repo *Pebble) Get() (stats.Stats, int32, error) {
+
+	var s stats.Stats
+	value, closer, err := repo.db.Get([]byte{statsPrefix})
+	if err == pebble.ErrNotFound {
+		return s, -1, nil
+	}
+	if err != nil {
+		return s, 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	if len(value) != valueSize {
+		return s, 0, errors.Errorf("invalid stats record of length %d", len(value))
+	}
+	for i, c := range counters(&s) {
+		*c = int64(binary.BigEndian.Uint64(value[4+8*i:]))
+	}
+	return s, int32(binary.BigEndian.Uint32(value)), nil
+}
+
+func (repo *Pebble) Expirations(from, to int32) (int64, error) {
+
+	var count int64
+	iter := repo.db.NewIter(&pebble.IterOptions{LowerBound: expirationKey(from), UpperBound: expirationKey(to + 1)})
+	for iter.First(); iter.Valid(); iter.Next() {
+		count += int64(binary.BigEndian.Uint64(iter.Value()))
+	}
+	return count, errors.Wrap(iter.Close(), "in close")
+}
+
+func (repo *Pebble) Clear() error {
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	err := batch.DeleteRange([]byte{expirationPrefix}, []byte{expirationPrefix + 1}, pebble.NoSync)
+	if err != nil {
+		return errors.Wrap(err, "in delete expirations")
+	}
+	if err = batch.Delete([]byte{statsPrefix}, pebble.NoSync); err != nil {
+		return errors.Wrap(err, "in delete stats")
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+// counters returns the fields of the stats in their stored order.
+func counters(s *stats.Stats) []*int64 {
+	return []*int64{&s.Names, &s.ActiveClaims, &s.AcceptedClaims, &s.ActiveSupports, &s.AcceptedSupports,
+		&s.ClaimAmount, &s.SupportAmount}
+}
+
+func (repo *Pebble) Close() error {
+
+	err := repo.db.Flush()
+	if err != nil {
+		// if we fail to close are we going to try again later?
+		return errors.Wrap(err, "on flush")
+	}
+
+	err = repo.db.Close()
+	return errors.Wrap(err, "on close")
+}
+
+func (repo *Pebble) Flush() error {
+	_, err := repo.db.AsyncFlush()
+	return err
+}
@@ -0,0 +1,26 @@
+package takeover
+
+import (
+	"github.com/lbryio/lbcd/claimtrie/node"
+)
+
+// Repo defines APIs for the takeover log to access persistence layer.
+type Repo interface {
+	// Set records the takeovers of each name; names and takeovers are parallel slices.
+	Set(names [][]byte, takeovers []node.Takeover) error
+
+	// Takeovers returns the takeovers of name between the heights, inclusive, in height order.
+	Takeovers(name []byte, fromHeight, toHeight int32) ([]node.Takeover, error)
+
+	// DropAfter removes the takeovers that happened after height.
+	DropAfter(height int32) error
+
+	// SetHeight records the height the log has been built to.
+	SetHeight(height int32) error
+
+	// Height returns the height the log has been built to, or -1 for a new log.
+	Height() (int32, error)
+
+	Close() error
+	Flush() error
+}
@@ -0,0 +1,78 @@
+package takeoverrepo
+
+import (
+	"sort"
+
+	"github.com/lbryio/lbcd/claimtrie/node"
+)
+
+type Memory struct {
+	takeovers map[string][]node.Takeover // in height order
+	height    int32
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		takeovers: map[string][]node.Takeover{},
+		height:    -1,
+	}
+}
+
+func (repo *Memory) Set(names [][]byte, takeovers []node.Takeover) error {
+
+	for i, t := range takeovers {
+		name := string(names[i])
+		list := repo.takeovers[name]
+		j := sort.Search(len(list), func(j int) bool { return list[j].Height >= t.Height })
+		if j < len(list) && list[j].Height == t.Height {
+			list[j] = t
+			continue
+		}
+		list = append(list, node.Takeover{})
+		copy(list[j+1:], list[j:])
+		list[j] = t
+		repo.takeovers[name] = list
+	}
+	return nil
+}
+
+func (repo *Memory) Takeovers(name []byte, fromHeight, toHeight int32) ([]node.Takeover, error) {
+
+	var takeovers []node.Takeover
+	for _, t := range repo.takeovers[string(name)] {
+		if t.Height >= fromHeight && t.Height <= toHeight {
+			takeovers = append(takeovers, t)
+		}
+	}
+	return takeovers, nil
+}
+
+func (repo *Memory) DropAfter(height int32) error {
+
+	for name, list := range repo.takeovers {
+		i := sort.Search(len(list), func(i int) bool { return list[i].Height > height })
+		if i == 0 {
+			delete(repo.takeovers, name)
+		} else {
+			repo.takeovers[name] = list[:i]
+		}
+	}
+	return nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+	repo.height = height
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
+
+func (repo *Memory) Flush() error {
+	return nil
+}
@@ -0,0 +1,172 @@
+package takeoverrepo
+
+import (
+	"encoding/binary"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/node"
+)
+
+// key formats:
+//
+//	takeover: 'n'(1B) + len(name)(2B) + name + height(4B) -> previousID(20B) + ID(20B) + previousAmount(8B) + amount(8B)
+//	height:   'h'(1B) + height(4B) + name -> nil
+//	tip:      't'(1B) -> height(4B)
+const (
+	namePrefix   = 'n'
+	heightPrefix = 'h'
+	tipPrefix    = 't'
+)
+
+type Pebble struct {
+	db *pebble.DB
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(16 << 20), MaxOpenFiles: 2000})
+	repo := &Pebble{db: db}
+
+	return repo, errors.Wrapf(err, "unable to open %s", path)
+}
+
+// nameKey takes the height as an uint32 so that callers can go one past math.MaxInt32.
+func nameKey(name []byte, height uint32) []byte {
+	key := make([]byte, 3+len(name)+4)
+	key[0] = namePrefix
+	binary.BigEndian.PutUint16(key[1:], uint16(len(name)))
+	copy(key[3:], name)
+	binary.BigEndian.PutUint32(key[3+len(name):], height)
+	return key
+}
+
+func heightKey(height int32, name []byte) []byte {
+	key := make([]byte, 5, 5+len(name))
+	key[0] = heightPrefix
+	binary.BigEndian.PutUint32(key[1:], uint32(height))
+	return append(key, name...)
+}
+
+func (repo *Pebble) Set(names [][]byte, takeovers []node.Takeover) error {
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	for i, t := range takeovers {
+		value := make([]byte, 2*change.ClaimIDSize+16)
+		copy(value, t.PreviousClaimID[:])
+		copy(value[change.ClaimIDSize:], t.ClaimID[:])
+		binary.BigEndian.PutUint64(value[2*change.ClaimIDSize:], uint64(t.PreviousAmount))
+		binary.BigEndian.PutUint64(value[2*change.ClaimIDSize+8:], uint64(t.Amount))
+
+		err := batch.Set(nameKey(names[i], uint32(t.Height)), value, pebble.NoSync)
+		if err != nil {
+			return errors.Wrap(err, "in set takeover")
+		}
+		err = batch.Set(heightKey(t.Height, names[i]), nil, pebble.NoSync)
+		if err != nil {
+			return errors.Wrap(err, "in set height")
+		}
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) Takeovers(name []byte, fromHeight, toHeight int32) ([]node.Takeover, error) {
+
+	if fromHeight < 0 {
+		fromHeight = 0
+	}
+	if toHeight < fromHeight {
+		return nil, nil
+	}
+
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: nameKey(name, uint32(fromHeight)),
+		UpperBound: nameKey(name, uint32(toHeight)+1),
+	})
+	defer iter.Close()
+
+	var takeovers []node.Takeover
+	for iter.First(); iter.Valid(); iter.Next() {
+		key, value := iter.Key(), iter.Value()
+		if len(value) != 2*change.ClaimIDSize+16 {
+			return nil, errors.Errorf("invalid takeover record of length %d", len(value))
+		}
+		t := node.Takeover{Height: int32(binary.BigEndian.Uint32(key[len(key)-4:]))}
+		copy(t.PreviousClaimID[:], value)
+		copy(t.ClaimID[:], value[change.ClaimIDSize:])
+		t.PreviousAmount = int64(binary.BigEndian.Uint64(value[2*change.ClaimIDSize:]))
+		t.Amount = int64(binary.BigEndian.Uint64(value[2*change.ClaimIDSize+8:]))
+		takeovers = append(takeovers, t)
+	}
+	return takeovers, errors.Wrap(iter.Error(), "in iterate")
+}
+
+func (repo *Pebble) DropAfter(height int32) error {
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	iter := repo.db.NewIter(&pebble.IterOptions{
+		LowerBound: heightKey(height+1, nil),
+		UpperBound: []byte{heightPrefix + 1},
+	})
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := iter.Key()
+		err := batch.Delete(nameKey(key[5:], binary.BigEndian.Uint32(key[1:])), pebble.NoSync)
+		if err != nil {
+			iter.Close()
+			return errors.Wrap(err, "in delete takeover")
+		}
+		err = batch.Delete(append([]byte(nil), key...), pebble.NoSync)
+		if err != nil {
+			iter.Close()
+			return errors.Wrap(err, "in delete height")
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Wrap(err, "in close")
+	}
+	return errors.Wrap(batch.Commit(pebble.NoSync), "in commit")
+}
+
+func (repo *Pebble) SetHeight(height int32) error {
+
+	var tip [4]byte
+	binary.BigEndian.PutUint32(tip[:], uint32(height))
+	return errors.Wrap(repo.db.Set([]byte{tipPrefix}, tip[:], pebble.NoSync), "in set")
+}
+
+func (repo *Pebble) Height() (int32, error) {
+
+	data, closer, err := repo.db.Get([]byte{tipPrefix})
+	if err == pebble.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "in get")
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(data)), nil
+}
+
+func (repo *Pebble) Close() error {
+
+	err := repo.db.Flush()
+	if err != nil {
+		// if we fail to close are we going to try again later?
+		return errors.Wrap(err, "on flush")
+	}
+
+	err = repo.db.Close()
+	return errors.Wrap(err, "on close")
+}
+
+func (repo *Pebble) Flush() error {
+	_, err := repo.db.AsyncFlush()
+	return err
+}
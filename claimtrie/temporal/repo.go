@@ -4,6 +4,9 @@ package temporal
 type Repo interface {
 	SetNodesAt(names [][]byte, heights []int32) error
 	NodesAt(height int32) ([][]byte, error)
+
+	// DropBefore removes the names of the heights below (excludes) the specified height.
+	DropBefore(height int32) error
 	Close() error
 	Flush() error
 }
@@ -36,6 +36,17 @@ func (repo *Memory) NodesAt(height int32) ([][]byte, error) {
 	return names, nil
 }
 
+func (repo *Memory) DropBefore(height int32) error {
+
+	for h := range repo.cache {
+		if h < height {
+			delete(repo.cache, h)
+		}
+	}
+
+	return nil
+}
+
 func (repo *Memory) Close() error {
 	return nil
 }
@@ -69,6 +69,15 @@ func (repo *Pebble) NodesAt(height int32) ([][]byte, error) {
 	return names, errors.Wrap(iter.Close(), "in close")
 }
 
+func (repo *Pebble) DropBefore(height int32) error {
+
+	// the keys start with the height, so those of the lower heights come first
+	end := bytes.NewBuffer(nil)
+	binary.Write(end, binary.BigEndian, height)
+
+	return errors.Wrap(repo.db.DeleteRange([]byte{}, end.Bytes(), pebble.NoSync), "in delete range")
+}
+
 func (repo *Pebble) Close() error {
 
 	err := repo.db.Flush()
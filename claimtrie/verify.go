@@ -0,0 +1,177 @@
+package claimtrie
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/merkletrie"
+	"github.com/lbryio/lbcd/claimtrie/node"
+	"github.com/lbryio/lbcd/claimtrie/param"
+)
+
+// Divergence is the first height at which the root of the ClaimTrie recomputed from its nodes doesn't match
+// the hashes on record for it.
+type Divergence struct {
+	Height   int32
+	Computed *chainhash.Hash
+	Stored   *chainhash.Hash // the one AppendBlock stored in the block repo
+	Header   *chainhash.Hash // the ClaimTrie field of the block header; nil when unknown
+
+	// Names are those whose hashes changed at the height; as the height below matched, the culprit is among them.
+	// It's nil at the first height verified and at the hash fork, where the whole trie gets computed.
+	Names [][]byte
+}
+
+// diverges returns whether the computed hash differs from the others.
+func (d *Divergence) diverges() bool {
+	return !d.Computed.IsEqual(d.Stored) || (d.Header != nil && !d.Computed.IsEqual(d.Header))
+}
+
+// Verify recomputes the root of the ClaimTrie from its nodes at each height from the from height to the
+// to height, inclusive, and compares it with the hash stored at that height and with the one returned by
+// header, when it isn't nil. It returns the first height at which they differ, or nil when they all match.
+// The ClaimTrie is only locked with locker, when it isn't nil, while a height is verified, so blocks can be
+// appended in between; it ends with an error when the ClaimTrie is reset below the height it's at.
+func (ct *ClaimTrie) Verify(from, to int32, header func(height int32) (*chainhash.Hash, error),
+	locker sync.Locker, interrupt <-chan struct{}) (*Divergence, error) {
+
+	if locker == nil {
+		locker = &sync.Mutex{}
+	}
+
+	trie := merkletrie.NewRamTrie()
+	var previous *chainhash.Hash
+	for height := from; height <= to; height++ {
+		if interruptRequested(interrupt) {
+			return nil, errors.New("interrupted")
+		}
+
+		locker.Lock()
+		d, err := ct.verifyAt(trie, from, to, height, previous, header, interrupt)
+		locker.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if d.diverges() {
+			return d, nil
+		}
+		previous = d.Stored
+	}
+	return nil, nil
+}
+
+// verifyAt updates the trie to height, from the height below it unless it's the from height, and returns
+// its hashes at the height. The stored hash of the height below is checked against the previous one
+// to make sure the ClaimTrie wasn't reset to other blocks since.
+func (ct *ClaimTrie) verifyAt(trie merkletrie.MerkleTrie, from, to, height int32, previous *chainhash.Hash,
+	header func(height int32) (*chainhash.Hash, error), interrupt <-chan struct{}) (*Divergence, error) {
+
+	if height == from && (from < 0 || from > to || to > ct.height) {
+		return nil, errors.Errorf("invalid range from %d to %d with the tip at %d", from, to, ct.height)
+	}
+	if height > ct.height {
+		return nil, errors.Errorf("the ClaimTrie was reset below height %d while verifying it", height)
+	}
+	if pruned := ct.nodeManager.PrunedHeight(); height < pruned {
+		return nil, errors.Wrapf(node.ErrPruned, "unable to verify at height %d below %d", height, pruned)
+	}
+	if previous != nil {
+		stored, err := ct.blockRepo.Get(height - 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "block repo get")
+		}
+		if !stored.IsEqual(previous) {
+			return nil, errors.Errorf("the ClaimTrie was reset to other blocks below height %d while verifying it", height)
+		}
+	}
+
+	var names [][]byte
+	var err error
+	all := height == from || height == param.ActiveParams.AllClaimsInMerkleForkHeight
+	if !all {
+		names, err = ct.temporalRepo.NodesAt(height)
+		if err != nil {
+			return nil, errors.Wrap(err, "temporal repo get")
+		}
+		names = removeDuplicates(names)
+	}
+
+	if err = ct.updateTrieAt(trie, height, names, all, interrupt); err != nil {
+		return nil, err
+	}
+	if interruptRequested(interrupt) {
+		return nil, errors.New("interrupted")
+	}
+
+	// the two share the cached hashes of the vertices, so only one of them can be called
+	d := &Divergence{Height: height}
+	if height >= param.ActiveParams.AllClaimsInMerkleForkHeight {
+		d.Computed = trie.MerkleHashAllClaims()
+	} else {
+		d.Computed = trie.MerkleHash()
+	}
+	d.Stored, err = ct.blockRepo.Get(height)
+	if err != nil {
+		return nil, errors.Wrap(err, "block repo get")
+	}
+	if header != nil {
+		d.Header, err = header(height)
+		if err != nil {
+			return nil, errors.Wrap(err, "header")
+		}
+	}
+	if d.diverges() && !all {
+		d.Names, err = ct.changedNames(height, names)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// updateTrieAt sets the hashes the names, or all of them, have at height in the trie.
+func (ct *ClaimTrie) updateTrieAt(trie merkletrie.MerkleTrie, height int32, names [][]byte, all bool,
+	interrupt <-chan struct{}) error {
+
+	var mu sync.Mutex
+	var first error
+	hash := func(name []byte) (*chainhash.Hash, int32) {
+		n, err := ct.nodeManager.NodeAt(height, name)
+		if err != nil {
+			mu.Lock()
+			if first == nil {
+				first = errors.Wrapf(err, "node %s at %d", name, height)
+			}
+			mu.Unlock()
+			return nil, 0
+		}
+		return node.HashAt(n, height), 0
+	}
+
+	for nhn := range ct.makeNameHashes(names, all, interrupt, hash) {
+		trie.Update(nhn.Name, nhn.Hash, false)
+	}
+	return first
+}
+
+// changedNames returns the names whose hashes at height differ from those at the height below it.
+func (ct *ClaimTrie) changedNames(height int32, names [][]byte) ([][]byte, error) {
+
+	var changed [][]byte
+	for _, name := range names {
+		before, err := ct.nodeManager.NodeAt(height-1, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "node %s at %d", name, height-1)
+		}
+		after, err := ct.nodeManager.NodeAt(height, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "node %s at %d", name, height)
+		}
+		if !node.HashAt(before, height-1).IsEqual(node.HashAt(after, height)) {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
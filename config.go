@@ -26,6 +26,7 @@ import (
 	"github.com/lbryio/lbcd/blockchain"
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/param"
 	"github.com/lbryio/lbcd/connmgr"
 	"github.com/lbryio/lbcd/database"
 	_ "github.com/lbryio/lbcd/database/ffldb"
@@ -58,6 +59,7 @@ const (
 	blockMaxSizeMax              = blockchain.MaxBlockBaseSize - 1000
 	blockMaxWeightMin            = 4000
 	blockMaxWeightMax            = blockchain.MaxBlockWeight - 4000
+	claimTriePruneDepthMin       = 200
 	defaultGenerate              = false
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
@@ -96,7 +98,7 @@ func minUint32(a, b uint32) uint32 {
 type config struct {
 	AddCheckpoints       []string      `long:"addcheckpoint" description:"Add a custom checkpoint.  Format: '<height>:<hash>'"`
 	AddPeers             []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
-	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
+	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available, along with an index of the claims paying each address which makes the getclaimsforaddress RPC available"`
 	AgentBlacklist       []string      `long:"agentblacklist" description:"A comma separated list of user-agent substrings which will cause lbcd to reject any peers whose user-agent contains any of the blacklisted substrings."`
 	AgentWhitelist       []string      `long:"agentwhitelist" description:"A comma separated list of user-agent substrings which will cause lbcd to require all peers' user-agents to contain one of the whitelisted substrings. The blacklist is applied before the blacklist, and an empty whitelist will allow all agents that do not fail the blacklist."`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
@@ -110,12 +112,29 @@ type config struct {
 	ConfigFile           string        `short:"C" long:"configfile" description:"Path to configuration file"`
 	ClaimTrieImpl        string        `long:"clmtimpl" description:"Implementation of ClaimTrie"`
 	ClaimTrieHeight      uint32        `long:"clmtheight" description:"Reset height of ClaimTrie"`
+	ClaimIDIndex         bool          `long:"claimidindex" description:"Maintain an index of claim IDs to the names, latest versions and statuses of their claims which makes the getclaimbyid RPC available"`
+	TakeoverIndex        bool          `long:"takeoverindex" description:"Maintain a log of the takeovers of each name which makes the gettakeoverhistory RPC available"`
+	ClaimChangeIndex     bool          `long:"claimchangeindex" description:"Maintain a log of the changes each block made to the claims and supports which makes the getclaimtriechanges RPC available"`
+	ChannelIndex         bool          `long:"channelindex" description:"Maintain an index of the stream claims validly signed by each channel which makes the getclaimsinchannel RPC available"`
+	RankingIndex         bool          `long:"rankingindex" description:"Maintain the rankings of the names and claims by their effective amounts which makes the gettopclaims RPC available"`
+	ClaimTrieStats       bool          `long:"claimtriestats" description:"Maintain aggregate statistics of the names, claims and supports reported by the getclaimtrieinfo RPC"`
+	ClaimTrieImport      string        `long:"claimtrieimport" description:"Load the ClaimTrie from a file written by claimtrieexport when it has no blocks yet, rather than rebuilding it from the blocks; only the blocks past its height are read to catch it up to the chain, unless the channel index has to be built, which reads every block"`
+	ClaimTrieExport      string        `long:"claimtrieexport" description:"Write the ClaimTrie at the chain tip to the specified file on start up"`
+	ClaimTriePruneDepth  int32         `long:"claimtrieprunedepth" description:"Drop the history of the ClaimTrie below this many blocks from the tip; 0 keeps all of it"`
+	ClaimTrieMemory      bool          `long:"claimtriememory" description:"Keep the ClaimTrie entirely in memory; it is rebuilt from the blocks on each start"`
+	NormalizationHeight  *int32        `long:"claimtrienormalizationheight" description:"Height of the fork that normalizes the names in the ClaimTrie -- regtest and simnet only"`
+	AllClaimsHeight      *int32        `long:"claimtrieallclaimsheight" description:"Height of the fork that puts all the claims of a name in the ClaimTrie hash -- regtest and simnet only"`
+	ClaimExpiration      *int32        `long:"claimtrieexpiration" description:"Blocks until claims expire before the extended expiration fork -- regtest and simnet only"`
+	ExtendedExpiration   *int32        `long:"claimtrieextendedexpiration" description:"Blocks until claims expire after the extended expiration fork -- regtest and simnet only"`
+	ExtendedHeight       *int32        `long:"claimtrieextendedheight" description:"Height of the extended expiration fork -- regtest and simnet only"`
+	ActiveDelayFactor    *int32        `long:"claimtrieactivedelayfactor" description:"Blocks since the last takeover of a name per block of delay in activating a new claim on it -- regtest and simnet only"`
+	MaxActiveDelay       *int32        `long:"claimtriemaxactivedelay" description:"Most blocks a new claim can wait to activate -- regtest and simnet only"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
 	DataDir              string        `short:"b" long:"datadir" description:"Directory to store data"`
 	DbType               string        `long:"dbtype" description:"Database backend to use for the Block Chain"`
 	DebugLevel           string        `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
-	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
+	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index and the claim index that goes with it from the database on start up and then exits."`
 	DropCfIndex          bool          `long:"dropcfindex" description:"Deletes the index used for committed filtering (CF) support from the database on start up and then exits."`
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
@@ -182,6 +201,30 @@ type config struct {
 	whitelists           []*net.IPNet
 }
 
+// applyClaimTrieParams replaces the ClaimTrie parameters of the active network
+// with the ones set in the config.  The ClaimTrie records the parameters it was
+// built with, and refuses to open with other ones until its claim_dbs are
+// removed to rebuild it.
+func (c *config) applyClaimTrieParams(params *param.ClaimTrieParams) {
+	overrides := []struct {
+		value *int32
+		param *int32
+	}{
+		{c.NormalizationHeight, &params.NormalizedNameForkHeight},
+		{c.AllClaimsHeight, &params.AllClaimsInMerkleForkHeight},
+		{c.ClaimExpiration, &params.OriginalClaimExpirationTime},
+		{c.ExtendedExpiration, &params.ExtendedClaimExpirationTime},
+		{c.ExtendedHeight, &params.ExtendedClaimExpirationForkHeight},
+		{c.ActiveDelayFactor, &params.ActiveDelayFactor},
+		{c.MaxActiveDelay, &params.MaxActiveDelay},
+	}
+	for _, o := range overrides {
+		if o.value != nil {
+			*o.param = *o.value
+		}
+	}
+}
+
 // serviceOptions defines the configuration options for the daemon as a service on
 // Windows.
 type serviceOptions struct {
@@ -848,6 +891,57 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The ClaimTrie can't be rolled back below the pruned history.
+	if cfg.ClaimTriePruneDepth != 0 && cfg.ClaimTriePruneDepth < claimTriePruneDepthMin {
+		str := "%s: The claimtrieprunedepth option may not be less " +
+			"than %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, claimTriePruneDepthMin,
+			cfg.ClaimTriePruneDepth)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// There is nothing to import into when the ClaimTrie is kept in memory.
+	if cfg.ClaimTrieMemory && cfg.ClaimTrieImport != "" {
+		str := "%s: the claimtriememory and claimtrieimport options " +
+			"can't be used together"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The ClaimTrie parameters of the public networks are consensus rules.
+	overrides := []*int32{cfg.NormalizationHeight, cfg.AllClaimsHeight,
+		cfg.ClaimExpiration, cfg.ExtendedExpiration, cfg.ExtendedHeight,
+		cfg.ActiveDelayFactor, cfg.MaxActiveDelay}
+	for _, p := range overrides {
+		if p != nil && !(cfg.RegressionTest || cfg.SimNet) {
+			str := "%s: the claimtrie fork heights, expirations and " +
+				"delays may only be set on the regtest and simnet networks"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if p != nil && *p < 0 {
+			str := "%s: the claimtrie fork heights, expirations and " +
+				"delays may not be less than 0 -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, *p)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+	if cfg.ActiveDelayFactor != nil && *cfg.ActiveDelayFactor == 0 {
+		str := "%s: The claimtrieactivedelayfactor option may not be 0"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
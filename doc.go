@@ -26,7 +26,9 @@ Application Options:
   -a, --addpeer=              Add a peer to connect with at startup
       --addrindex             Maintain a full address-based transaction index
                               which makes the searchrawtransactions RPC
-                              available
+                              available, along with an index of the claims
+                              paying each address which makes the
+                              getclaimsforaddress RPC available
       --banduration=          How long to ban misbehaving peers.  Valid time
                               units are {s, m, h}.  Minimum 1 second (default:
                               24h0m0s)
@@ -47,6 +49,57 @@ Application Options:
   -C, --configfile=           Path to configuration file
 	    --clmtimpl=             Implementation of ClaimTrie
 	    --clmtheight=           Reset height of ClaimTrie
+      --claimidindex          Maintain an index of claim IDs to the names,
+                              latest versions and statuses of their claims
+                              which makes the getclaimbyid RPC available
+      --takeoverindex         Maintain a log of the takeovers of each name
+                              which makes the gettakeoverhistory RPC available
+      --claimchangeindex      Maintain a log of the changes each block made to
+                              the claims and supports which makes the
+                              getclaimtriechanges RPC available
+      --channelindex          Maintain an index of the stream claims validly
+                              signed by each channel which makes the
+                              getclaimsinchannel RPC available
+      --rankingindex          Maintain the rankings of the names and claims by
+                              their effective amounts which makes the
+                              gettopclaims RPC available
+      --claimtriestats        Maintain aggregate statistics of the names,
+                              claims and supports reported by the
+                              getclaimtrieinfo RPC
+      --claimtrieimport=      Load the ClaimTrie from a file written by
+                              claimtrieexport when it has no blocks yet, rather
+                              than rebuilding it from the blocks; only the
+                              blocks past its height are read to catch it up to
+                              the chain, unless the channel index has to be
+                              built, which reads every block
+      --claimtrieexport=      Write the ClaimTrie at the chain tip to the
+                              specified file on start up
+      --claimtrieprunedepth=  Drop the history of the ClaimTrie below this many
+                              blocks from the tip; 0 keeps all of it
+      --claimtriememory       Keep the ClaimTrie entirely in memory; it is
+                              rebuilt from the blocks on each start
+      --claimtrienormalizationheight=
+                              Height of the fork that normalizes the names in
+                              the ClaimTrie -- regtest and simnet only
+      --claimtrieallclaimsheight=
+                              Height of the fork that puts all the claims of a
+                              name in the ClaimTrie hash -- regtest and simnet
+                              only
+      --claimtrieexpiration=  Blocks until claims expire before the extended
+                              expiration fork -- regtest and simnet only
+      --claimtrieextendedexpiration=
+                              Blocks until claims expire after the extended
+                              expiration fork -- regtest and simnet only
+      --claimtrieextendedheight=
+                              Height of the extended expiration fork -- regtest
+                              and simnet only
+      --claimtrieactivedelayfactor=
+                              Blocks since the last takeover of a name per block
+                              of delay in activating a new claim on it --
+                              regtest and simnet only
+      --claimtriemaxactivedelay=
+                              Most blocks a new claim can wait to activate --
+                              regtest and simnet only
       --connect=              Connect only to the specified peers at startup
       --cpuprofile=           Write CPU profile to the specified file
   -b, --datadir=              Directory to store data
@@ -59,8 +112,9 @@ Application Options:
                               set the log level for individual subsystems --
                               Use show to list available subsystems (default:
                               info)
-      --dropaddrindex         Deletes the address-based transaction index from
-                              the database on start up and then exits.
+      --dropaddrindex         Deletes the address-based transaction index and
+                              the claim index that goes with it from the
+                              database on start up and then exits.
       --dropcfindex           Deletes the index used for committed filtering
                               (CF) support from the database on start up and
                               then exits.
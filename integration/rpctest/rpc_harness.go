@@ -18,6 +18,7 @@ import (
 
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/param"
 	"github.com/lbryio/lbcd/rpcclient"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
@@ -84,6 +85,10 @@ type Harness struct {
 	// to.
 	ActiveNet *chaincfg.Params
 
+	// ClaimTrieParams is the parameters of the ClaimTrie the node runs
+	// with.
+	ClaimTrieParams param.ClaimTrieParams
+
 	// MaxConnRetries is the maximum number of times we re-try to connect to
 	// the node after starting it.
 	MaxConnRetries int
@@ -105,6 +110,48 @@ type Harness struct {
 	sync.Mutex
 }
 
+// NewWithClaimTrieParams is like New, but the node runs with the specified
+// ClaimTrie fork heights, expirations and activation delays in place of the
+// ones of the network, which lets tests reach the forks and the expirations
+// without mining hundreds of blocks. Only the regression and simulation test
+// networks accept them.
+//
+// NOTE: This function is safe for concurrent access.
+func NewWithClaimTrieParams(activeNet *chaincfg.Params,
+	claimTrieParams *param.ClaimTrieParams,
+	handlers *rpcclient.NotificationHandlers, extraArgs []string,
+	customExePath string) (*Harness, error) {
+
+	if activeNet.Net != wire.TestNet && activeNet.Net != wire.SimNet {
+		return nil, fmt.Errorf("the ClaimTrie parameters may only be " +
+			"set on the regression and simulation test networks")
+	}
+
+	extraArgs = append(extraArgs,
+		fmt.Sprintf("--claimtrienormalizationheight=%d",
+			claimTrieParams.NormalizedNameForkHeight),
+		fmt.Sprintf("--claimtrieallclaimsheight=%d",
+			claimTrieParams.AllClaimsInMerkleForkHeight),
+		fmt.Sprintf("--claimtrieexpiration=%d",
+			claimTrieParams.OriginalClaimExpirationTime),
+		fmt.Sprintf("--claimtrieextendedexpiration=%d",
+			claimTrieParams.ExtendedClaimExpirationTime),
+		fmt.Sprintf("--claimtrieextendedheight=%d",
+			claimTrieParams.ExtendedClaimExpirationForkHeight),
+		fmt.Sprintf("--claimtrieactivedelayfactor=%d",
+			claimTrieParams.ActiveDelayFactor),
+		fmt.Sprintf("--claimtriemaxactivedelay=%d",
+			claimTrieParams.MaxActiveDelay),
+	)
+
+	h, err := New(activeNet, handlers, extraArgs, customExePath)
+	if err != nil {
+		return nil, err
+	}
+	h.ClaimTrieParams = *claimTrieParams
+	return h, nil
+}
+
 // New creates and initializes new instance of the rpc test harness.
 // Optionally, websocket handlers and a specified configuration may be passed.
 // In the case that a nil config is passed, a default configuration will be
@@ -213,6 +260,7 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 		ConnectionRetryTimeout: DefaultConnectionRetryTimeout,
 		testNodeDir:            nodeTestData,
 		ActiveNet:              activeNet,
+		ClaimTrieParams:        param.NetworkParams(activeNet.Net),
 		nodeNum:                nodeNum,
 		wallet:                 wallet,
 	}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/param"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
@@ -126,6 +127,44 @@ func testConnectNode(r *Harness, t *testing.T) {
 	assertConnectedTo(t, harness, r)
 }
 
+func testClaimTrieParams(r *Harness, t *testing.T) {
+	// The ClaimTrie parameters of the main network are consensus rules.
+	params := param.Regtest
+	params.NormalizedNameForkHeight = 5
+	params.AllClaimsInMerkleForkHeight = 10
+	params.OriginalClaimExpirationTime = 20
+	_, err := NewWithClaimTrieParams(&chaincfg.MainNetParams, &params,
+		nil, nil, "")
+	if err == nil {
+		t.Fatal("ClaimTrie parameters accepted on the main network")
+	}
+
+	// Create a fresh test harness with the forks close by.
+	harness, err := NewWithClaimTrieParams(&chaincfg.RegressionNetParams,
+		&params, nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := harness.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete rpctest setup: %v", err)
+	}
+	defer harness.TearDown()
+
+	if harness.ClaimTrieParams != params {
+		t.Fatalf("harness ClaimTrie parameters are %v, should be %v",
+			harness.ClaimTrieParams, params)
+	}
+	if r.ClaimTrieParams != param.Regtest {
+		t.Fatalf("main harness ClaimTrie parameters are %v, should "+
+			"be %v", r.ClaimTrieParams, param.Regtest)
+	}
+
+	// The node mines past the forks.
+	if _, err := harness.Client.Generate(15); err != nil {
+		t.Fatalf("unable to generate blocks past the forks: %v", err)
+	}
+}
+
 func testTearDownAll(t *testing.T) {
 	// Grab a local copy of the currently active harnesses before
 	// attempting to tear them all down.
@@ -550,6 +589,7 @@ func testMemWalletLockedOutputs(r *Harness, t *testing.T) {
 var harnessTestCases = []HarnessTestCase{
 	testSendOutputs,
 	testConnectNode,
+	testClaimTrieParams,
 	testActiveHarnesses,
 	testJoinBlocks,
 	testJoinMempools, // Depends on results of testJoinBlocks
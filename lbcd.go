@@ -149,6 +149,7 @@ func btcdMain(serverChan chan<- *server) error {
 	}
 
 	param.SetNetwork(activeNetParams.Params.Net) // prep the claimtrie params
+	cfg.applyClaimTrieParams(&param.ActiveParams)
 
 	go logMemoryUsage()
 
@@ -5,6 +5,7 @@
 package mempool
 
 import (
+	"bytes"
 	"container/list"
 	"fmt"
 	"math"
@@ -17,6 +18,8 @@ import (
 	"github.com/lbryio/lbcd/btcjson"
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/normalization"
 	"github.com/lbryio/lbcd/mining"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
@@ -89,6 +92,13 @@ type Config struct {
 	// into the mempool or not.
 	IsDeploymentActive func(deploymentID uint32) (bool, error)
 
+	// ClaimExists defines the function to use to check whether a claim
+	// with the given normalized name and claim ID exists in the ClaimTrie
+	// at the current best chain tip.  Supports for claims which exist
+	// neither there nor in the pool are rejected.  This can be nil, in
+	// which case only claims in the pool are considered.
+	ClaimExists func(name []byte, id change.ClaimID) (bool, error)
+
 	// SigCache defines a signature cache to use.
 	SigCache *txscript.SigCache
 
@@ -185,6 +195,10 @@ type TxPool struct {
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
 
+	// claims counts the names of the claims created or updated by the
+	// transactions in the pool, by claim ID.
+	claims map[change.ClaimID]map[string]int
+
 	// nextExpireScan is the time after which the orphan pool will be
 	// scanned in order to evict orphans.  This is NOT a hard deadline as
 	// the scan will only run when an orphan is added to the pool as opposed
@@ -490,6 +504,7 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 		for _, txIn := range txDesc.Tx.MsgTx().TxIn {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
+		mp.updateClaims(tx, -1)
 		delete(mp.pool, *txHash)
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	}
@@ -551,6 +566,7 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	mp.updateClaims(tx, 1)
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 
 	// Add unconfirmed address index entries associated with the transaction
@@ -807,6 +823,67 @@ func (mp *TxPool) fetchInputUtxos(tx *btcutil.Tx) (*blockchain.UtxoViewpoint, er
 	return utxoView, nil
 }
 
+// claimExists returns whether a claim with the given normalized name and claim
+// ID exists in the ClaimTrie or is created or updated by a transaction in the
+// pool.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) claimExists(name []byte, id change.ClaimID) (bool, error) {
+	if mp.cfg.ClaimExists != nil {
+		exists, err := mp.cfg.ClaimExists(name, id)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+
+	// Consensus normalizes the names of a block at the height of its parent.
+	height := mp.cfg.BestHeight()
+	for poolName := range mp.claims[id] {
+		if bytes.Equal(normalization.NormalizeIfNecessary([]byte(poolName), height), name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// updateClaims adds delta to the counts of the names of the claims created or
+// updated by the passed transaction, which is being added to or removed from
+// the pool.  The names are kept as they are in the scripts since they are
+// normalized at the height of the block they are checked against.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) updateClaims(tx *btcutil.Tx, delta int) {
+	for i, txOut := range tx.MsgTx().TxOut {
+		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil {
+			continue
+		}
+		var id change.ClaimID
+		switch cs.Opcode() {
+		case txscript.OP_CLAIMNAME:
+			id = change.NewClaimID(*wire.NewOutPoint(tx.Hash(), uint32(i)))
+		case txscript.OP_UPDATECLAIM:
+			copy(id[:], cs.ClaimID())
+		default:
+			continue
+		}
+
+		names := mp.claims[id]
+		if names == nil {
+			names = make(map[string]int)
+			mp.claims[id] = names
+		}
+		name := string(cs.Name())
+		names[name] += delta
+		if names[name] <= 0 {
+			delete(names, name)
+		}
+		if len(names) == 0 {
+			delete(mp.claims, id)
+		}
+	}
+}
+
 // FetchTransaction returns the requested transaction from the transaction pool.
 // This only fetches from the main transaction pool and does not include
 // orphans.
@@ -1093,6 +1170,13 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, err
 	}
 
+	// Don't allow transactions with claim updates or supports that would
+	// be ignored once mined, so their fees aren't burned for nothing.
+	err = checkClaimScripts(tx, utxoView, bestHeight, mp.claimExists)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Don't allow transactions with non-standard inputs if the network
 	// parameters forbid their acceptance.
 	if !mp.cfg.Policy.AcceptNonStd {
@@ -1563,5 +1647,6 @@ func New(cfg *Config) *TxPool {
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*btcutil.Tx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*btcutil.Tx),
+		claims:         make(map[change.ClaimID]map[string]int),
 	}
 }
@@ -16,6 +16,8 @@ import (
 	"github.com/lbryio/lbcd/btcec"
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/param"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
@@ -560,7 +562,7 @@ func TestOrphanReject(t *testing.T) {
 
 		// Ensure no transactions were reported as accepted.
 		if len(acceptedTxns) != 0 {
-			t.Fatal("ProcessTransaction: reported %d accepted "+
+			t.Fatalf("ProcessTransaction: reported %d accepted "+
 				"transactions from failed orphan attempt",
 				len(acceptedTxns))
 		}
@@ -1851,3 +1853,78 @@ func TestRBF(t *testing.T) {
 		}
 	}
 }
+
+// TestClaimExists ensures claims created or updated by transactions in the
+// pool are found until the transactions are removed.
+func TestClaimExists(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	mp := harness.txPool
+
+	claimScript, err := txscript.ClaimNameScript("Test", "value")
+	if err != nil {
+		t.Fatalf("unable to create claim script: %v", err)
+	}
+	claimTx := wire.NewMsgTx(wire.TxVersion)
+	claimTx.AddTxIn(wire.NewTxIn(&outputs[0].outPoint, nil, nil))
+	claimTx.AddTxOut(wire.NewTxOut(1000, claimScript))
+	claim := btcutil.NewTx(claimTx)
+	id := change.NewClaimID(wire.OutPoint{Hash: *claim.Hash(), Index: 0})
+
+	updateScript, err := txscript.UpdateClaimScript("Test", id[:], "new")
+	if err != nil {
+		t.Fatalf("unable to create update script: %v", err)
+	}
+	updateTx := wire.NewMsgTx(wire.TxVersion)
+	updateTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: *claim.Hash()}, nil, nil))
+	updateTx.AddTxOut(wire.NewTxOut(1000, updateScript))
+	update := btcutil.NewTx(updateTx)
+
+	checkExists := func(desc string, want bool) {
+		t.Helper()
+		exists, err := mp.claimExists([]byte("Test"), id)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		if exists != want {
+			t.Fatalf("%s: claim exists is %v, want %v", desc,
+				exists, want)
+		}
+	}
+
+	checkExists("empty pool", false)
+	mp.addTransaction(harness.chain.utxos, claim, 0, 0)
+	mp.addTransaction(harness.chain.utxos, update, 0, 0)
+	checkExists("claim and update in pool", true)
+
+	// The names of the pool are normalized at the tip height, as consensus
+	// does for the next block, so they only match in their normalized form
+	// once the tip is at the normalization fork.
+	height := harness.chain.BestHeight()
+	fork := param.ActiveParams.NormalizedNameForkHeight
+	for _, tip := range []int32{fork - 1, fork} {
+		harness.chain.SetHeight(tip)
+		exists, err := mp.claimExists([]byte("test"), id)
+		if err != nil {
+			t.Fatalf("tip %d: unexpected error: %v", tip, err)
+		}
+		if exists != (tip >= fork) {
+			t.Fatalf("tip %d: normalized claim exists is %v", tip,
+				exists)
+		}
+	}
+	harness.chain.SetHeight(height)
+
+	mp.removeTransaction(claim, false)
+	checkExists("update in pool", true)
+	mp.removeTransaction(update, false)
+	checkExists("both removed", false)
+	if len(mp.claims) != 0 {
+		t.Fatalf("claims left after removing all transactions: %v",
+			mp.claims)
+	}
+}
@@ -5,11 +5,14 @@
 package mempool
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"time"
 
 	"github.com/lbryio/lbcd/blockchain"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/normalization"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
@@ -375,6 +378,107 @@ func checkTransactionStandard(tx *btcutil.Tx, height int32,
 	return nil
 }
 
+// checkClaimScripts performs checks on the claim operations of a transaction
+// to ensure that they will have an effect once it is mined.  Updates must
+// spend a claim with the same name and claim ID in the same transaction, and
+// supports must point at claims that exist.  The claimExists function reports
+// whether a claim with the given normalized name and claim ID exists outside
+// of the transaction.  The names are normalized at the passed height, which is
+// the height of the tip as consensus normalizes the names of a block at the
+// height of its parent.
+//
+// It is safe to elide existence checks on the referenced inputs since they
+// have already been checked prior to calling this function.
+func checkClaimScripts(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint,
+	height int32, claimExists func(name []byte, id change.ClaimID) (bool, error)) error {
+
+	// Collect the claims spent by the transaction, which are the only ones
+	// it can update.
+	spent := make(map[change.ClaimID][]byte)
+	for _, txIn := range tx.MsgTx().TxIn {
+		op := txIn.PreviousOutPoint
+		entry := utxoView.LookupEntry(op)
+		cs, err := txscript.DecodeClaimScript(entry.PkScript())
+		if err != nil {
+			continue
+		}
+		name := normalization.NormalizeIfNecessary(cs.Name(), height)
+		switch cs.Opcode() {
+		case txscript.OP_CLAIMNAME:
+			spent[change.NewClaimID(op)] = name
+		case txscript.OP_UPDATECLAIM:
+			var id change.ClaimID
+			copy(id[:], cs.ClaimID())
+			spent[id] = name
+		}
+	}
+
+	// Claims created or updated by the transaction may be supported by it
+	// as well, whichever output comes first.
+	updated := make(map[change.ClaimID][]byte)
+	for i, txOut := range tx.MsgTx().TxOut {
+		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil {
+			continue
+		}
+		name := normalization.NormalizeIfNecessary(cs.Name(), height)
+
+		switch cs.Opcode() {
+		case txscript.OP_CLAIMNAME:
+			op := wire.OutPoint{Hash: *tx.Hash(), Index: uint32(i)}
+			updated[change.NewClaimID(op)] = name
+
+		case txscript.OP_UPDATECLAIM:
+			var id change.ClaimID
+			copy(id[:], cs.ClaimID())
+			spentName, ok := spent[id]
+			if !ok {
+				str := fmt.Sprintf("transaction output #%d updates "+
+					"claim %s which is not spent by the "+
+					"transaction", i, id)
+				return txRuleError(wire.RejectInvalid, str)
+			}
+			if !bytes.Equal(spentName, name) {
+				str := fmt.Sprintf("transaction output #%d updates "+
+					"claim %s with name %q which does not match "+
+					"the name of the spent claim %q", i, id,
+					name, spentName)
+				return txRuleError(wire.RejectInvalid, str)
+			}
+			// A claim can only be updated once.
+			delete(spent, id)
+			updated[id] = name
+		}
+	}
+
+	for i, txOut := range tx.MsgTx().TxOut {
+		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil || cs.Opcode() != txscript.OP_SUPPORTCLAIM {
+			continue
+		}
+
+		var id change.ClaimID
+		copy(id[:], cs.ClaimID())
+		name := normalization.NormalizeIfNecessary(cs.Name(), height)
+		if updatedName, ok := updated[id]; ok &&
+			bytes.Equal(updatedName, name) {
+			continue
+		}
+		exists, err := claimExists(name, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			str := fmt.Sprintf("transaction output #%d supports "+
+				"claim %s which does not exist for name %q",
+				i, id, name)
+			return txRuleError(wire.RejectInvalid, str)
+		}
+	}
+
+	return nil
+}
+
 // GetTxVirtualSize computes the virtual size of a given transaction. A
 // transaction's virtual size is based off its weight, creating a discount for
 // any witness data it contains, proportional to the current
@@ -9,9 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lbryio/lbcd/blockchain"
 	"github.com/lbryio/lbcd/btcec"
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/param"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
 	btcutil "github.com/lbryio/lbcutil"
@@ -510,3 +513,138 @@ func TestCheckTransactionStandard(t *testing.T) {
 		}
 	}
 }
+
+// TestCheckClaimScripts tests the checkClaimScripts API.
+func TestCheckClaimScripts(t *testing.T) {
+	mustScript := func(script []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("unable to create claim script: %v", err)
+		}
+		return script
+	}
+
+	// Create a confirmed transaction with a claim and a regular output to
+	// be spent by the transactions under test.
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxOut(wire.NewTxOut(1000, mustScript(txscript.ClaimNameScript("Test", "value"))))
+	prevTx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	prev := btcutil.NewTx(prevTx)
+	claimOut := wire.OutPoint{Hash: *prev.Hash(), Index: 0}
+	plainOut := wire.OutPoint{Hash: *prev.Hash(), Index: 1}
+	claimID := change.NewClaimID(claimOut)
+	unknownID := change.NewClaimID(plainOut)
+
+	utxoView := blockchain.NewUtxoViewpoint()
+	utxoView.AddTxOuts(prev, 100)
+
+	// Check at the normalization fork height so names are compared in
+	// their normalized form.
+	height := param.ActiveParams.NormalizedNameForkHeight
+	claimExists := func(name []byte, id change.ClaimID) (bool, error) {
+		return id == claimID && bytes.Equal(name, []byte("test")), nil
+	}
+
+	tests := []struct {
+		name    string
+		spend   wire.OutPoint
+		scripts [][]byte
+		valid   bool
+	}{
+		{
+			name:  "update of spent claim",
+			spend: claimOut,
+			scripts: [][]byte{
+				mustScript(txscript.UpdateClaimScript("Test", claimID[:], "new")),
+			},
+			valid: true,
+		},
+		{
+			name:  "update with normalized name",
+			spend: claimOut,
+			scripts: [][]byte{
+				mustScript(txscript.UpdateClaimScript("TEST", claimID[:], "new")),
+			},
+			valid: true,
+		},
+		{
+			name:  "update without spending the claim",
+			spend: plainOut,
+			scripts: [][]byte{
+				mustScript(txscript.UpdateClaimScript("Test", claimID[:], "new")),
+			},
+			valid: false,
+		},
+		{
+			name:  "update with another name",
+			spend: claimOut,
+			scripts: [][]byte{
+				mustScript(txscript.UpdateClaimScript("other", claimID[:], "new")),
+			},
+			valid: false,
+		},
+		{
+			name:  "update of the same claim twice",
+			spend: claimOut,
+			scripts: [][]byte{
+				mustScript(txscript.UpdateClaimScript("Test", claimID[:], "new")),
+				mustScript(txscript.UpdateClaimScript("Test", claimID[:], "newer")),
+			},
+			valid: false,
+		},
+		{
+			name:  "support of existing claim",
+			spend: plainOut,
+			scripts: [][]byte{
+				mustScript(txscript.SupportClaimScript("test", claimID[:], nil)),
+			},
+			valid: true,
+		},
+		{
+			name:  "support of unknown claim",
+			spend: plainOut,
+			scripts: [][]byte{
+				mustScript(txscript.SupportClaimScript("test", unknownID[:], nil)),
+			},
+			valid: false,
+		},
+		{
+			name:  "support of existing claim with another name",
+			spend: plainOut,
+			scripts: [][]byte{
+				mustScript(txscript.SupportClaimScript("other", claimID[:], nil)),
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&test.spend, nil, nil))
+		for _, script := range test.scripts {
+			tx.AddTxOut(wire.NewTxOut(100, script))
+		}
+
+		err := checkClaimScripts(btcutil.NewTx(tx), utxoView, height, claimExists)
+		if err == nil && !test.valid {
+			t.Errorf("checkClaimScripts (%s): valid when it should "+
+				"not be", test.name)
+			continue
+		}
+		if err != nil && test.valid {
+			t.Errorf("checkClaimScripts (%s): invalid when it should "+
+				"not be: %v", test.name, err)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+
+		// Ensure the reject code is the expected one.
+		code, found := extractRejectCode(err)
+		if !found || code != wire.RejectInvalid {
+			t.Errorf("checkClaimScripts (%s): unexpected reject "+
+				"code - got %v, want %v", test.name, code,
+				wire.RejectInvalid)
+		}
+	}
+}
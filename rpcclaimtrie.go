@@ -3,16 +3,24 @@ package main
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/lbryio/lbcd/blockchain/indexers"
 	"github.com/lbryio/lbcd/btcjson"
+	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/metadata"
 	"github.com/lbryio/lbcd/claimtrie/node"
 	"github.com/lbryio/lbcd/claimtrie/normalization"
+	"github.com/lbryio/lbcd/claimtrie/proof"
 	"github.com/lbryio/lbcd/database"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
+	btcutil "github.com/lbryio/lbcutil"
 )
 
 var claimtrieHandlers = map[string]commandHandler{
@@ -22,6 +30,22 @@ var claimtrieHandlers = map[string]commandHandler{
 	"getclaimsfornamebybid": handleGetClaimsForNameByBid,
 	"getclaimsfornamebyseq": handleGetClaimsForNameBySeq,
 	"normalize":             handleGetNormalized,
+	"getnameproof":          handleGetNameProof,
+	"getclaimproof":         handleGetClaimProof,
+	"getclaimbyid":          handleGetClaimByID,
+	"getclaimhistory":       handleGetClaimHistory,
+	"gettakeoverhistory":    handleGetTakeoverHistory,
+	"listnames":             handleListNames,
+	"getclaimtrieinfo":      handleGetClaimTrieInfo,
+	"verifyclaimtrie":       handleVerifyClaimTrie,
+	"getexpiringclaims":     handleGetExpiringClaims,
+	"simulatebid":           handleSimulateBid,
+	"getclaimtriechanges":   handleGetClaimTrieChanges,
+	"resolve":               handleResolve,
+	"getclaimsforaddress":   handleGetClaimsForAddress,
+	"decodeclaimvalue":      handleDecodeClaimValue,
+	"getclaimsinchannel":    handleGetClaimsInChannel,
+	"gettopclaims":          handleGetTopClaims,
 }
 
 func handleGetChangesInBlock(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
@@ -47,6 +71,44 @@ func handleGetChangesInBlock(s *rpcServer, cmd interface{}, _ <-chan struct{}) (
 	}, nil
 }
 
+func handleGetClaimTrieChanges(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetClaimTrieChangesCmd)
+	hash, height, err := parseHashOrHeight(s, c.HashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.cfg.Chain.GetClaimTrieChanges(height)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	results := make([]btcjson.ClaimTrieChangeResult, 0, len(changes))
+	for _, chg := range changes {
+		results = append(results, btcjson.ClaimTrieChangeResult{
+			Type:          changeTypeNames[chg.Type],
+			Name:          string(chg.Name),
+			ClaimID:       chg.ClaimID.String(),
+			TXID:          chg.OutPoint.Hash.String(),
+			N:             chg.OutPoint.Index,
+			Amount:        chg.Amount,
+			Height:        chg.Height,
+			ActiveHeight:  chg.ActiveHeight,
+			VisibleHeight: chg.VisibleHeight,
+		})
+	}
+
+	return btcjson.GetClaimTrieChangesResult{
+		Hash:    hash,
+		Height:  height,
+		Changes: results,
+	}, nil
+}
+
 func parseHashOrHeight(s *rpcServer, hashOrHeight *string) (string, int32, error) {
 	if hashOrHeight == nil || len(*hashOrHeight) == 0 {
 
@@ -99,17 +161,14 @@ func handleGetClaimsForName(s *rpcServer, cmd interface{}, _ <-chan struct{}) (i
 		return nil, err
 	}
 
-	name, n, err := s.cfg.Chain.GetClaimsForName(height, c.Name)
+	name, n, err := lookupClaims(s, height, c.Name, c.IncludeMempool)
 	if err != nil {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCMisc,
-			Message: "Message: " + err.Error(),
-		}
+		return nil, err
 	}
 
 	var results []btcjson.ClaimResult
 	for i := range n.Claims {
-		cr, err := toClaimResult(s, int32(i), n, c.IncludeValues)
+		cr, err := toClaimResult(s, int32(i), n, height, c.IncludeValues)
 		if err != nil {
 			return nil, err
 		}
@@ -132,19 +191,16 @@ func handleGetClaimsForNameByID(s *rpcServer, cmd interface{}, _ <-chan struct{}
 		return nil, err
 	}
 
-	name, n, err := s.cfg.Chain.GetClaimsForName(height, c.Name)
+	name, n, err := lookupClaims(s, height, c.Name, c.IncludeMempool)
 	if err != nil {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCMisc,
-			Message: "Message: " + err.Error(),
-		}
+		return nil, err
 	}
 
 	var results []btcjson.ClaimResult
 	for i := 0; i < len(n.Claims); i++ {
 		for _, id := range c.PartialClaimIDs {
 			if strings.HasPrefix(n.Claims[i].ClaimID.String(), id) {
-				cr, err := toClaimResult(s, int32(i), n, c.IncludeValues)
+				cr, err := toClaimResult(s, int32(i), n, height, c.IncludeValues)
 				if err != nil {
 					return nil, err
 				}
@@ -170,18 +226,15 @@ func handleGetClaimsForNameByBid(s *rpcServer, cmd interface{}, _ <-chan struct{
 		return nil, err
 	}
 
-	name, n, err := s.cfg.Chain.GetClaimsForName(height, c.Name)
+	name, n, err := lookupClaims(s, height, c.Name, c.IncludeMempool)
 	if err != nil {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCMisc,
-			Message: "Message: " + err.Error(),
-		}
+		return nil, err
 	}
 
 	var results []btcjson.ClaimResult
 	for _, b := range c.Bids { // claims are already sorted in bid order
 		if b >= 0 && int(b) < len(n.Claims) {
-			cr, err := toClaimResult(s, b, n, c.IncludeValues)
+			cr, err := toClaimResult(s, b, n, height, c.IncludeValues)
 			if err != nil {
 				return nil, err
 			}
@@ -205,12 +258,9 @@ func handleGetClaimsForNameBySeq(s *rpcServer, cmd interface{}, _ <-chan struct{
 		return nil, err
 	}
 
-	name, n, err := s.cfg.Chain.GetClaimsForName(height, c.Name)
+	name, n, err := lookupClaims(s, height, c.Name, c.IncludeMempool)
 	if err != nil {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCMisc,
-			Message: "Message: " + err.Error(),
-		}
+		return nil, err
 	}
 
 	sm := map[int32]bool{}
@@ -221,7 +271,7 @@ func handleGetClaimsForNameBySeq(s *rpcServer, cmd interface{}, _ <-chan struct{
 	var results []btcjson.ClaimResult
 	for i := 0; i < len(n.Claims); i++ {
 		if sm[n.Claims[i].Sequence] {
-			cr, err := toClaimResult(s, int32(i), n, c.IncludeValues)
+			cr, err := toClaimResult(s, int32(i), n, height, c.IncludeValues)
 			if err != nil {
 				return nil, err
 			}
@@ -237,107 +287,1195 @@ func handleGetClaimsForNameBySeq(s *rpcServer, cmd interface{}, _ <-chan struct{
 	}, nil
 }
 
-func toClaimResult(s *rpcServer, i int32, node *node.Node, includeValues *bool) (btcjson.ClaimResult, error) {
-	claim := node.Claims[i]
-	address, value, err := lookupValue(s, claim.OutPoint, includeValues)
-	supports, err := toSupportResults(s, i, node, includeValues)
-	return btcjson.ClaimResult{
-		ClaimID:         claim.ClaimID.String(),
-		Height:          claim.AcceptedAt,
-		ValidAtHeight:   claim.ActiveAt,
-		TXID:            claim.OutPoint.Hash.String(),
-		N:               claim.OutPoint.Index,
-		Bid:             i, // assuming sorted by bid
-		EffectiveAmount: claim.Amount + node.SupportSums[claim.ClaimID.Key()],
-		Sequence:        claim.Sequence,
-		Supports:        supports,
-		Address:         address,
-		Value:           value,
-	}, err
+func handleGetClaimByID(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetClaimByIDCmd)
+	hash, height, err := parseHashOrHeight(s, c.HashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.ClaimID) != change.ClaimIDSize*2 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Claim ID must be 40 hex characters: " + c.ClaimID,
+		}
+	}
+	id, err := change.NewIDFromString(c.ClaimID)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to parse the claim ID " + c.ClaimID + ": " + err.Error(),
+		}
+	}
+
+	name, n, i, err := s.cfg.Chain.GetClaimByID(height, id)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	cr, err := toClaimResult(s, int32(i), n, height, c.IncludeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	return btcjson.GetClaimByIDResult{
+		Hash:           hash,
+		Height:         height,
+		NormalizedName: name,
+		Status:         statusNames[n.Claims[i].Status],
+		Claim:          cr,
+	}, nil
 }
 
-func toSupportResults(s *rpcServer, i int32, n *node.Node, includeValues *bool) ([]btcjson.SupportResult, error) {
-	var results []btcjson.SupportResult
-	c := n.Claims[i]
-	for _, sup := range n.Supports {
-		if sup.Status == node.Activated && c.ClaimID == sup.ClaimID {
-			address, value, err := lookupValue(s, sup.OutPoint, includeValues)
-			if err != nil {
-				return results, err
-			}
-			results = append(results, btcjson.SupportResult{
-				TXID:          sup.OutPoint.Hash.String(),
-				N:             sup.OutPoint.Index,
-				Height:        sup.AcceptedAt,
-				ValidAtHeight: sup.ActiveAt,
-				Amount:        sup.Amount,
-				Value:         value,
-				Address:       address,
-			})
+// claimURL is a parsed LBRY URL: a name, and at most one of a (partial) claim ID, a sequence or a bid position.
+type claimURL struct {
+	name     string
+	claimID  string // hex prefix of the claim ID when it's set
+	sequence int32  // 1-based sequence of the claim on the name when it's set; getclaimsfornamebyseq counts from 0
+	bid      int32  // 1-based position of the claim in bid order when it's set; getclaimsfornamebybid counts from 0
+}
+
+// parseClaimURL parses URLs such as lbry://name, name#claimidprefix, name:sequence and name$bid.
+// As in every LBRY client, name:1 is the first claim made on the name and name$1 is the winning bid.
+func parseClaimURL(url string) (claimURL, error) {
+
+	var u claimURL
+	url = strings.TrimPrefix(url, "lbry://")
+
+	i := strings.IndexAny(url, "#:$")
+	if i < 0 {
+		u.name = url
+	} else {
+		u.name = url[:i]
+	}
+	if u.name == "" {
+		return u, errors.New("the URL has no name")
+	}
+	if strings.Contains(u.name, "/") {
+		return u, errors.New("channel paths are not supported")
+	}
+	if i < 0 {
+		return u, nil
+	}
+
+	modifier, value := url[i], url[i+1:]
+	if strings.ContainsAny(value, "#:$/") {
+		return u, errors.New("the URL may only have one claim ID, sequence or bid")
+	}
+	switch modifier {
+	case '#':
+		if len(value) == 0 || len(value) > change.ClaimIDSize*2 {
+			return u, fmt.Errorf("the claim ID must be 1 to %d hex characters: %s", change.ClaimIDSize*2, value)
+		}
+		if strings.Trim(strings.ToLower(value), "0123456789abcdef") != "" {
+			return u, fmt.Errorf("the claim ID must be hex: %s", value)
+		}
+		u.claimID = strings.ToLower(value)
+	case ':', '$':
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || n < 1 {
+			return u, fmt.Errorf("the sequence or bid must be a number of 1 or more: %s", value)
+		}
+		if modifier == ':' {
+			u.sequence = int32(n)
+		} else {
+			u.bid = int32(n)
 		}
 	}
-	return results, nil
+	return u, nil
 }
 
-func lookupValue(s *rpcServer, outpoint wire.OutPoint, includeValues *bool) (string, string, error) {
-	if includeValues == nil || !*includeValues {
-		return "", "", nil
+func handleResolve(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.ResolveCmd)
+	u, err := parseClaimURL(c.URL)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to parse the URL " + c.URL + ": " + err.Error(),
+		}
 	}
-	// TODO: maybe use addrIndex if the txIndex is not available
 
-	if s.cfg.TxIndex == nil {
-		return "", "", &btcjson.RPCError{
-			Code: btcjson.ErrRPCNoTxInfo,
-			Message: "The transaction index must be " +
-				"enabled to query the blockchain " +
-				"(specify --txindex)",
+	hash, height, err := parseHashOrHeight(s, c.HashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	name, n, err := lookupClaims(s, height, u.name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []int
+	for i, claim := range n.Claims { // claims are already sorted in bid order
+		switch {
+		case u.claimID != "":
+			if strings.HasPrefix(claim.ClaimID.String(), u.claimID) {
+				matches = append(matches, i)
+			}
+		case u.sequence > 0:
+			if claim.Sequence+1 == u.sequence {
+				matches = append(matches, i)
+			}
+		case u.bid > 0:
+			if i+1 == int(u.bid) {
+				matches = append(matches, i)
+			}
+		default:
+			if claim == n.BestClaim && n.HasActiveBestClaim() {
+				matches = append(matches, i)
+			}
 		}
 	}
 
-	txHash := &outpoint.Hash
-	blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+	if len(matches) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "No claim on " + name + " matches " + c.URL,
+		}
+	}
+	if len(matches) > 1 {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "The URL " + c.URL + " is ambiguous; " + strconv.Itoa(len(matches)) +
+				" claims on " + name + " match it",
+		}
+	}
+
+	cr, err := toClaimResult(s, int32(matches[0]), n, height, c.IncludeValues)
 	if err != nil {
-		context := "Failed to retrieve transaction location"
-		return "", "", internalRPCError(err.Error(), context)
+		return nil, err
 	}
-	if blockRegion == nil {
-		return "", "", rpcNoTxInfoError(txHash)
+
+	return btcjson.ResolveResult{
+		Hash:           hash,
+		Height:         height,
+		NormalizedName: name,
+		Claim:          cr,
+	}, nil
+}
+
+func handleGetClaimsForAddress(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	claimAddrIndex := s.cfg.ClaimAddrIndex
+	if claimAddrIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Address index must be enabled (--addrindex)",
+		}
 	}
 
-	// Load the raw transaction bytes from the database.
-	var txBytes []byte
+	c := cmd.(*btcjson.GetClaimsForAddressCmd)
+	params := s.cfg.ChainParams
+	addr, err := btcutil.DecodeAddress(c.Address, params)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key: " + err.Error(),
+		}
+	}
+	if *c.Skip < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The skip can't be negative",
+		}
+	}
+	if *c.Count < 0 || *c.Count > maxClaimsForAddressCount {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Count must be between 0 and " + strconv.Itoa(maxClaimsForAddressCount),
+		}
+	}
+
+	var regions []database.BlockRegion
+	var serializedTxns [][]byte
 	err = s.cfg.DB.View(func(dbTx database.Tx) error {
 		var err error
-		txBytes, err = dbTx.FetchBlockRegion(blockRegion)
+		regions, _, _, err = claimAddrIndex.TxRegionsForAddress(dbTx, addr,
+			indexers.ClaimTagAny, uint32(*c.Skip), uint32(*c.Count), *c.Reverse)
+		if err != nil {
+			return err
+		}
+		serializedTxns, err = dbTx.FetchBlockRegions(regions)
 		return err
 	})
 	if err != nil {
-		return "", "", rpcNoTxInfoError(txHash)
+		return nil, internalRPCError(err.Error(), "Failed to load address index entries")
 	}
 
-	// Deserialize the transaction
-	var msgTx wire.MsgTx
-	err = msgTx.Deserialize(bytes.NewReader(txBytes))
+	results := make([]btcjson.AddressClaimResult, 0, len(serializedTxns))
+	for i, serializedTx := range serializedTxns {
+		var mtx wire.MsgTx
+		if err := mtx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+			return nil, internalRPCError(err.Error(), "Failed to deserialize transaction")
+		}
+		height, err := s.cfg.Chain.BlockHeightByHash(regions[i].Hash)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "Failed to obtain block height")
+		}
+
+		// the index tags the whole transaction, so pick out the claim outputs paying the address
+		for n, txOut := range mtx.TxOut {
+			cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+			if err != nil || !scriptPaysAddress(txOut.PkScript, addr, params) {
+				continue
+			}
+
+			out := wire.OutPoint{Hash: mtx.TxHash(), Index: uint32(n)}
+			var typ change.ChangeType
+			var id change.ClaimID
+			switch cs.Opcode() {
+			case txscript.OP_CLAIMNAME:
+				typ = change.AddClaim
+				id = change.NewClaimID(out)
+			case txscript.OP_UPDATECLAIM:
+				typ = change.UpdateClaim
+				copy(id[:], cs.ClaimID())
+			case txscript.OP_SUPPORTCLAIM:
+				typ = change.AddSupport
+				copy(id[:], cs.ClaimID())
+			}
+
+			entry, err := s.cfg.Chain.FetchUtxoEntry(out)
+			if err != nil {
+				return nil, internalRPCError(err.Error(), "Failed to fetch the output")
+			}
+
+			results = append(results, btcjson.AddressClaimResult{
+				Type:      changeTypeNames[typ],
+				Name:      string(cs.Name()),
+				ClaimID:   id.String(),
+				TXID:      out.Hash.String(),
+				N:         out.Index,
+				Amount:    txOut.Value,
+				BlockHash: regions[i].Hash.String(),
+				Height:    height,
+				Spent:     entry == nil || entry.IsSpent(),
+			})
+		}
+	}
+
+	return btcjson.GetClaimsForAddressResult{
+		Address: c.Address,
+		Claims:  results,
+	}, nil
+}
+
+// scriptPaysAddress returns whether one of the addresses in the script is addr.
+func scriptPaysAddress(pkScript []byte, addr btcutil.Address, params *chaincfg.Params) bool {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, params)
 	if err != nil {
-		context := "Failed to deserialize transaction"
-		return "", "", internalRPCError(err.Error(), context)
+		return false
+	}
+	for _, a := range addrs {
+		if a.EncodeAddress() == addr.EncodeAddress() {
+			return true
+		}
 	}
+	return false
+}
 
-	txo := msgTx.TxOut[outpoint.Index]
-	cs, err := txscript.DecodeClaimScript(txo.PkScript)
+var changeTypeNames = map[change.ChangeType]string{
+	change.AddClaim:     "addclaim",
+	change.SpendClaim:   "spendclaim",
+	change.UpdateClaim:  "updateclaim",
+	change.AddSupport:   "addsupport",
+	change.SpendSupport: "spendsupport",
+
+	change.ActivateClaim:   "activateclaim",
+	change.ExpireClaim:     "expireclaim",
+	change.ActivateSupport: "activatesupport",
+	change.ExpireSupport:   "expiresupport",
+}
+
+var statusNames = map[node.Status]string{
+	node.Accepted:    "accepted",
+	node.Activated:   "activated",
+	node.Deactivated: "deactivated",
+}
+
+func handleGetClaimsInChannel(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetClaimsInChannelCmd)
+	if len(c.ChannelID) != change.ClaimIDSize*2 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Channel ID must be 40 hex characters: " + c.ChannelID,
+		}
+	}
+	id, err := change.NewIDFromString(c.ChannelID)
 	if err != nil {
-		context := "Failed to decode the claim script"
-		return "", "", internalRPCError(err.Error(), context)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to parse the channel ID " + c.ChannelID + ": " + err.Error(),
+		}
+	}
+	if *c.Start < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The start can't be negative",
+		}
+	}
+	if *c.Limit < 0 || *c.Limit > maxClaimsInChannelLimit {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Limit must be between 0 and " + strconv.Itoa(maxClaimsInChannelLimit),
+		}
 	}
 
-	_, addresses, _, _ := txscript.ExtractPkScriptAddrs(txo.PkScript[cs.Size():], s.cfg.ChainParams)
-	return addresses[0].EncodeAddress(), hex.EncodeToString(cs.Value()), nil
+	members, err := s.cfg.Chain.GetClaimsInChannel(id, *c.Start, *c.Limit)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	results := make([]btcjson.ChannelClaimResult, 0, len(members))
+	for _, m := range members {
+		results = append(results, btcjson.ChannelClaimResult{
+			ClaimID: m.ClaimID.String(),
+			Name:    string(m.Name),
+			TXID:    m.OutPoint.Hash.String(),
+			N:       m.OutPoint.Index,
+			Height:  m.Height,
+		})
+	}
+
+	return btcjson.GetClaimsInChannelResult{
+		ChannelID: id.String(),
+		Claims:    results,
+	}, nil
 }
 
-func handleGetNormalized(_ *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
-	c := cmd.(*btcjson.GetNormalizedCmd)
-	r := btcjson.GetNormalizedResult{
-		NormalizedName: string(normalization.Normalize([]byte(c.Name))),
+func handleGetTopClaims(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetTopClaimsCmd)
+	hash, height, err := parseHashOrHeight(s, c.HashOrHeight)
+	if err != nil {
+		return nil, err
 	}
-	return r, nil
+
+	count := 100
+	if c.Count != nil {
+		count = int(*c.Count)
+	}
+	if count < 1 || count > maxTopClaimsCount {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Count must be between 1 and " + strconv.Itoa(maxTopClaimsCount),
+		}
+	}
+	var byName bool
+	if c.ByName != nil {
+		byName = *c.ByName
+	}
+	var prefix string
+	if c.Prefix != nil {
+		prefix = *c.Prefix
+	}
+	var minAmount int64
+	if c.MinAmount != nil {
+		minAmount = *c.MinAmount
+	}
+	if minAmount < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The minimum amount can't be negative",
+		}
+	}
+
+	entries, err := s.cfg.Chain.GetTopClaims(height, byName, prefix, minAmount, count)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	results := make([]btcjson.TopClaimResult, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, btcjson.TopClaimResult{
+			Name:            string(e.Name),
+			ClaimID:         e.ClaimID.String(),
+			EffectiveAmount: e.Amount,
+		})
+	}
+
+	return btcjson.GetTopClaimsResult{
+		Hash:   hash,
+		Height: height,
+		Claims: results,
+	}, nil
+}
+
+func handleGetClaimHistory(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetClaimHistoryCmd)
+	hash, height, err := parseHashOrHeight(s, c.HashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	name, id, entries, err := s.cfg.Chain.GetClaimHistory(height, c.Name, c.PartialClaimID)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	results := make([]btcjson.ClaimHistoryResult, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, btcjson.ClaimHistoryResult{
+			Type:               changeTypeNames[e.Change.Type],
+			Height:             e.Change.Height,
+			Name:               string(e.Change.Name),
+			TXID:               e.Change.OutPoint.Hash.String(),
+			N:                  e.Change.OutPoint.Index,
+			Amount:             e.Change.Amount,
+			ValidAtHeight:      e.ActiveAt,
+			ExpirationHeight:   e.ExpireAt,
+			Status:             statusNames[e.Status],
+			Controlling:        e.Controlling,
+			LastTakeoverHeight: e.TakenOverAt,
+		})
+	}
+
+	return btcjson.GetClaimHistoryResult{
+		Hash:           hash,
+		Height:         height,
+		NormalizedName: name,
+		ClaimID:        id.String(),
+		History:        results,
+	}, nil
+}
+
+func handleGetTakeoverHistory(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetTakeoverHistoryCmd)
+
+	var fromHeight, toHeight int32 = 0, -1
+	if c.FromHeight != nil {
+		fromHeight = *c.FromHeight
+	}
+	if c.ToHeight != nil {
+		toHeight = *c.ToHeight
+	}
+
+	name, takeovers, err := s.cfg.Chain.GetTakeoverHistory(c.Name, fromHeight, toHeight)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	results := make([]btcjson.TakeoverResult, 0, len(takeovers))
+	for _, t := range takeovers {
+		tr := btcjson.TakeoverResult{
+			Height:                  t.Height,
+			PreviousEffectiveAmount: t.PreviousAmount,
+			EffectiveAmount:         t.Amount,
+		}
+		if t.PreviousClaimID != (change.ClaimID{}) {
+			tr.PreviousClaimID = t.PreviousClaimID.String()
+		}
+		if t.ClaimID != (change.ClaimID{}) {
+			tr.ClaimID = t.ClaimID.String()
+		}
+		results = append(results, tr)
+	}
+
+	return btcjson.GetTakeoverHistoryResult{
+		NormalizedName: name,
+		Takeovers:      results,
+	}, nil
+}
+
+// lookupClaims returns the node of the name at the height, or, when includeMempool is set, the node at the
+// tip with the claims, updates and supports waiting in the mempool applied as if they were in the next block.
+func lookupClaims(s *rpcServer, height int32, name string, includeMempool *bool) (string, *node.Node, error) {
+
+	if includeMempool == nil || !*includeMempool {
+		normalizedName, n, err := s.cfg.Chain.GetClaimsForName(height, name)
+		if err != nil {
+			return normalizedName, nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMisc,
+				Message: "Message: " + err.Error(),
+			}
+		}
+		return normalizedName, n, nil
+	}
+
+	if height != s.cfg.Chain.BestSnapshot().Height {
+		return "", nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The mempool can only be included at the tip",
+		}
+	}
+
+	descs := s.cfg.TxMemPool.TxDescs()
+	txs := make([]*btcutil.Tx, 0, len(descs))
+	for _, desc := range descs {
+		txs = append(txs, desc.Tx)
+	}
+
+	normalizedName, n, err := s.cfg.Chain.GetPendingClaimsForName(name, txs)
+	if err != nil {
+		return normalizedName, nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+	return normalizedName, n, nil
+}
+
+// maxListNamesLimit is the largest number of names listnames returns in one call.
+const maxListNamesLimit = 10000
+
+// maxTopClaimsCount is the largest number of claims or names gettopclaims returns in one call.
+const maxTopClaimsCount = 10000
+
+// maxClaimsForAddressCount is the largest number of transactions getclaimsforaddress loads in one call.
+const maxClaimsForAddressCount = 1000
+
+// maxClaimsInChannelLimit is the largest number of claims getclaimsinchannel returns in one call.
+const maxClaimsInChannelLimit = 10000
+
+// maxVerifyClaimTrieBlocks is the largest number of heights verifyclaimtrie verifies in one call, as each
+// call starts by computing the whole trie at its first height.
+const maxVerifyClaimTrieBlocks = 10000
+
+// maxExpiringWithin is the largest window, in blocks, that getclaimtrieinfo looks for expiring names in;
+// it is about a week of blocks.
+const maxExpiringWithin = 4032
+
+// maxExpiringClaimsBlocks is the largest number of heights past the tip that getexpiringclaims looks at;
+// it is about a month of blocks.
+const maxExpiringClaimsBlocks = 17280
+
+func handleListNames(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.ListNamesCmd)
+	hash, height, err := parseHashOrHeight(s, c.HashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefix, start string
+	if c.Prefix != nil {
+		prefix = *c.Prefix
+	}
+	if c.Start != nil {
+		start = *c.Start
+	}
+	limit := 100
+	if c.Limit != nil {
+		limit = int(*c.Limit)
+	}
+	if limit < 1 || limit > maxListNamesLimit {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Limit must be between 1 and " + strconv.Itoa(maxListNamesLimit),
+		}
+	}
+
+	results := make([]btcjson.NameResult, 0, limit)
+	next := ""
+	err = s.cfg.Chain.ListNames(height, prefix, start, func(name string, n *node.Node) bool {
+		if len(results) == limit {
+			next = name
+			return false
+		}
+		select {
+		case <-closeChan:
+			return false
+		default:
+		}
+		id := n.BestClaim.ClaimID
+		results = append(results, btcjson.NameResult{
+			Name:               name,
+			ClaimID:            id.String(),
+			EffectiveAmount:    n.BestClaim.Amount + n.SupportSums[id.Key()],
+			LastTakeoverHeight: n.TakenOverAt,
+		})
+		return true
+	})
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	return btcjson.ListNamesResult{
+		Hash:   hash,
+		Height: height,
+		Names:  results,
+		Next:   next,
+	}, nil
+}
+
+func handleGetClaimTrieInfo(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetClaimTrieInfoCmd)
+
+	expiringWithin := int32(576)
+	if c.ExpiringWithin != nil {
+		expiringWithin = *c.ExpiringWithin
+	}
+	if expiringWithin < 0 || expiringWithin > maxExpiringWithin {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Expiringwithin must be between 0 and " + strconv.Itoa(maxExpiringWithin),
+		}
+	}
+
+	info, err := s.cfg.Chain.GetClaimTrieInfo(expiringWithin)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	result := btcjson.GetClaimTrieInfoResult{
+		Height:          info.Height,
+		MerkleHash:      info.MerkleHash.String(),
+		NodeCacheHits:   info.NodeCacheHits,
+		NodeCacheMisses: info.NodeCacheMisses,
+	}
+	if info.Stats != nil {
+		result.Stats = &btcjson.ClaimTrieStatsResult{
+			Names:            info.Stats.Names,
+			ActiveClaims:     info.Stats.ActiveClaims,
+			AcceptedClaims:   info.Stats.AcceptedClaims,
+			ActiveSupports:   info.Stats.ActiveSupports,
+			AcceptedSupports: info.Stats.AcceptedSupports,
+			ClaimAmount:      info.Stats.ClaimAmount,
+			SupportAmount:    info.Stats.SupportAmount,
+			ExpiringWithin:   expiringWithin,
+			ExpiringNames:    info.ExpiringNames,
+		}
+	}
+	return result, nil
+}
+
+func handleVerifyClaimTrie(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.VerifyClaimTrieCmd)
+
+	var fromHeight, toHeight int32 = 0, -1
+	if c.FromHeight != nil {
+		fromHeight = *c.FromHeight
+	}
+	if c.ToHeight != nil {
+		toHeight = *c.ToHeight
+	}
+	if toHeight < 0 {
+		toHeight = s.cfg.Chain.BestSnapshot().Height
+	}
+	if toHeight-fromHeight >= maxVerifyClaimTrieBlocks {
+		toHeight = fromHeight + maxVerifyClaimTrieBlocks - 1 // the caller goes on from the returned toheight
+	}
+
+	toHeight, d, err := s.cfg.Chain.VerifyClaimTrie(fromHeight, toHeight, closeChan)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	result := btcjson.VerifyClaimTrieResult{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		Valid:      d == nil,
+	}
+	if d != nil {
+		result.DivergentHeight = d.Height
+		result.ComputedHash = d.Computed.String()
+		result.StoredHash = d.Stored.String()
+		if d.Header != nil {
+			result.HeaderHash = d.Header.String()
+		}
+		for _, name := range d.Names {
+			result.Names = append(result.Names, string(name))
+		}
+	}
+	return result, nil
+}
+
+func handleGetExpiringClaims(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.GetExpiringClaimsCmd)
+
+	tip := s.cfg.Chain.BestSnapshot().Height
+	if c.FromHeight <= tip || c.FromHeight > c.ToHeight || c.ToHeight > tip+maxExpiringClaimsBlocks {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Fromheight must be past the tip, and toheight between it and " +
+				strconv.Itoa(maxExpiringClaimsBlocks) + " blocks past the tip",
+		}
+	}
+
+	height, events, err := s.cfg.Chain.GetExpiringClaims(c.FromHeight, c.ToHeight)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	results := make([]btcjson.ScheduledEventResult, 0, len(events))
+	for _, e := range events {
+		results = append(results, btcjson.ScheduledEventResult{
+			Height:  e.Height,
+			Type:    claimEventTypeNames[e.Type],
+			Name:    string(e.Name),
+			ClaimID: e.ClaimID.String(),
+			TXID:    e.OutPoint.Hash.String(),
+			N:       e.OutPoint.Index,
+			Amount:  e.Amount,
+		})
+	}
+
+	return btcjson.GetExpiringClaimsResult{
+		Height: height,
+		Events: results,
+	}, nil
+}
+
+func handleSimulateBid(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.SimulateBidCmd)
+
+	if c.Amount <= 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Amount must be positive",
+		}
+	}
+
+	typ, id := change.AddClaim, change.ClaimID{}
+	if c.ClaimID != nil && *c.ClaimID != "" {
+		if len(*c.ClaimID) != change.ClaimIDSize*2 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Claim ID must be 40 hex characters: " + *c.ClaimID,
+			}
+		}
+		var err error
+		id, err = change.NewIDFromString(*c.ClaimID)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Unable to parse the claim ID " + *c.ClaimID + ": " + err.Error(),
+			}
+		}
+		typ = change.UpdateClaim
+		if c.Support != nil && *c.Support {
+			typ = change.AddSupport
+		}
+	} else if c.Support != nil && *c.Support {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "A support needs the ID of the claim it supports",
+		}
+	}
+
+	sim, err := s.cfg.Chain.SimulateBid(c.Name, c.Amount, typ, id)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	result := btcjson.SimulateBidResult{
+		NormalizedName:   string(sim.Name),
+		Height:           sim.Height,
+		ActivationHeight: sim.ActiveAt,
+		Controlling:      sim.Controlling,
+		TakeoverHeight:   sim.TakenOverAt,
+		EffectiveAmount:  sim.EffectiveAmount,
+		WinningAmount:    sim.WinningAmount,
+		Needed:           sim.Needed,
+	}
+	if typ != change.AddClaim {
+		result.ClaimID = sim.ClaimID.String() // a new claim gets its ID from the output it's in
+	}
+	return result, nil
+}
+
+// toClaimResult converts the claim at index i of a node at the given height. Claims and supports
+// accepted above that height are pending ones from the mempool.
+func toClaimResult(s *rpcServer, i int32, node *node.Node, height int32, includeValues *bool) (btcjson.ClaimResult, error) {
+	claim := node.Claims[i]
+	address, value, err := lookupValue(s, claim.OutPoint, includeValues)
+	if err != nil {
+		return btcjson.ClaimResult{}, err
+	}
+	supports, err := toSupportResults(s, i, node, height, includeValues)
+	result := btcjson.ClaimResult{
+		ClaimID:         claim.ClaimID.String(),
+		Height:          claim.AcceptedAt,
+		ValidAtHeight:   claim.ActiveAt,
+		TXID:            claim.OutPoint.Hash.String(),
+		N:               claim.OutPoint.Index,
+		Bid:             i, // assuming sorted by bid
+		EffectiveAmount: claim.Amount + node.SupportSums[claim.ClaimID.Key()],
+		Sequence:        claim.Sequence,
+		Supports:        supports,
+		Address:         address,
+		Value:           value,
+		Pending:         claim.AcceptedAt > height,
+	}
+	if includeValues != nil && *includeValues {
+		// A value that can't be decoded is still returned in hex, just without its metadata.
+		if tx, cs, addr, err := lookupClaimOutput(s, claim.OutPoint); err == nil {
+			if decoded, err := metadata.Decode(cs.Value()); err == nil {
+				result.Decoded = toDecodedClaimResult(s, decoded, tx, addr, height)
+			}
+		}
+	}
+	return result, err
+}
+
+func toSupportResults(s *rpcServer, i int32, n *node.Node, height int32, includeValues *bool) ([]btcjson.SupportResult, error) {
+	var results []btcjson.SupportResult
+	c := n.Claims[i]
+	for _, sup := range n.Supports {
+		pending := sup.AcceptedAt > height && sup.Status == node.Accepted // not active yet, but still worth showing
+		if (sup.Status == node.Activated || pending) && c.ClaimID == sup.ClaimID {
+			address, value, err := lookupValue(s, sup.OutPoint, includeValues)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, btcjson.SupportResult{
+				TXID:          sup.OutPoint.Hash.String(),
+				N:             sup.OutPoint.Index,
+				Height:        sup.AcceptedAt,
+				ValidAtHeight: sup.ActiveAt,
+				Amount:        sup.Amount,
+				Value:         value,
+				Address:       address,
+				Pending:       sup.AcceptedAt > height,
+			})
+		}
+	}
+	return results, nil
+}
+
+func lookupValue(s *rpcServer, outpoint wire.OutPoint, includeValues *bool) (string, string, error) {
+	if includeValues == nil || !*includeValues {
+		return "", "", nil
+	}
+
+	_, cs, address, err := lookupClaimOutput(s, outpoint)
+	if err != nil {
+		return "", "", err
+	}
+	if address == nil {
+		return "", hex.EncodeToString(cs.Value()), nil
+	}
+	return address.EncodeAddress(), hex.EncodeToString(cs.Value()), nil
+}
+
+// lookupClaimOutput returns the transaction of a claim or support output, along with its claim script and the
+// address it pays to, if any.
+func lookupClaimOutput(s *rpcServer, outpoint wire.OutPoint) (*wire.MsgTx, *txscript.ClaimScript, btcutil.Address, error) {
+
+	msgTx, err := lookupTx(s, &outpoint.Hash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if int(outpoint.Index) >= len(msgTx.TxOut) {
+		return nil, nil, nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidTxVout,
+			Message: "Output index number (vout) does not exist for transaction.",
+		}
+	}
+
+	txo := msgTx.TxOut[outpoint.Index]
+	cs, err := txscript.DecodeClaimScript(txo.PkScript)
+	if err != nil {
+		context := "Failed to decode the claim script"
+		return nil, nil, nil, internalRPCError(err.Error(), context)
+	}
+
+	var address btcutil.Address
+	_, addresses, _, _ := txscript.ExtractPkScriptAddrs(txo.PkScript[cs.Size():], s.cfg.ChainParams)
+	if len(addresses) > 0 {
+		address = addresses[0]
+	}
+	return msgTx, cs, address, nil
+}
+
+// lookupTx returns a transaction from the mempool, where the pending claims and supports are, or the blocks.
+func lookupTx(s *rpcServer, txHash *chainhash.Hash) (*wire.MsgTx, error) {
+	if tx, err := s.cfg.TxMemPool.FetchTransaction(txHash); err == nil {
+		return tx.MsgTx(), nil
+	}
+	return lookupMinedTx(s, txHash)
+}
+
+func lookupMinedTx(s *rpcServer, txHash *chainhash.Hash) (*wire.MsgTx, error) {
+	// TODO: maybe use addrIndex if the txIndex is not available
+
+	if s.cfg.TxIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCNoTxInfo,
+			Message: "The transaction index must be " +
+				"enabled to query the blockchain " +
+				"(specify --txindex)",
+		}
+	}
+
+	blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+	if err != nil {
+		context := "Failed to retrieve transaction location"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if blockRegion == nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	// Load the raw transaction bytes from the database.
+	var txBytes []byte
+	err = s.cfg.DB.View(func(dbTx database.Tx) error {
+		var err error
+		txBytes, err = dbTx.FetchBlockRegion(blockRegion)
+		return err
+	})
+	if err != nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	// Deserialize the transaction
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		context := "Failed to deserialize transaction"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return &msgTx, nil
+}
+
+func handleDecodeClaimValue(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+
+	c := cmd.(*btcjson.DecodeClaimValueCmd)
+	value, err := hex.DecodeString(c.Value)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Value)
+	}
+	claim, err := metadata.Decode(value)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Unable to decode the claim value: " + err.Error(),
+		}
+	}
+
+	// Without the transaction of the claim, there's nothing to verify the signature against.
+	var msgTx *wire.MsgTx
+	var address btcutil.Address
+	if c.TXID != nil && *c.TXID != "" {
+		txHash, err := chainhash.NewHashFromStr(*c.TXID)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.TXID)
+		}
+		msgTx, err = lookupTx(s, txHash)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, txo := range msgTx.TxOut {
+			cs, err := txscript.DecodeClaimScript(txo.PkScript)
+			if err != nil || !bytes.Equal(cs.Value(), value) {
+				continue
+			}
+			_, addresses, _, _ := txscript.ExtractPkScriptAddrs(txo.PkScript[cs.Size():], s.cfg.ChainParams)
+			if len(addresses) > 0 {
+				address = addresses[0]
+			}
+			found = true
+			break
+		}
+		if !found {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Transaction " + *c.TXID + " has no claim with the value",
+			}
+		}
+	}
+
+	return toDecodedClaimResult(s, claim, msgTx, address, s.cfg.Chain.BestSnapshot().Height), nil
+}
+
+// toDecodedClaimResult returns the metadata of a claim. When it's signed, its signature is verified if the
+// signing channel is in the claimtrie at the height and the transaction or address of the claim is known.
+func toDecodedClaimResult(s *rpcServer, c *metadata.Claim, msgTx *wire.MsgTx, address btcutil.Address,
+	height int32) *btcjson.DecodedClaimResult {
+
+	result := &btcjson.DecodedClaimResult{
+		Format:      c.Format.String(),
+		Type:        c.Type.String(),
+		Title:       c.Title,
+		Description: c.Description,
+		Thumbnail:   c.Thumbnail,
+		Tags:        c.Tags,
+		Claims:      claimIDStrings(c.Claims),
+	}
+	if st := c.Stream; st != nil {
+		result.Stream = &btcjson.DecodedStreamResult{
+			MediaType:   st.MediaType,
+			SourceName:  st.SourceName,
+			SourceHash:  hex.EncodeToString(st.SourceHash),
+			SDHash:      hex.EncodeToString(st.SDHash),
+			Size:        st.Size,
+			Author:      st.Author,
+			License:     st.License,
+			LicenseURL:  st.LicenseURL,
+			ReleaseTime: st.ReleaseTime,
+			Kind:        st.Kind,
+		}
+		if st.Fee != nil {
+			result.Stream.Fee = &btcjson.DecodedFeeResult{
+				Currency: st.Fee.Currency,
+				Address:  st.Fee.Address,
+				Amount:   st.Fee.Amount,
+			}
+		}
+	}
+	if ch := c.Channel; ch != nil {
+		result.Channel = &btcjson.DecodedChannelResult{
+			PublicKey:  hex.EncodeToString(ch.PublicKey),
+			Email:      ch.Email,
+			WebsiteURL: ch.WebsiteURL,
+			Cover:      ch.Cover,
+			Featured:   claimIDStrings(ch.Featured),
+		}
+	}
+
+	if !c.Signed() {
+		return result
+	}
+	result.SigningChannel = c.SigningChannel.String()
+	result.Signature = hex.EncodeToString(c.Signature)
+	if publicKey, err := lookupChannelKey(s, c.SigningChannel, height); err == nil {
+		if valid, err := c.VerifySignature(publicKey, msgTx, address); err == nil {
+			result.SignatureValid = &valid
+		}
+	}
+	return result
+}
+
+// lookupChannelKey returns the public key of a channel as of the height.
+func lookupChannelKey(s *rpcServer, id change.ClaimID, height int32) ([]byte, error) {
+
+	_, n, i, err := s.cfg.Chain.GetClaimByID(height, id)
+	if err != nil {
+		return nil, err
+	}
+	_, cs, _, err := lookupClaimOutput(s, n.Claims[i].OutPoint)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := metadata.Decode(cs.Value())
+	if err != nil {
+		return nil, err
+	}
+	if channel.Channel == nil {
+		return nil, fmt.Errorf("claim %s is not a channel", id)
+	}
+	return channel.Channel.PublicKey, nil
+}
+
+func claimIDStrings(ids []change.ClaimID) []string {
+	var results []string
+	for _, id := range ids {
+		results = append(results, id.String())
+	}
+	return results
+}
+
+func handleGetNormalized(_ *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNormalizedCmd)
+	r := btcjson.GetNormalizedResult{
+		NormalizedName: string(normalization.Normalize([]byte(c.Name))),
+	}
+	return r, nil
+}
+
+func handleGetNameProof(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNameProofCmd)
+	return getProof(s, c.Name, "", c.HashOrHeight)
+}
+
+func handleGetClaimProof(s *rpcServer, cmd interface{}, _ <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimProofCmd)
+	if len(c.PartialClaimID) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "A partial claim ID is required",
+		}
+	}
+	return getProof(s, c.Name, c.PartialClaimID, c.HashOrHeight)
+}
+
+func getProof(s *rpcServer, name, partialID string, hashOrHeight *string) (interface{}, error) {
+
+	hash, height, err := parseHashOrHeight(s, hashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	name, p, err := s.cfg.Chain.GetNameProof(height, name, partialID)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Message: " + err.Error(),
+		}
+	}
+
+	return toProofResult(hash, height, name, p), nil
+}
+
+func toProofResult(hash string, height int32, name string, p *proof.Proof) btcjson.ProofResult {
+	r := btcjson.ProofResult{
+		Hash:           hash,
+		Height:         height,
+		NormalizedName: name,
+	}
+	if p.OutPoint != nil {
+		r.TXID = p.OutPoint.Hash.String()
+		r.N = p.OutPoint.Index
+		r.LastTakeoverHeight = p.TakeoverHeight
+	}
+	for _, n := range p.Nodes {
+		nr := btcjson.ProofNodeResult{}
+		if n.ValueHash != nil {
+			nr.ValueHash = n.ValueHash.String()
+		}
+		for _, c := range n.Children {
+			nr.Children = append(nr.Children, btcjson.ProofChildResult{
+				Character: int(c.Character),
+				NodeHash:  c.Hash.String(),
+			})
+		}
+		r.Nodes = append(r.Nodes, nr)
+	}
+	for _, pair := range p.Pairs {
+		r.Pairs = append(r.Pairs, btcjson.ProofPairResult{
+			Right: pair.Right,
+			Hash:  pair.Hash.String(),
+		})
+	}
+	return r
 }
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+// TestParseClaimURL ensures the LBRY URLs are parsed into their name and
+// modifier, and that the malformed ones are rejected.
+func TestParseClaimURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want claimURL
+		err  bool
+	}{
+		{url: "lbry://test", want: claimURL{name: "test"}},
+		{url: "test", want: claimURL{name: "test"}},
+		{url: "test#", err: true},
+		{url: "test#Ab3", want: claimURL{name: "test", claimID: "ab3"}},
+		{url: "test#ab3g", err: true},
+		{url: "test#0123456789012345678901234567890123456789", want: claimURL{name: "test",
+			claimID: "0123456789012345678901234567890123456789"}},
+		{url: "test#01234567890123456789012345678901234567890", err: true},
+		{url: "lbry://test:1", want: claimURL{name: "test", sequence: 1}},
+		{url: "test:12", want: claimURL{name: "test", sequence: 12}},
+		{url: "test:0", err: true},
+		{url: "test:-1", err: true},
+		{url: "test$1", want: claimURL{name: "test", bid: 1}},
+		{url: "test$0", err: true},
+		{url: "test$x", err: true},
+		{url: "test#ab:2", err: true},
+		{url: "lbry://", err: true},
+		{url: "#ab", err: true},
+		{url: "@channel/test", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseClaimURL(test.url)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseClaimURL(%q): expected an error, got %+v", test.url, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseClaimURL(%q): unexpected error: %v", test.url, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseClaimURL(%q): got %+v, want %+v", test.url, got, test.want)
+		}
+	}
+}
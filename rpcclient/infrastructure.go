@@ -295,6 +295,22 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 		for _, addr := range bcmd.Addresses {
 			c.ntfnState.notifyReceived[addr] = struct{}{}
 		}
+
+	case *btcjson.NotifyClaimsCmd:
+		for _, name := range bcmd.Names {
+			c.ntfnState.notifyClaimNames[name] = struct{}{}
+		}
+		for _, id := range bcmd.ClaimIDs {
+			c.ntfnState.notifyClaimIDs[id] = struct{}{}
+		}
+
+	case *btcjson.StopNotifyClaimsCmd:
+		for _, name := range bcmd.Names {
+			delete(c.ntfnState.notifyClaimNames, name)
+		}
+		for _, id := range bcmd.ClaimIDs {
+			delete(c.ntfnState.notifyClaimIDs, id)
+		}
 	}
 }
 
@@ -621,6 +637,24 @@ func (c *Client) reregisterNtfns() error {
 		}
 	}
 
+	// Reregister the combination of all previously registered
+	// notifyclaims names and claim IDs in one command if needed.
+	if len(stateCopy.notifyClaimNames) > 0 || len(stateCopy.notifyClaimIDs) > 0 {
+		names := make([]string, 0, len(stateCopy.notifyClaimNames))
+		for name := range stateCopy.notifyClaimNames {
+			names = append(names, name)
+		}
+		ids := make([]string, 0, len(stateCopy.notifyClaimIDs))
+		for id := range stateCopy.notifyClaimIDs {
+			ids = append(ids, id)
+		}
+		log.Debugf("Reregistering [notifyclaims] names: %v, claim IDs: %v",
+			names, ids)
+		if err := c.NotifyClaims(names, ids); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -37,6 +37,8 @@ type notificationState struct {
 	notifyNewTxVerbose bool
 	notifyReceived     map[string]struct{}
 	notifySpent        map[btcjson.OutPoint]struct{}
+	notifyClaimNames   map[string]struct{}
+	notifyClaimIDs     map[string]struct{}
 }
 
 // Copy returns a deep copy of the receiver.
@@ -53,6 +55,14 @@ func (s *notificationState) Copy() *notificationState {
 	for op := range s.notifySpent {
 		stateCopy.notifySpent[op] = struct{}{}
 	}
+	stateCopy.notifyClaimNames = make(map[string]struct{})
+	for name := range s.notifyClaimNames {
+		stateCopy.notifyClaimNames[name] = struct{}{}
+	}
+	stateCopy.notifyClaimIDs = make(map[string]struct{})
+	for id := range s.notifyClaimIDs {
+		stateCopy.notifyClaimIDs[id] = struct{}{}
+	}
 
 	return &stateCopy
 }
@@ -60,8 +70,10 @@ func (s *notificationState) Copy() *notificationState {
 // newNotificationState returns a new notification state ready to be populated.
 func newNotificationState() *notificationState {
 	return &notificationState{
-		notifyReceived: make(map[string]struct{}),
-		notifySpent:    make(map[btcjson.OutPoint]struct{}),
+		notifyReceived:   make(map[string]struct{}),
+		notifySpent:      make(map[btcjson.OutPoint]struct{}),
+		notifyClaimNames: make(map[string]struct{}),
+		notifyClaimIDs:   make(map[string]struct{}),
 	}
 }
 
@@ -151,6 +163,16 @@ type NotificationHandlers struct {
 	// github.com/decred/dcrrpcclient.
 	OnRelevantTxAccepted func(transaction []byte)
 
+	// OnClaimEvents is invoked when a block connected to, or disconnected
+	// from, the longest (best) chain changes claims or supports on a
+	// subscribed name or claim ID.  It will only be invoked if a preceding
+	// call to NotifyClaims has been made to register for the notification
+	// and the function is non-nil.
+	//
+	// NOTE: This is an lbcd extension.
+	OnClaimEvents func(hash *chainhash.Hash, height int32, disconnected bool,
+		events []btcjson.ClaimEvent)
+
 	// OnRescanFinished is invoked after a rescan finishes due to a previous
 	// call to Rescan or RescanEndHeight.  Finished rescans should be
 	// signaled on this notification, rather than relying on the return
@@ -341,6 +363,24 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnRelevantTxAccepted(transaction)
 
+	// OnClaimEvents
+	case btcjson.ClaimEventsNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnClaimEvents == nil {
+			return
+		}
+
+		hash, height, disconnected, events, err :=
+			parseClaimEventsNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid claimevents "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnClaimEvents(hash, height, disconnected, events)
+
 	// OnRescanFinished
 	case btcjson.RescanFinishedNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -678,6 +718,53 @@ func parseChainTxNtfnParams(params []json.RawMessage) (*btcutil.Tx,
 	return btcutil.NewTx(&msgTx), block, nil
 }
 
+// parseClaimEventsNtfnParams parses out the block hash and height, whether the
+// block was disconnected, and the events from the parameters of a claimevents
+// notification.
+func parseClaimEventsNtfnParams(params []json.RawMessage) (*chainhash.Hash,
+	int32, bool, []btcjson.ClaimEvent, error) {
+
+	if len(params) != 4 {
+		return nil, 0, false, nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var blockHashStr string
+	err := json.Unmarshal(params[0], &blockHashStr)
+	if err != nil {
+		return nil, 0, false, nil, err
+	}
+
+	// Unmarshal second parameter as an integer.
+	var blockHeight int32
+	err = json.Unmarshal(params[1], &blockHeight)
+	if err != nil {
+		return nil, 0, false, nil, err
+	}
+
+	// Unmarshal third parameter as a boolean.
+	var disconnected bool
+	err = json.Unmarshal(params[2], &disconnected)
+	if err != nil {
+		return nil, 0, false, nil, err
+	}
+
+	// Unmarshal fourth parameter as an array of events.
+	var events []btcjson.ClaimEvent
+	err = json.Unmarshal(params[3], &events)
+	if err != nil {
+		return nil, 0, false, nil, err
+	}
+
+	// Create hash from block hash string.
+	blockHash, err := chainhash.NewHashFromStr(blockHashStr)
+	if err != nil {
+		return nil, 0, false, nil, err
+	}
+
+	return blockHash, blockHeight, disconnected, events, nil
+}
+
 // parseRescanProgressParams parses out the height of the last rescanned block
 // from the parameters of rescanfinished and rescanprogress notifications.
 func parseRescanProgressParams(params []json.RawMessage) (*chainhash.Hash, int32, time.Time, error) {
@@ -1355,3 +1442,88 @@ func (c *Client) LoadTxFilterAsync(reload bool, addresses []btcutil.Address,
 func (c *Client) LoadTxFilter(reload bool, addresses []btcutil.Address, outPoints []wire.OutPoint) error {
 	return c.LoadTxFilterAsync(reload, addresses, outPoints).Receive()
 }
+
+// FutureNotifyClaimsResult is a future promise to deliver the result of a
+// NotifyClaimsAsync or StopNotifyClaimsAsync RPC invocation (or an applicable
+// error).
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+type FutureNotifyClaimsResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the registration was not successful.
+func (r FutureNotifyClaimsResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// NotifyClaimsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See NotifyClaims for the blocking version and more details.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+func (c *Client) NotifyClaimsAsync(names []string, claimIDs []string) FutureNotifyClaimsResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := btcjson.NewNotifyClaimsCmd(names, claimIDs)
+	return c.sendCmd(cmd)
+}
+
+// NotifyClaims registers the client to receive notifications when claims or
+// supports on any of the passed names, or on any of the passed (full, hex)
+// claim IDs, are added, updated, spent, activated or expired by a block that
+// is connected to or disconnected from the main chain, and when the control
+// of such a name changes.  The notifications are delivered to the
+// notification handlers associated with the client.  Calling this function
+// has no effect if there are no notification handlers and will result in an
+// error if the client is configured to run in HTTP POST mode.
+//
+// The notifications delivered as a result of this call will be via
+// OnClaimEvents.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+func (c *Client) NotifyClaims(names []string, claimIDs []string) error {
+	return c.NotifyClaimsAsync(names, claimIDs).Receive()
+}
+
+// StopNotifyClaimsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See StopNotifyClaims for the blocking version and more details.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+func (c *Client) StopNotifyClaimsAsync(names []string, claimIDs []string) FutureNotifyClaimsResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := btcjson.NewStopNotifyClaimsCmd(names, claimIDs)
+	return c.sendCmd(cmd)
+}
+
+// StopNotifyClaims cancels the claim notifications previously registered with
+// NotifyClaims for the passed names and claim IDs.
+//
+// NOTE: This is an lbcd extension and requires a websocket connection.
+func (c *Client) StopNotifyClaims(names []string, claimIDs []string) error {
+	return c.StopNotifyClaimsAsync(names, claimIDs).Receive()
+}
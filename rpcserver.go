@@ -4685,9 +4685,10 @@ type rpcserverConfig struct {
 
 	// These fields define any optional indexes the RPC server can make use
 	// of to provide additional data when queried.
-	TxIndex   *indexers.TxIndex
-	AddrIndex *indexers.AddrIndex
-	CfIndex   *indexers.CfIndex
+	TxIndex        *indexers.TxIndex
+	AddrIndex      *indexers.AddrIndex
+	ClaimAddrIndex *indexers.ClaimAddrIndex
+	CfIndex        *indexers.CfIndex
 
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
@@ -4755,6 +4756,16 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
+
+	case blockchain.NTClaimEvents:
+		events, ok := notification.Data.(*blockchain.ClaimEvents)
+		if !ok {
+			rpcsLog.Warnf("Chain claim events notification is not claim events.")
+			break
+		}
+
+		// Notify registered websocket clients.
+		s.ntfnMgr.NotifyClaimEvents(events)
 	}
 }
 
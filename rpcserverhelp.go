@@ -355,13 +355,13 @@ var helpDescsEnUS = map[string]string{
 
 	// GetCFilterCmd help.
 	"getcfilter--synopsis":  "Returns a block's committed filter given its hash.",
-	"getcfilter-filtertype": "The type of filter to return (0=regular)",
+	"getcfilter-filtertype": "The type of filter to return (0=regular, 1=claim names and IDs)",
 	"getcfilter-hash":       "The hash of the block",
 	"getcfilter--result0":   "The block's committed filter",
 
 	// GetCFilterHeaderCmd help.
 	"getcfilterheader--synopsis":  "Returns a block's compact filter header given its hash.",
-	"getcfilterheader-filtertype": "The type of filter header to return (0=regular)",
+	"getcfilterheader-filtertype": "The type of filter header to return (0=regular, 1=claim names and IDs)",
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
@@ -670,6 +670,16 @@ var helpDescsEnUS = map[string]string{
 	"stopnotifyspent--synopsis": "Cancel registered spending notifications for each passed outpoint.",
 	"stopnotifyspent-outpoints": "List of transaction outpoints to stop monitoring.",
 
+	// NotifyClaimsCmd help.
+	"notifyclaims--synopsis": "Send a claimevents notification when a block that adds, updates, spends, activates or expires claims or supports on any of the passed names or claim IDs is connected to or disconnected from the main (best) chain, or when the control of such a name changes.",
+	"notifyclaims-names":     "List of names to monitor (normalized as needed)",
+	"notifyclaims-claimids":  "List of full (40 hex characters) claim IDs to monitor",
+
+	// StopNotifyClaimsCmd help.
+	"stopnotifyclaims--synopsis": "Cancel registered claim notifications for each passed name and claim ID.",
+	"stopnotifyclaims-names":     "List of names to stop monitoring",
+	"stopnotifyclaims-claimids":  "List of full claim IDs to stop monitoring",
+
 	// LoadTxFilterCmd help.
 	"loadtxfilter--synopsis": "Load, add to, or reload a websocket client's transaction filter for mempool transactions, new blocks and rescanblocks.",
 	"loadtxfilter-reload":    "Load a new filter instead of adding data to an existing one",
@@ -737,6 +747,11 @@ var helpDescsEnUS = map[string]string{
 	"getclaimsfornamebybid-includevalues": "Return the metadata and address",
 	"getclaimsfornamebyid-includevalues":  "Return the metadata and address",
 
+	"getclaimsforname-includemempool":      "Also return the claims, updates and supports waiting in the mempool, as they would be if mined in the next block; only at the tip",
+	"getclaimsfornamebyseq-includemempool": "Also return the claims, updates and supports waiting in the mempool, as they would be if mined in the next block; only at the tip",
+	"getclaimsfornamebybid-includemempool": "Also return the claims, updates and supports waiting in the mempool, as they would be if mined in the next block; only at the tip",
+	"getclaimsfornamebyid-includemempool":  "Also return the claims, updates and supports waiting in the mempool, as they would be if mined in the next block; only at the tip",
+
 	"getclaimsfornameresult-claims":         "All the active claims on the given name",
 	"getclaimsfornameresult-normalizedname": "Lower-case version of the passed-in name",
 	"getclaimsfornameresult-height":         "Height of the requested block",
@@ -756,6 +771,7 @@ var helpDescsEnUS = map[string]string{
 	"supportresult-amount":        "LBC staked",
 	"supportresult-height":        "The height when the stake was created or updated",
 	"supportresult-validatheight": "The height when the stake becomes valid",
+	"supportresult-pending":       "The support is waiting in the mempool",
 	"claimresult-value":           "This is the metadata given as part of the claim",
 	"claimresult-txid":            "The hash of the transaction",
 	"claimresult-n":               "The output (TXO) index",
@@ -768,6 +784,8 @@ var helpDescsEnUS = map[string]string{
 	"claimresult-sequence":        "The order this claim was created compared to other claims on this name",
 	"claimresult-bid":             "Bid of 0 means that this claim currently owns the name",
 	"claimresult-claimid":         "20-byte hash of TXID:N, often used in indexes for the claims",
+	"claimresult-pending":         "The claim, or its update, is waiting in the mempool",
+	"claimresult-decoded":         "The metadata of the claim, when it can be decoded",
 
 	"generatetoaddress--synopsis":    "Mine blocks and send their reward to a given address",
 	"generatetoaddress--result0":     "The list of generated blocks' hashes",
@@ -780,6 +798,251 @@ var helpDescsEnUS = map[string]string{
 	"normalize--result0":  "The normalized name",
 	"normalize-name":      "The string to be normalized",
 
+	"getnameproof--synopsis": "Returns the path of hashes proving the winning claim of a name against the ClaimTrie root. " +
+		"Before the AllClaimsInMerkle fork, a name with no winning claim gets a proof of its absence; from the fork on, " +
+		"the root doesn't commit to names, so absent names are rejected and the proof ties the claim's outpoint to the root, " +
+		"leaving the name to be checked against the claim script of that output",
+	"getnameproof-name":            "Requested name for the proof",
+	"getnameproof-hashorheight":    "Requested block hash or height; only the tip is supported",
+	"getclaimproof--synopsis":      "Returns the path of hashes proving a claim against the ClaimTrie root",
+	"getclaimproof-name":           "Name of the claim",
+	"getclaimproof-partialclaimid": "Full or partial claim ID of the claim to prove",
+	"getclaimproof-hashorheight":   "Requested block hash or height; only the tip is supported",
+
+	"proofresult-hash":               "Hash of the block whose ClaimTrie root the proof leads to",
+	"proofresult-height":             "Height of the block",
+	"proofresult-normalizedname":     "The name as stored in the ClaimTrie",
+	"proofresult-txid":               "The hash of the transaction of the proven claim; absent for proofs of non-existence",
+	"proofresult-n":                  "The output (TXO) index of the proven claim",
+	"proofresult-lasttakeoverheight": "The height when the name was last taken over",
+	"proofresult-nodes":              "Nodes from the root to the name; used before the AllClaimsInMerkle fork",
+	"proofresult-pairs":              "Sibling hashes from the claim up to the root; used after the AllClaimsInMerkle fork",
+	"proofnoderesult-children":       "The node's children except the one continuing the path",
+	"proofnoderesult-valuehash":      "The hash of the node's winning claim, if any",
+	"proofchildresult-character":     "The byte value leading to the child",
+	"proofchildresult-nodehash":      "The hash of the child",
+	"proofpairresult-right":          "True when the sibling hash goes on the right",
+	"proofpairresult-hash":           "The sibling hash",
+
+	"getclaimbyid--synopsis":            "Returns the claim with the given ID; requires --claimidindex",
+	"getclaimbyid-claimid":              "Full claim ID (40 hex characters)",
+	"getclaimbyid-hashorheight":         "Requested block hash or height; default to the current tip",
+	"getclaimbyid-includevalues":        "Return the metadata and address",
+	"getclaimbyidresult-hash":           "Hash of the requested block",
+	"getclaimbyidresult-height":         "Height of the requested block",
+	"getclaimbyidresult-normalizedname": "The name of the claim as stored in the ClaimTrie",
+	"getclaimbyidresult-status":         "Either accepted, when the claim is waiting for its activation, or activated",
+	"getclaimbyidresult-claim":          "The claim",
+
+	"getclaimhistory--synopsis":             "Returns every change made to a claim and its supports, with the heights computed for each of them",
+	"getclaimhistory-name":                  "Name of the claim",
+	"getclaimhistory-partialclaimid":        "Full or partial claim ID of the claim",
+	"getclaimhistory-hashorheight":          "Requested block hash or height; default to the current tip",
+	"getclaimhistoryresult-hash":            "Hash of the requested block",
+	"getclaimhistoryresult-height":          "Height of the requested block",
+	"getclaimhistoryresult-normalizedname":  "The name as stored in the ClaimTrie",
+	"getclaimhistoryresult-claimid":         "The full ID of the matching claim",
+	"getclaimhistoryresult-history":         "The changes in the order they were applied",
+	"claimhistoryresult-type":               "One of addclaim, updateclaim, spendclaim, addsupport or spendsupport",
+	"claimhistoryresult-height":             "The height of the block that contained the change",
+	"claimhistoryresult-name":               "The name given in the transaction",
+	"claimhistoryresult-txid":               "The hash of the transaction",
+	"claimhistoryresult-n":                  "The output (TXO) index",
+	"claimhistoryresult-amount":             "LBC staked",
+	"claimhistoryresult-validatheight":      "The height when the stake becomes (or became) valid",
+	"claimhistoryresult-expirationheight":   "The height when the stake expires",
+	"claimhistoryresult-status":             "One of accepted, activated or deactivated; spends show the status of the stake before it was spent",
+	"claimhistoryresult-controlling":        "True when the claim owned the name at the end of the block",
+	"claimhistoryresult-lasttakeoverheight": "The height when the name was last taken over, as of the end of the block",
+
+	"gettakeoverhistory--synopsis":            "Returns the changes of the controlling claim of a name; requires --takeoverindex",
+	"gettakeoverhistory-name":                 "Requested name",
+	"gettakeoverhistory-fromheight":           "First height of interest (inclusive)",
+	"gettakeoverhistory-toheight":             "Last height of interest (inclusive); -1 for the current tip",
+	"gettakeoverhistoryresult-normalizedname": "The name as stored in the ClaimTrie, normalized at toheight",
+	"gettakeoverhistoryresult-takeovers":      "The takeovers in height order",
+	"takeoverresult-height":                   "The height of the takeover",
+	"takeoverresult-previousclaimid":          "The claim that controlled the name before; absent when there was none",
+	"takeoverresult-claimid":                  "The claim that controls the name after; absent when the name was left without claims",
+	"takeoverresult-previouseffectiveamount":  "The effective amount of the previous claim at the takeover height; 0 if it was spent or expired",
+	"takeoverresult-effectiveamount":          "The effective amount of the new claim at the takeover height",
+
+	"listnames--synopsis":           "Returns, in byte order, the names that have a controlling claim along with that claim",
+	"listnames-prefix":              "Only list the names that start with this prefix",
+	"listnames-start":               "Start listing at this name (inclusive); pass the next field of the previous result to get the following page",
+	"listnames-limit":               "The maximum number of names to return, up to 10000",
+	"listnames-hashorheight":        "Requested block hash or height; default to tip",
+	"listnamesresult-hash":          "Hash of the requested block",
+	"listnamesresult-height":        "Height of the requested block",
+	"listnamesresult-names":         "The names, normalized as stored in the ClaimTrie",
+	"listnamesresult-next":          "The name to start the next page at; absent when there are no more names",
+	"nameresult-name":               "The name",
+	"nameresult-claimid":            "The ID of the controlling claim",
+	"nameresult-effectiveamount":    "The amount of the controlling claim plus its active supports",
+	"nameresult-lasttakeoverheight": "The height when the name was last taken over",
+
+	"getclaimtrieinfo--synopsis":             "Returns the height and hash of the ClaimTrie along with aggregate statistics of its names, claims and supports",
+	"getclaimtrieinfo-expiringwithin":        "Count the names whose controlling claim expires within this many blocks, up to 4032; requires --claimtriestats",
+	"getclaimtrieinforesult-height":          "The height of the ClaimTrie",
+	"getclaimtrieinforesult-merklehash":      "The root hash of the ClaimTrie at that height",
+	"getclaimtrieinforesult-stats":           "The aggregate statistics; requires --claimtriestats",
+	"getclaimtrieinforesult-nodecachehits":   "The number of node lookups served from the node cache since startup",
+	"getclaimtrieinforesult-nodecachemisses": "The number of node lookups that had to replay the changes of the name since startup",
+	"claimtriestatsresult-names":             "The number of names with at least one claim",
+	"claimtriestatsresult-activeclaims":      "The number of active claims",
+	"claimtriestatsresult-acceptedclaims":    "The number of claims waiting for activation",
+	"claimtriestatsresult-activesupports":    "The number of active supports",
+	"claimtriestatsresult-acceptedsupports":  "The number of supports waiting for activation",
+	"claimtriestatsresult-claimamount":       "The total amount staked in active and accepted claims, in dewies",
+	"claimtriestatsresult-supportamount":     "The total amount staked in active and accepted supports, in dewies",
+	"claimtriestatsresult-expiringwithin":    "The number of blocks used for expiringnames",
+	"claimtriestatsresult-expiringnames":     "The number of names whose controlling claim expires within expiringwithin blocks",
+
+	"verifyclaimtrie--synopsis":             "Recomputes the ClaimTrie from its nodes at each height in the range, up to 10000 of them per call, and compares it with the stored hash and the block header; the chain is locked while each height is verified",
+	"verifyclaimtrie-fromheight":            "The first height to verify",
+	"verifyclaimtrie-toheight":              "The last height to verify; -1 for the tip. Ranges longer than 10000 heights stop short of it",
+	"verifyclaimtrieresult-fromheight":      "The first height verified",
+	"verifyclaimtrieresult-toheight":        "The last height verified",
+	"verifyclaimtrieresult-valid":           "Whether the recomputed hash matched at every height",
+	"verifyclaimtrieresult-divergentheight": "The first height at which the hashes differ; absent when valid",
+	"verifyclaimtrieresult-computedhash":    "The hash recomputed from the nodes at that height",
+	"verifyclaimtrieresult-storedhash":      "The hash the ClaimTrie stored at that height",
+	"verifyclaimtrieresult-headerhash":      "The ClaimTrie hash of the block header at that height",
+	"verifyclaimtrieresult-names":           "The names whose hashes changed at that height, one of which is computed differently; absent at the first height verified and at the hash fork, where the whole trie is computed",
+
+	"getexpiringclaims--synopsis":    "Returns the claims and supports that expire or activate between two heights past the tip, as things stand at the tip",
+	"getexpiringclaims-fromheight":   "The first height to look at; it must be past the tip",
+	"getexpiringclaims-toheight":     "The last height to look at, up to 17280 blocks (about a month) past the tip",
+	"getexpiringclaimsresult-height": "The height of the tip the events are predicted from; the blocks to come can change them",
+	"getexpiringclaimsresult-events": "The events in height order",
+	"scheduledeventresult-height":    "The height the event is due at",
+	"scheduledeventresult-type":      "One of activateclaim, expireclaim, activatesupport or expiresupport",
+	"scheduledeventresult-name":      "The name, normalized as stored in the ClaimTrie",
+	"scheduledeventresult-claimid":   "The ID of the claim, or of the claim a support supports",
+	"scheduledeventresult-txid":      "The transaction of the claim or support",
+	"scheduledeventresult-n":         "The output index of the claim or support",
+	"scheduledeventresult-amount":    "The amount of the claim or support, in dewies",
+
+	"simulatebid--synopsis":              "Predicts what a bid placed in the next block would do, as things stand at the tip; the mempool and the blocks to come are not accounted for",
+	"simulatebid-name":                   "The name to bid on",
+	"simulatebid-amount":                 "The amount of the bid, in dewies",
+	"simulatebid-claimid":                "The claim to update, or to support; a new claim if empty",
+	"simulatebid-support":                "Whether the bid supports the claim instead of updating it",
+	"simulatebidresult-normalizedname":   "The name, normalized as stored in the ClaimTrie",
+	"simulatebidresult-height":           "The height the bid is placed at",
+	"simulatebidresult-claimid":          "The claim the bid is for; absent for a new claim",
+	"simulatebidresult-activationheight": "The height the bid activates at",
+	"simulatebidresult-controlling":      "Whether the claim controls the name once the bid is active",
+	"simulatebidresult-takeoverheight":   "The height the claim took, or takes, over the name at; absent when it doesn't control it",
+	"simulatebidresult-effectiveamount":  "The effective amount of the claim once the bid is active, in dewies",
+	"simulatebidresult-winningamount":    "The effective amount of the strongest other active claim by then, in dewies",
+	"simulatebidresult-needed":           "How much more the bid needs to beat that claim, in dewies; 0 if it does",
+
+	"getclaimtriechanges--synopsis":       "Returns the changes a block made to the claims and supports, including the activations and expirations that were due at it; it needs the claimchangeindex",
+	"getclaimtriechanges-hashorheight":    "Requested block hash or height; default to the chain tip",
+	"getclaimtriechangesresult-hash":      "Hash of the block",
+	"getclaimtriechangesresult-height":    "Height of the block",
+	"getclaimtriechangesresult-changes":   "The changes in the order they were made; those of the transactions come before the activations and expirations of each name",
+	"claimtriechangeresult-type":          "One of addclaim, spendclaim, updateclaim, addsupport, spendsupport, activateclaim, expireclaim, activatesupport or expiresupport",
+	"claimtriechangeresult-name":          "The name, normalized as stored in the ClaimTrie",
+	"claimtriechangeresult-claimid":       "The ID of the claim, or of the claim a support supports",
+	"claimtriechangeresult-txid":          "The transaction of the claim or support",
+	"claimtriechangeresult-n":             "The output index of the claim or support",
+	"claimtriechangeresult-amount":        "The amount of the claim or support, in dewies; 0 for spends",
+	"claimtriechangeresult-height":        "The height the claim or support was accepted at, which is earlier than the block for the copies made at the normalization fork",
+	"claimtriechangeresult-activeheight":  "The height the claim or support activates, or activated, at; absent for spends",
+	"claimtriechangeresult-visibleheight": "The height the claim or support became visible at under its name; absent for spends",
+
+	"resolve--synopsis":            "Returns the claim a LBRY URL points to: lbry://name for the controlling claim, name#claimidprefix for the only claim whose ID starts with the prefix, name:sequence for the claim made in that order, or name$bid for the claim in that place in bid order; both count from 1, unlike getclaimsfornamebyseq and getclaimsfornamebybid",
+	"resolve-url":                  "The URL; the lbry:// is optional, and channel paths are not supported",
+	"resolve-hashorheight":         "Requested block hash or height; default to the current tip",
+	"resolve-includevalues":        "Return the metadata and address",
+	"resolveresult-hash":           "Hash of the requested block",
+	"resolveresult-height":         "Height of the requested block",
+	"resolveresult-normalizedname": "The name of the claim as stored in the ClaimTrie",
+	"resolveresult-claim":          "The claim",
+
+	"getclaimsforaddress--synopsis": "Returns the claims, updates and supports paid to an address in the blocks, in the order they were made. " +
+		"Usage of this RPC requires the optional --addrindex flag to be activated; the transactions in the memory pool are not included",
+	"getclaimsforaddress-address":       "The address the claims and supports pay to",
+	"getclaimsforaddress-skip":          "The number of leading transactions to leave out of the results",
+	"getclaimsforaddress-count":         "The maximum number of transactions to return the claims and supports of, up to 1000",
+	"getclaimsforaddress-reverse":       "Specifies that the transactions should be returned in reverse chronological order",
+	"getclaimsforaddressresult-address": "The address",
+	"getclaimsforaddressresult-claims":  "The claims and supports",
+	"addressclaimresult-type":           "One of addclaim, updateclaim or addsupport",
+	"addressclaimresult-name":           "The name in the claim script",
+	"addressclaimresult-claimid":        "The ID of the claim, or of the claim a support supports",
+	"addressclaimresult-txid":           "The transaction of the claim or support",
+	"addressclaimresult-n":              "The output index of the claim or support",
+	"addressclaimresult-amount":         "The amount of the claim or support, in dewies",
+	"addressclaimresult-blockhash":      "Hash of the block the transaction is part of",
+	"addressclaimresult-height":         "Height of the block the transaction is part of",
+	"addressclaimresult-spent":          "Whether the output has been spent since",
+
+	"decodeclaimvalue--synopsis": "Decodes the metadata of a claim value, in any of the formats LBRY has used. " +
+		"When the claim is signed and its transaction is given, the signature is verified against the public key of the signing channel",
+	"decodeclaimvalue-value":            "The claim value, hex-encoded",
+	"decodeclaimvalue-txid":             "The transaction of the claim, needed to verify its signature",
+	"decodedclaimresult-format":         "One of json, legacy or current",
+	"decodedclaimresult-type":           "One of stream, channel, collection or repost",
+	"decodedclaimresult-title":          "The title",
+	"decodedclaimresult-description":    "The description",
+	"decodedclaimresult-thumbnail":      "The URL of the thumbnail",
+	"decodedclaimresult-tags":           "The tags",
+	"decodedclaimresult-stream":         "The metadata of the stream",
+	"decodedclaimresult-channel":        "The metadata of the channel",
+	"decodedclaimresult-claims":         "The claims of a collection, or the claim a repost is for",
+	"decodedclaimresult-signingchannel": "The ID of the channel that signed the claim",
+	"decodedclaimresult-signature":      "The signature of the channel, hex-encoded",
+	"decodedclaimresult-signaturevalid": "Whether the signature is valid, when the signing channel and the transaction of the claim are known",
+	"decodedstreamresult-mediatype":     "The media type of the content",
+	"decodedstreamresult-sourcename":    "The file name of the content",
+	"decodedstreamresult-sourcehash":    "The hash of the content, hex-encoded",
+	"decodedstreamresult-sdhash":        "The hash of the stream descriptor blob, hex-encoded",
+	"decodedstreamresult-size":          "The size of the content in bytes",
+	"decodedstreamresult-author":        "The author",
+	"decodedstreamresult-license":       "The license",
+	"decodedstreamresult-licenseurl":    "The URL of the license",
+	"decodedstreamresult-releasetime":   "The release time, in seconds since the UNIX epoch",
+	"decodedstreamresult-kind":          "One of image, video, audio or software",
+	"decodedstreamresult-fee":           "The price of the stream",
+	"decodedfeeresult-currency":         "One of LBC, BTC or USD",
+	"decodedfeeresult-address":          "The address to pay",
+	"decodedfeeresult-amount":           "The amount in the smallest unit of the currency: dewies, satoshis or cents",
+	"decodedchannelresult-publickey":    "The public key of the channel, hex-encoded",
+	"decodedchannelresult-email":        "The email address",
+	"decodedchannelresult-websiteurl":   "The URL of the website",
+	"decodedchannelresult-cover":        "The URL of the cover image",
+	"decodedchannelresult-featured":     "The featured claims",
+
+	"getclaimsinchannel--synopsis": "Returns the stream claims validly signed by a channel, in the order they joined it; " +
+		"updates that stay in the channel keep their place. Usage of this RPC requires the optional --channelindex flag to be activated",
+	"getclaimsinchannel-channelid":       "The claim ID of the channel",
+	"getclaimsinchannel-start":           "The number of leading claims to leave out of the results",
+	"getclaimsinchannel-limit":           "The maximum number of claims to return, up to 10000",
+	"getclaimsinchannelresult-channelid": "The claim ID of the channel",
+	"getclaimsinchannelresult-claims":    "The claims",
+	"channelclaimresult-claimid":         "The ID of the claim",
+	"channelclaimresult-name":            "The name of the claim, as it is in its script",
+	"channelclaimresult-txid":            "The transaction of the latest version of the claim",
+	"channelclaimresult-n":               "The output index of the latest version of the claim",
+	"channelclaimresult-height":          "The height the claim joined the channel at",
+
+	"gettopclaims--synopsis": "Returns the active claims, or the names by their controlling claims, ranked by their effective amounts: " +
+		"those of the claims plus their activated supports. Usage of this RPC requires the optional --rankingindex flag to be activated",
+	"gettopclaims-count":             "The maximum number of claims or names to return, up to 10000",
+	"gettopclaims-byname":            "Rank the names by their controlling claims rather than every claim",
+	"gettopclaims-prefix":            "Only rank the names that start with this prefix",
+	"gettopclaims-minamount":         "Leave out the claims and names below this effective amount, in dewies",
+	"gettopclaims-hashorheight":      "Requested block hash or height, at most 100 blocks below the tip; default to tip",
+	"gettopclaimsresult-hash":        "Hash of the requested block",
+	"gettopclaimsresult-height":      "Height of the requested block",
+	"gettopclaimsresult-claims":      "The claims or names, highest effective amount first",
+	"topclaimresult-name":            "The name, normalized as stored in the ClaimTrie",
+	"topclaimresult-claimid":         "The ID of the claim, or of the controlling claim of the name",
+	"topclaimresult-effectiveamount": "The amount of the claim plus those of its activated supports, in dewies",
+
 	"getblockverboseresult-getblockverboseresultbase": "",
 	"prevout-issupport": "Previous output created a support",
 	"prevout-isclaim":   "Previous output created or updated a claim",
@@ -850,6 +1113,8 @@ var rpcResultTypes = map[string][]interface{}{
 	"stopnotifyreceived":        nil,
 	"notifyspent":               nil,
 	"stopnotifyspent":           nil,
+	"notifyclaims":              nil,
+	"stopnotifyclaims":          nil,
 	"rescan":                    nil,
 	"rescanblocks":              {(*[]btcjson.RescannedBlock)(nil)},
 
@@ -860,6 +1125,22 @@ var rpcResultTypes = map[string][]interface{}{
 	"getclaimsfornamebyseq": {(*btcjson.GetClaimsForNameResult)(nil)},
 	"normalize":             {(*string)(nil)},
 	"getchangesinblock":     {(*btcjson.GetChangesInBlockResult)(nil)},
+	"getnameproof":          {(*btcjson.ProofResult)(nil)},
+	"getclaimproof":         {(*btcjson.ProofResult)(nil)},
+	"getclaimbyid":          {(*btcjson.GetClaimByIDResult)(nil)},
+	"getclaimhistory":       {(*btcjson.GetClaimHistoryResult)(nil)},
+	"gettakeoverhistory":    {(*btcjson.GetTakeoverHistoryResult)(nil)},
+	"listnames":             {(*btcjson.ListNamesResult)(nil)},
+	"getclaimtrieinfo":      {(*btcjson.GetClaimTrieInfoResult)(nil)},
+	"verifyclaimtrie":       {(*btcjson.VerifyClaimTrieResult)(nil)},
+	"getexpiringclaims":     {(*btcjson.GetExpiringClaimsResult)(nil)},
+	"simulatebid":           {(*btcjson.SimulateBidResult)(nil)},
+	"getclaimtriechanges":   {(*btcjson.GetClaimTrieChangesResult)(nil)},
+	"resolve":               {(*btcjson.ResolveResult)(nil)},
+	"getclaimsforaddress":   {(*btcjson.GetClaimsForAddressResult)(nil)},
+	"decodeclaimvalue":      {(*btcjson.DecodedClaimResult)(nil)},
+	"getclaimsinchannel":    {(*btcjson.GetClaimsInChannelResult)(nil)},
+	"gettopclaims":          {(*btcjson.GetTopClaimsResult)(nil)},
 }
 
 // helpCacher provides a concurrent safe type that provides help and usage for
@@ -25,6 +25,9 @@ import (
 	"github.com/lbryio/lbcd/btcjson"
 	"github.com/lbryio/lbcd/chaincfg"
 	"github.com/lbryio/lbcd/chaincfg/chainhash"
+	"github.com/lbryio/lbcd/claimtrie"
+	"github.com/lbryio/lbcd/claimtrie/change"
+	"github.com/lbryio/lbcd/claimtrie/normalization"
 	"github.com/lbryio/lbcd/database"
 	"github.com/lbryio/lbcd/txscript"
 	"github.com/lbryio/lbcd/wire"
@@ -66,11 +69,13 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"loadtxfilter":              handleLoadTxFilter,
 	"help":                      handleWebsocketHelp,
 	"notifyblocks":              handleNotifyBlocks,
+	"notifyclaims":              handleNotifyClaims,
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
+	"stopnotifyclaims":          handleStopNotifyClaims,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
 	"stopnotifyspent":           handleStopNotifySpent,
 	"stopnotifyreceived":        handleStopNotifyReceived,
@@ -226,6 +231,20 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *btcutil.Block) {
 	}
 }
 
+// NotifyClaimEvents passes the claim events of a block connected to, or
+// disconnected from, the best chain to the notification manager for claim
+// notification processing.
+func (m *wsNotificationManager) NotifyClaimEvents(events *blockchain.ClaimEvents) {
+	// As NotifyClaimEvents will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- (*notificationClaimEvents)(events):
+	case <-m.quit:
+	}
+}
+
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is is a new transaction, rather than one
@@ -447,6 +466,7 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 // Notification types
 type notificationBlockConnected btcutil.Block
 type notificationBlockDisconnected btcutil.Block
+type notificationClaimEvents blockchain.ClaimEvents
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *btcutil.Tx
@@ -475,6 +495,16 @@ type notificationUnregisterAddr struct {
 	wsc  *wsClient
 	addr string
 }
+type notificationRegisterClaims struct {
+	wsc   *wsClient
+	names []string
+	ids   []change.ClaimID
+}
+type notificationUnregisterClaims struct {
+	wsc   *wsClient
+	names []string
+	ids   []change.ClaimID
+}
 
 // notificationHandler reads notifications and control messages from the queue
 // handler and processes one at a time.
@@ -493,6 +523,11 @@ func (m *wsNotificationManager) notificationHandler() {
 	txNotifications := make(map[chan struct{}]*wsClient)
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
+	watchedClaimNames := make(map[string]map[chan struct{}]*wsClient)
+	watchedClaimIDs := make(map[change.ClaimID]map[chan struct{}]*wsClient)
+
+	// The chain only computes claim events while somebody watches claims.
+	watchingClaims := false
 
 out:
 	for {
@@ -532,6 +567,12 @@ out:
 						block)
 				}
 
+			case *notificationClaimEvents:
+				if len(watchedClaimNames) != 0 || len(watchedClaimIDs) != 0 {
+					m.notifyClaimEvents(watchedClaimNames,
+						watchedClaimIDs, (*blockchain.ClaimEvents)(n))
+				}
+
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
@@ -564,6 +605,18 @@ out:
 				for addr := range wsc.addrRequests {
 					m.removeAddrRequest(watchedAddrs, wsc, addr)
 				}
+				names := make([]string, 0, len(wsc.claimNameRequests))
+				for name := range wsc.claimNameRequests {
+					names = append(names, name)
+				}
+				ids := make([]change.ClaimID, 0, len(wsc.claimIDRequests))
+				for id := range wsc.claimIDRequests {
+					ids = append(ids, id)
+				}
+				m.removeClaimRequests(watchedClaimNames, watchedClaimIDs,
+					wsc, names, ids)
+				watchingClaims = m.watchClaimEvents(watchingClaims,
+					len(watchedClaimNames)+len(watchedClaimIDs) != 0)
 				delete(clients, wsc.quit)
 
 			case *notificationRegisterSpent:
@@ -578,6 +631,18 @@ out:
 			case *notificationUnregisterAddr:
 				m.removeAddrRequest(watchedAddrs, n.wsc, n.addr)
 
+			case *notificationRegisterClaims:
+				m.addClaimRequests(watchedClaimNames, watchedClaimIDs,
+					n.wsc, n.names, n.ids)
+				watchingClaims = m.watchClaimEvents(watchingClaims,
+					len(watchedClaimNames)+len(watchedClaimIDs) != 0)
+
+			case *notificationUnregisterClaims:
+				m.removeClaimRequests(watchedClaimNames, watchedClaimIDs,
+					n.wsc, n.names, n.ids)
+				watchingClaims = m.watchClaimEvents(watchingClaims,
+					len(watchedClaimNames)+len(watchedClaimIDs) != 0)
+
 			case *notificationRegisterNewMempoolTxs:
 				wsc := (*wsClient)(n)
 				txNotifications[wsc.quit] = wsc
@@ -598,6 +663,7 @@ out:
 		}
 	}
 
+	m.watchClaimEvents(watchingClaims, false)
 	for _, c := range clients {
 		c.Disconnect()
 	}
@@ -1176,6 +1242,177 @@ func (*wsNotificationManager) removeAddrRequest(addrs map[string]map[chan struct
 	}
 }
 
+// RegisterClaimRequests requests notifications to the passed websocket client
+// when claims or supports on any of the passed (normalized) names, or on any
+// of the passed claim IDs, change.
+func (m *wsNotificationManager) RegisterClaimRequests(wsc *wsClient, names []string, ids []change.ClaimID) {
+	m.queueNotification <- &notificationRegisterClaims{
+		wsc:   wsc,
+		names: names,
+		ids:   ids,
+	}
+}
+
+// addClaimRequests adds the websocket client wsc to the name and claim ID to
+// client sets so wsc will be notified for any claim events on them.
+func (*wsNotificationManager) addClaimRequests(nameMap map[string]map[chan struct{}]*wsClient,
+	idMap map[change.ClaimID]map[chan struct{}]*wsClient, wsc *wsClient,
+	names []string, ids []change.ClaimID) {
+
+	for _, name := range names {
+		// Track the request in the client as well so it can be quickly be
+		// removed on disconnect.
+		wsc.claimNameRequests[name] = struct{}{}
+
+		cmap, ok := nameMap[name]
+		if !ok {
+			cmap = make(map[chan struct{}]*wsClient)
+			nameMap[name] = cmap
+		}
+		cmap[wsc.quit] = wsc
+	}
+	for _, id := range ids {
+		wsc.claimIDRequests[id] = struct{}{}
+
+		cmap, ok := idMap[id]
+		if !ok {
+			cmap = make(map[chan struct{}]*wsClient)
+			idMap[id] = cmap
+		}
+		cmap[wsc.quit] = wsc
+	}
+}
+
+// UnregisterClaimRequests removes a request from the passed websocket client
+// to be notified of claim events on the passed names and claim IDs.
+func (m *wsNotificationManager) UnregisterClaimRequests(wsc *wsClient, names []string, ids []change.ClaimID) {
+	m.queueNotification <- &notificationUnregisterClaims{
+		wsc:   wsc,
+		names: names,
+		ids:   ids,
+	}
+}
+
+// removeClaimRequests removes the websocket client wsc from the name and claim
+// ID to client sets so it will no longer receive claim events for them.
+func (*wsNotificationManager) removeClaimRequests(nameMap map[string]map[chan struct{}]*wsClient,
+	idMap map[change.ClaimID]map[chan struct{}]*wsClient, wsc *wsClient,
+	names []string, ids []change.ClaimID) {
+
+	for _, name := range names {
+		delete(wsc.claimNameRequests, name)
+
+		cmap, ok := nameMap[name]
+		if !ok {
+			rpcsLog.Warnf("Attempt to remove nonexistent claim request "+
+				"for name %q for websocket client %s", name, wsc.addr)
+			continue
+		}
+		delete(cmap, wsc.quit)
+		if len(cmap) == 0 {
+			delete(nameMap, name)
+		}
+	}
+	for _, id := range ids {
+		delete(wsc.claimIDRequests, id)
+
+		cmap, ok := idMap[id]
+		if !ok {
+			rpcsLog.Warnf("Attempt to remove nonexistent claim request "+
+				"for claim ID %s for websocket client %s", id, wsc.addr)
+			continue
+		}
+		delete(cmap, wsc.quit)
+		if len(cmap) == 0 {
+			delete(idMap, id)
+		}
+	}
+}
+
+// watchClaimEvents tells the chain to start or stop computing claim events
+// when whether they are needed changes, and returns the new state.
+func (m *wsNotificationManager) watchClaimEvents(watching, needed bool) bool {
+	if watching != needed {
+		m.server.cfg.Chain.WatchClaimEvents(needed)
+	}
+	return needed
+}
+
+// claimEventTypeNames maps the claim events to the types used by the
+// claimevents notification.
+var claimEventTypeNames = map[claimtrie.EventType]string{
+	claimtrie.ClaimAdded:       "addclaim",
+	claimtrie.ClaimUpdated:     "updateclaim",
+	claimtrie.ClaimSpent:       "spendclaim",
+	claimtrie.ClaimActivated:   "activateclaim",
+	claimtrie.ClaimExpired:     "expireclaim",
+	claimtrie.SupportAdded:     "addsupport",
+	claimtrie.SupportSpent:     "spendsupport",
+	claimtrie.SupportActivated: "activatesupport",
+	claimtrie.SupportExpired:   "expiresupport",
+	claimtrie.Takeover:         "takeover",
+}
+
+// notifyClaimEvents sends a claimevents notification with the events of a
+// block to each websocket client that subscribed to any of their names or
+// claim IDs.  A client gets each event once, no matter how many of its
+// subscriptions match it.
+func (*wsNotificationManager) notifyClaimEvents(nameMap map[string]map[chan struct{}]*wsClient,
+	idMap map[change.ClaimID]map[chan struct{}]*wsClient, ce *blockchain.ClaimEvents) {
+
+	clients := make(map[chan struct{}]*wsClient)
+	subscribed := make(map[chan struct{}][]btcjson.ClaimEvent)
+	for _, e := range ce.Events {
+		matched := make(map[chan struct{}]*wsClient)
+		for quit, wsc := range nameMap[string(e.Name)] {
+			matched[quit] = wsc
+		}
+		for _, id := range []change.ClaimID{e.ClaimID, e.PreviousClaimID} {
+			if id == (change.ClaimID{}) {
+				continue
+			}
+			for quit, wsc := range idMap[id] {
+				matched[quit] = wsc
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		event := btcjson.ClaimEvent{
+			Type:   claimEventTypeNames[e.Type],
+			Name:   string(e.Name),
+			N:      e.OutPoint.Index,
+			Amount: e.Amount,
+		}
+		if e.ClaimID != (change.ClaimID{}) {
+			event.ClaimID = e.ClaimID.String()
+		}
+		if e.PreviousClaimID != (change.ClaimID{}) {
+			event.PreviousClaimID = e.PreviousClaimID.String()
+		}
+		if e.OutPoint.Hash != (chainhash.Hash{}) {
+			event.TXID = e.OutPoint.Hash.String()
+		}
+		for quit, wsc := range matched {
+			clients[quit] = wsc
+			subscribed[quit] = append(subscribed[quit], event)
+		}
+	}
+
+	for quit, events := range subscribed {
+		ntfn := btcjson.NewClaimEventsNtfn(ce.Block.Hash().String(),
+			ce.Block.Height(), ce.Disconnected, events)
+		marshalled, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal claim events "+
+				"notification: %v", err)
+			return
+		}
+		clients[quit].QueueNotification(marshalled)
+	}
+}
+
 // AddClient adds the passed websocket client to the notification manager.
 func (m *wsNotificationManager) AddClient(wsc *wsClient) {
 	m.queueNotification <- (*notificationRegisterClient)(wsc)
@@ -1287,6 +1524,12 @@ type wsClient struct {
 	// Owned by the notification manager.
 	spentRequests map[wire.OutPoint]struct{}
 
+	// claimNameRequests and claimIDRequests are the sets of normalized
+	// names and claim IDs the caller has requested claim events for.
+	// Owned by the notification manager.
+	claimNameRequests map[string]struct{}
+	claimIDRequests   map[change.ClaimID]struct{}
+
 	// filterData is the new generation transaction filter backported from
 	// github.com/decred/dcrd for the new backported `loadtxfilter` and
 	// `rescanblocks` methods.
@@ -2007,6 +2250,8 @@ func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
 		server:            server,
 		addrRequests:      make(map[string]struct{}),
 		spentRequests:     make(map[wire.OutPoint]struct{}),
+		claimNameRequests: make(map[string]struct{}),
+		claimIDRequests:   make(map[change.ClaimID]struct{}),
 		serviceRequestSem: makeSemaphore(cfg.RPCMaxConcurrentReqs),
 		ntfnChan:          make(chan []byte, 1), // nonblocking sync
 		sendChan:          make(chan wsResponse, websocketSendBufferSize),
@@ -2203,6 +2448,70 @@ func handleStopNotifySpent(wsc *wsClient, icmd interface{}) (interface{}, error)
 	return nil, nil
 }
 
+// parseClaimRequests normalizes the names and parses the claim IDs passed to
+// the notifyclaims and stopnotifyclaims commands.
+func parseClaimRequests(wsc *wsClient, names []string, claimIDs []string) ([]string, []change.ClaimID, error) {
+	height := wsc.server.cfg.Chain.BestSnapshot().Height + 1
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized,
+			string(normalization.NormalizeIfNecessary([]byte(name), height)))
+	}
+
+	ids := make([]change.ClaimID, 0, len(claimIDs))
+	for _, s := range claimIDs {
+		if len(s) != change.ClaimIDSize*2 {
+			return nil, nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Claim ID must be 40 hex characters: " + s,
+			}
+		}
+		id, err := change.NewIDFromString(s)
+		if err != nil {
+			return nil, nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Unable to parse the claim ID " + s + ": " + err.Error(),
+			}
+		}
+		ids = append(ids, id)
+	}
+	return normalized, ids, nil
+}
+
+// handleNotifyClaims implements the notifyclaims command extension for
+// websocket connections.
+func handleNotifyClaims(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.NotifyClaimsCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+
+	names, ids, err := parseClaimRequests(wsc, cmd.Names, cmd.ClaimIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	wsc.server.ntfnMgr.RegisterClaimRequests(wsc, names, ids)
+	return nil, nil
+}
+
+// handleStopNotifyClaims implements the stopnotifyclaims command extension
+// for websocket connections.
+func handleStopNotifyClaims(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.StopNotifyClaimsCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+
+	names, ids, err := parseClaimRequests(wsc, cmd.Names, cmd.ClaimIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	wsc.server.ntfnMgr.UnregisterClaimRequests(wsc, names, ids)
+	return nil, nil
+}
+
 // handleStopNotifyReceived implements the stopnotifyreceived command extension
 // for websocket connections.
 func handleStopNotifyReceived(wsc *wsClient, icmd interface{}) (interface{}, error) {
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"os"
 	"runtime"
 	"sort"
 	"strconv"
@@ -235,9 +236,10 @@ type server struct {
 	// if the associated index is not enabled.  These fields are set during
 	// initial creation of the server and never changed afterwards, so they
 	// do not need to be protected for concurrent access.
-	txIndex   *indexers.TxIndex
-	addrIndex *indexers.AddrIndex
-	cfIndex   *indexers.CfIndex
+	txIndex        *indexers.TxIndex
+	addrIndex      *indexers.AddrIndex
+	claimAddrIndex *indexers.ClaimAddrIndex
+	cfIndex        *indexers.CfIndex
 
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
@@ -791,7 +793,7 @@ func (sp *serverPeer) OnGetCFilters(_ *peer.Peer, msg *wire.MsgGetCFilters) {
 	// We'll also ensure that the remote party is requesting a set of
 	// filters that we actually currently maintain.
 	switch msg.FilterType {
-	case wire.GCSFilterRegular:
+	case wire.GCSFilterRegular, wire.GCSFilterClaim:
 		break
 
 	default:
@@ -847,7 +849,7 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 	// We'll also ensure that the remote party is requesting a set of
 	// headers for filters that we actually currently maintain.
 	switch msg.FilterType {
-	case wire.GCSFilterRegular:
+	case wire.GCSFilterRegular, wire.GCSFilterClaim:
 		break
 
 	default:
@@ -964,7 +966,7 @@ func (sp *serverPeer) OnGetCFCheckpt(_ *peer.Peer, msg *wire.MsgGetCFCheckpt) {
 	// We'll also ensure that the remote party is requesting a set of
 	// checkpoints for filters that we actually currently maintain.
 	switch msg.FilterType {
-	case wire.GCSFilterRegular:
+	case wire.GCSFilterRegular, wire.GCSFilterClaim:
 		break
 
 	default:
@@ -2707,7 +2709,8 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if cfg.AddrIndex {
 		indxLog.Info("Address index is enabled")
 		s.addrIndex = indexers.NewAddrIndex(db, chainParams)
-		indexes = append(indexes, s.addrIndex)
+		s.claimAddrIndex = indexers.NewClaimAddrIndex(db, chainParams)
+		indexes = append(indexes, s.addrIndex, s.claimAddrIndex)
 	}
 	if !cfg.NoCFilters {
 		indxLog.Info("Committed filter index is enabled")
@@ -2732,6 +2735,14 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	claimTrieCfg := claimtrieconfig.DefaultConfig
 	claimTrieCfg.DataDir = cfg.DataDir
 	claimTrieCfg.Interrupt = interrupt
+	claimTrieCfg.ClaimIDIndex = cfg.ClaimIDIndex
+	claimTrieCfg.TakeoverIndex = cfg.TakeoverIndex
+	claimTrieCfg.ChangeIndex = cfg.ClaimChangeIndex
+	claimTrieCfg.ChannelIndex = cfg.ChannelIndex
+	claimTrieCfg.RankingIndex = cfg.RankingIndex
+	claimTrieCfg.Stats = cfg.ClaimTrieStats
+	claimTrieCfg.PruneDepth = cfg.ClaimTriePruneDepth
+	claimTrieCfg.Memory = cfg.ClaimTrieMemory
 
 	var ct *claimtrie.ClaimTrie
 
@@ -2749,6 +2760,12 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		lbryLog.Errorf("ClaimTrie uses Unknown implementation")
 	}
 
+	if cfg.ClaimTrieImpl != "none" && cfg.ClaimTrieImport != "" {
+		if err = importClaimTrie(claimTrieCfg, cfg.ClaimTrieImport); err != nil {
+			return nil, err
+		}
+	}
+
 	if cfg.ClaimTrieImpl != "none" {
 		ct, err = claimtrie.New(claimTrieCfg)
 		if err != nil {
@@ -2780,6 +2797,12 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		return nil, err
 	}
 
+	if ct != nil && cfg.ClaimTrieExport != "" {
+		if err = exportClaimTrie(ct, cfg.ClaimTrieExport); err != nil {
+			return nil, err
+		}
+	}
+
 	// Search for a FeeEstimator state in the database. If none can be found
 	// or if it cannot be loaded, create a new one.
 	db.Update(func(tx database.Tx) error {
@@ -2830,6 +2853,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			return s.chain.CalcSequenceLock(tx, view, true)
 		},
 		IsDeploymentActive: s.chain.IsDeploymentActive,
+		ClaimExists:        s.chain.ClaimExists,
 		SigCache:           s.sigCache,
 		HashCache:          s.hashCache,
 		AddrIndex:          s.addrIndex,
@@ -2972,21 +2996,22 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		}
 
 		s.rpcServer, err = newRPCServer(&rpcserverConfig{
-			Listeners:    rpcListeners,
-			StartupTime:  startupTime.Unix(),
-			ConnMgr:      &rpcConnManager{&s},
-			SyncMgr:      &rpcSyncMgr{&s, s.syncManager},
-			TimeSource:   s.timeSource,
-			Chain:        s.chain,
-			ChainParams:  chainParams,
-			DB:           db,
-			TxMemPool:    s.txMemPool,
-			Generator:    blockTemplateGenerator,
-			CPUMiner:     s.cpuMiner,
-			TxIndex:      s.txIndex,
-			AddrIndex:    s.addrIndex,
-			CfIndex:      s.cfIndex,
-			FeeEstimator: s.feeEstimator,
+			Listeners:      rpcListeners,
+			StartupTime:    startupTime.Unix(),
+			ConnMgr:        &rpcConnManager{&s},
+			SyncMgr:        &rpcSyncMgr{&s, s.syncManager},
+			TimeSource:     s.timeSource,
+			Chain:          s.chain,
+			ChainParams:    chainParams,
+			DB:             db,
+			TxMemPool:      s.txMemPool,
+			Generator:      blockTemplateGenerator,
+			CPUMiner:       s.cpuMiner,
+			TxIndex:        s.txIndex,
+			AddrIndex:      s.addrIndex,
+			ClaimAddrIndex: s.claimAddrIndex,
+			CfIndex:        s.cfIndex,
+			FeeEstimator:   s.feeEstimator,
 		})
 		if err != nil {
 			return nil, err
@@ -3002,6 +3027,43 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	return &s, nil
 }
 
+// importClaimTrie loads the ClaimTrie written to file by exportClaimTrie into the empty repos of cfg.
+// The chain checks the imported ClaimTrie against the block header at its height when it loads.
+func importClaimTrie(cfg claimtrieconfig.Config, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lbryLog.Infof("Importing the ClaimTrie from %s", file)
+	height, hash, err := claimtrie.Import(cfg, f)
+	if errors.Is(err, claimtrie.ErrImportNotEmpty) {
+		lbryLog.Infof("The ClaimTrie already has blocks; not importing %s", file)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to import the ClaimTrie from %s: %v", file, err)
+	}
+	lbryLog.Infof("Imported the ClaimTrie at height %d with hash %s", height, hash)
+	return nil
+}
+
+// exportClaimTrie writes the ClaimTrie at its current height to file.
+func exportClaimTrie(ct *claimtrie.ClaimTrie, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lbryLog.Infof("Exporting the ClaimTrie at height %d to %s", ct.Height(), file)
+	if err = ct.Export(ct.Height(), f); err != nil {
+		return fmt.Errorf("unable to export the ClaimTrie to %s: %v", file, err)
+	}
+	return f.Close()
+}
+
 // initListeners initializes the configured net listeners and adds any bound
 // addresses to the address manager. Returns the listeners and a NAT interface,
 // which is non-nil if UPnP is in use.
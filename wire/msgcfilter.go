@@ -17,6 +17,10 @@ type FilterType uint8
 const (
 	// GCSFilterRegular is the regular filter type.
 	GCSFilterRegular FilterType = iota
+
+	// GCSFilterClaim is the claim filter type.  It matches the normalized
+	// names and the claim IDs touched by the claim scripts of a block.
+	GCSFilterClaim
 )
 
 const (